@@ -0,0 +1,163 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"gorm.io/gorm"
+)
+
+const (
+	AdminDefaultLimit = 100
+	AdminMaxLimit     = 1000
+)
+
+var ErrAdminTokenEmpty = errors.New("admin api token is empty")
+
+// AdminServer the operator http api of the large deposit approval queue,
+// it is served on its own address and every request requires the bearer token
+type AdminServer struct {
+	db    *gorm.DB
+	token string
+	log   log.Logger
+	mux   *http.ServeMux
+}
+
+// ReviewRequest the operator review of the large deposit
+type ReviewRequest struct {
+	DepositID int64  `json:"deposit_id"`
+	Operator  string `json:"operator"`
+	Remark    string `json:"remark"`
+}
+
+// ReviewResponse the deposit status after the review
+type ReviewResponse struct {
+	DepositID int64  `json:"deposit_id"`
+	Status    string `json:"status"`
+}
+
+// NewAdminServer new admin http api server, the token is required
+func NewAdminServer(db *gorm.DB, token string, logger log.Logger) (*AdminServer, error) {
+	if token == "" {
+		return nil, ErrAdminTokenEmpty
+	}
+	s := &AdminServer{
+		db:    db,
+		token: token,
+		log:   logger,
+		mux:   http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/admin/v1/approvals", s.handle(http.MethodGet, s.handleApprovals))
+	s.mux.HandleFunc("/admin/v1/approvals/approve", s.handle(http.MethodPost, s.review(bitcoin.ApproveDeposit,
+		model.DepositB2TxStatusPending)))
+	s.mux.HandleFunc("/admin/v1/approvals/reject", s.handle(http.MethodPost, s.review(bitcoin.RejectDeposit,
+		model.DepositB2TxStatusApprovalRejected)))
+	return s, nil
+}
+
+// Handler returns the http handler of the admin api
+func (s *AdminServer) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe serves the admin api on the address, blocks until the server stops
+func (s *AdminServer) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(lis)
+}
+
+// Serve serves the admin api on the bound listener, blocks until the server stops
+func (s *AdminServer) Serve(lis net.Listener) error {
+	server := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+	}
+	s.log.Infow("admin http api listening", "address", lis.Addr().String())
+	return server.Serve(lis)
+}
+
+// handle checks the bearer token and allows the method only
+func (s *AdminServer) handle(method string, handler func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+			return
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+			return
+		}
+		resp, err := handler(r)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *AdminServer) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidParam), errors.Is(err, bitcoin.ErrOperatorEmpty):
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "deposit not found"})
+	case errors.Is(err, bitcoin.ErrDepositNotPendingApproval):
+		writeJSON(w, http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		s.log.Errorw("admin http api err", "path", r.URL.Path, "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "internal error"})
+	}
+}
+
+// handleApprovals lists the large deposits wait operator approval in id order
+func (s *AdminServer) handleApprovals(r *http.Request) (interface{}, error) {
+	limit := AdminDefaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > AdminMaxLimit {
+			return nil, fmt.Errorf("%w: limit %s", ErrInvalidParam, v)
+		}
+	}
+	return bitcoin.PendingApprovalDeposits(s.db.WithContext(r.Context()), limit)
+}
+
+// review approves or rejects the large deposit, the operator is recorded with the review time
+func (s *AdminServer) review(
+	review func(db *gorm.DB, depositID int64, operator string, remark string) error,
+	status int,
+) func(r *http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var req ReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidParam, err.Error())
+		}
+		if req.DepositID <= 0 {
+			return nil, fmt.Errorf("%w: deposit_id %d", ErrInvalidParam, req.DepositID)
+		}
+		if req.Operator == "" {
+			return nil, bitcoin.ErrOperatorEmpty
+		}
+		if err := review(s.db.WithContext(r.Context()), req.DepositID, req.Operator, req.Remark); err != nil {
+			return nil, err
+		}
+		s.log.Infow("admin review large deposit", "depositID", req.DepositID, "operator", req.Operator,
+			"status", bitcoin.DepositStatusName(status))
+		return ReviewResponse{DepositID: req.DepositID, Status: bitcoin.DepositStatusName(status)}, nil
+	}
+}
@@ -0,0 +1,51 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/api"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdminServer(t *testing.T) {
+	_, err := api.NewAdminServer(nil, "", log.NewNopLogger())
+	require.ErrorIs(t, err, api.ErrAdminTokenEmpty)
+}
+
+func TestAdminServerInvalidRequest(t *testing.T) {
+	server, err := api.NewAdminServer(nil, "secret", log.NewNopLogger())
+	require.NoError(t, err)
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		body   string
+		status int
+	}{
+		{"no token", http.MethodGet, "/admin/v1/approvals", "", "", http.StatusUnauthorized},
+		{"wrong token", http.MethodPost, "/admin/v1/approvals/approve", "Bearer wrong", `{"deposit_id":1,"operator":"alice"}`, http.StatusUnauthorized},
+		{"token without bearer", http.MethodGet, "/admin/v1/approvals", "secre", "", http.StatusUnauthorized},
+		{"method not allowed", http.MethodGet, "/admin/v1/approvals/approve", "Bearer secret", "", http.StatusMethodNotAllowed},
+		{"invalid limit", http.MethodGet, "/admin/v1/approvals?limit=0", "Bearer secret", "", http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/admin/v1/approvals/approve", "Bearer secret", `{`, http.StatusBadRequest},
+		{"without deposit id", http.MethodPost, "/admin/v1/approvals/approve", "Bearer secret", `{"operator":"alice"}`, http.StatusBadRequest},
+		{"without operator", http.MethodPost, "/admin/v1/approvals/reject", "Bearer secret", `{"deposit_id":1}`, http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.token != "" {
+				req.Header.Set("Authorization", tc.token)
+			}
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
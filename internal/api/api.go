@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/metrics"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"gorm.io/gorm"
+)
+
+const ReadHeaderTimeout = 10 * time.Second
+
+// ChainTip returns the bitcoin chain tip, the indexer bitcoin client
+type ChainTip interface {
+	LatestBlock() (int64, error)
+}
+
+// Server the read-only http query api of deposits and indexer state
+type Server struct {
+	db       *gorm.DB
+	chain    ChainTip
+	listener *bitcoin.DepositListener
+	log      log.Logger
+	mux      *http.ServeMux
+}
+
+// IndexerState the indexed checkpoint and the bitcoin chain tip
+type IndexerState struct {
+	BtcIndex      model.BtcIndex `json:"btc_index"`
+	ChainTip      int64          `json:"chain_tip"`
+	Lag           int64          `json:"lag"`
+	ChainTipError string         `json:"chain_tip_error,omitempty"`
+}
+
+// ErrorResponse the error response body
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer new http api server, chain tip is not shown if chain is nil,
+// the websocket subscriptions only poll if listener is nil
+func NewServer(db *gorm.DB, chain ChainTip, listener *bitcoin.DepositListener, logger log.Logger) *Server {
+	s := &Server{
+		db:       db,
+		chain:    chain,
+		listener: listener,
+		log:      logger,
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/v1/deposit", s.get(s.handleDeposit))
+	s.mux.HandleFunc("/v1/deposits", s.get(s.handleDeposits))
+	s.mux.HandleFunc("/v1/indexer", s.get(s.handleIndexer))
+	s.mux.HandleFunc("/v1/ws", s.handleWS)
+	s.mux.HandleFunc("/openapi.json", s.get(s.handleOpenAPI))
+	s.mux.Handle("/metrics", metrics.Handler(NewDBCollector(db, logger)))
+	return s
+}
+
+// Handle registers the handler to the api server, eg. health checks
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the http handler of the api
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe serves the api on the address, blocks until the server stops
+func (s *Server) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(lis)
+}
+
+// Serve serves the api on the bound listener, blocks until the server stops
+func (s *Server) Serve(lis net.Listener) error {
+	server := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+	}
+	s.log.Infow("http api listening", "address", lis.Addr().String())
+	return server.Serve(lis)
+}
+
+// get allows the GET method only, the api is read-only
+func (s *Server) get(handler func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+			return
+		}
+		resp, err := handler(r)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidParam), errors.Is(err, bitcoin.ErrDepositUnknownStatus):
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrNotFound):
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	default:
+		s.log.Errorw("http api err", "path", r.URL.Path, "error", err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "internal error"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// handleDeposit returns the deposit by the bitcoin tx hash, or the b2 tx hash of the contract deposit or eoa transfer
+func (s *Server) handleDeposit(r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	deposit, err := GetDeposit(r.Context(), s.db, query.Get("btc_tx_hash"), query.Get("b2_tx_hash"))
+	if err != nil {
+		return nil, err
+	}
+	return NewDepositResponse(deposit), nil
+}
+
+// handleDeposits lists the deposits of the sender or the aa address in id descending order
+func (s *Server) handleDeposits(r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	q := DepositQuery{
+		Sender:    query.Get("sender"),
+		AAAddress: query.Get("aa_address"),
+		Status:    query.Get("status"),
+		EoaStatus: query.Get("eoa_status"),
+		Cursor:    query.Get("cursor"),
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: limit %s", ErrInvalidParam, v)
+		}
+		q.Limit = limit
+	}
+	deposits, nextCursor, err := ListDeposits(r.Context(), s.db, q)
+	if err != nil {
+		return nil, err
+	}
+	list := DepositList{Deposits: make([]DepositResponse, 0, len(deposits)), NextCursor: nextCursor}
+	for _, deposit := range deposits {
+		list.Deposits = append(list.Deposits, NewDepositResponse(deposit))
+	}
+	return list, nil
+}
+
+// handleIndexer returns the indexed checkpoint and the bitcoin chain tip
+func (s *Server) handleIndexer(r *http.Request) (interface{}, error) {
+	var state IndexerState
+	var indexes []model.BtcIndex
+	if err := s.db.WithContext(r.Context()).Order("id").Limit(1).Find(&indexes).Error; err != nil {
+		return nil, err
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("btc index %w", ErrNotFound)
+	}
+	state.BtcIndex = indexes[0]
+	if s.chain == nil {
+		state.ChainTipError = "bitcoin indexer disabled"
+		return state, nil
+	}
+	tip, err := s.chain.LatestBlock()
+	if err != nil {
+		state.ChainTipError = err.Error()
+		return state, nil
+	}
+	state.ChainTip = tip
+	state.Lag = tip - state.BtcIndex.BtcIndexBlock
+	return state, nil
+}
+
+func (s *Server) handleOpenAPI(_ *http.Request) (interface{}, error) {
+	return OpenAPISpec(), nil
+}
@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/api"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpecGenerated(t *testing.T) {
+	spec, err := json.MarshalIndent(api.OpenAPISpec(), "", "  ")
+	require.NoError(t, err)
+	doc, err := os.ReadFile("../../docs/openapi.json")
+	require.NoError(t, err)
+	require.JSONEq(t, string(spec), string(doc), "docs/openapi.json is outdated, run make openapi")
+}
+
+func TestOpenAPIDepositSchema(t *testing.T) {
+	schemas := api.OpenAPISpec()["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	properties := schemas["Deposit"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, name := range []string{"id", "created_at", "btc_tx_hash", "btc_value", "b2_tx_hash", "b2_tx_status", "btc_from_aa_address"} {
+		require.Contains(t, properties, name)
+	}
+	require.Equal(t, "integer", properties["btc_value"].(map[string]interface{})["type"])
+	require.Equal(t, "date-time", properties["created_at"].(map[string]interface{})["format"])
+	for _, name := range []string{"lease_owner", "b2_raw_tx", "compliance_reason", "last_error", "trace_parent", "deleted_at"} {
+		require.NotContains(t, properties, name)
+	}
+}
+
+func TestNewDepositResponse(t *testing.T) {
+	deposit := model.Deposit{
+		ID:               7,
+		BtcTxHash:        "btc-hash",
+		BtcValue:         model.NewBigIntFromInt64(100000),
+		B2TxStatus:       model.DepositB2TxStatusPending,
+		B2RawTx:          "0x02f8",
+		ComplianceReason: "sanctioned address",
+		LastError:        "nonce too low",
+		LeaseOwner:       "bridge-1",
+		TraceParent:      "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	body, err := json.Marshal(api.NewDepositResponse(deposit))
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &fields))
+	require.Equal(t, "btc-hash", fields["btc_tx_hash"])
+	require.EqualValues(t, 100000, fields["btc_value"])
+	for _, name := range []string{"lease_owner", "b2_raw_tx", "compliance_reason", "last_error", "trace_parent"} {
+		require.NotContains(t, fields, name)
+	}
+	for _, value := range []string{deposit.B2RawTx, deposit.ComplianceReason, deposit.LastError, deposit.LeaseOwner} {
+		require.NotContains(t, string(body), value)
+	}
+}
+
+func TestServerInvalidRequest(t *testing.T) {
+	server := api.NewServer(nil, nil, nil, log.NewNopLogger())
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"method not allowed", http.MethodPost, "/v1/deposit?btc_tx_hash=abc", http.StatusMethodNotAllowed},
+		{"deposit without hash", http.MethodGet, "/v1/deposit", http.StatusBadRequest},
+		{"deposits without sender", http.MethodGet, "/v1/deposits", http.StatusBadRequest},
+		{"invalid limit", http.MethodGet, "/v1/deposits?sender=tb1q&limit=1000", http.StatusBadRequest},
+		{"unknown status", http.MethodGet, "/v1/deposits?sender=tb1q&status=unknown", http.StatusBadRequest},
+		{"ws without filter", http.MethodGet, "/v1/ws", http.StatusBadRequest},
+		{"ws with both filters", http.MethodGet, "/v1/ws?btc_tx_hash=abc&address=tb1q", http.StatusBadRequest},
+		{"ws invalid after event id", http.MethodGet, "/v1/ws?btc_tx_hash=abc&after_event_id=x", http.StatusBadRequest},
+		{"openapi", http.MethodGet, "/openapi.json", http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
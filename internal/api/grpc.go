@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/api/indexerpb"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// WithdrawSource returns the withdraw of the b2 tx, the bridge contract WithdrawEvent in the receipt
+type WithdrawSource interface {
+	GetWithdraw(ctx context.Context, txHash string) (*bitcoin.WithdrawEvent, error)
+}
+
+// GRPCServer the gRPC IndexerQuery service, reads the same models as the http api
+type GRPCServer struct {
+	indexerpb.UnimplementedIndexerQueryServer
+
+	db        *gorm.DB
+	listener  *bitcoin.DepositListener
+	withdraws WithdrawSource
+	log       log.Logger
+}
+
+// NewGRPCServer new gRPC query server, the watch stream only polls if listener is nil
+func NewGRPCServer(db *gorm.DB, listener *bitcoin.DepositListener, withdraws WithdrawSource, logger log.Logger) *GRPCServer {
+	return &GRPCServer{
+		db:        db,
+		listener:  listener,
+		withdraws: withdraws,
+		log:       logger,
+	}
+}
+
+// ListenAndServe serves the gRPC service on the address, blocks until the server stops
+func (g *GRPCServer) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return g.Serve(lis)
+}
+
+// Serve serves the gRPC service on the bound listener, blocks until the server stops
+func (g *GRPCServer) Serve(lis net.Listener) error {
+	server := grpc.NewServer()
+	indexerpb.RegisterIndexerQueryServer(server, g)
+	g.log.Infow("grpc api listening", "address", lis.Addr().String())
+	return server.Serve(lis)
+}
+
+func (g *GRPCServer) GetDeposit(ctx context.Context, req *indexerpb.GetDepositRequest) (*indexerpb.Deposit, error) {
+	deposit, err := GetDeposit(ctx, g.db, req.GetBtcTxHash(), req.GetB2TxHash())
+	if err != nil {
+		return nil, g.status(err)
+	}
+	return DepositToProto(deposit), nil
+}
+
+func (g *GRPCServer) ListDeposits(ctx context.Context, req *indexerpb.ListDepositsRequest) (*indexerpb.ListDepositsResponse, error) {
+	deposits, nextCursor, err := ListDeposits(ctx, g.db, DepositQuery{
+		Sender:    req.GetSender(),
+		AAAddress: req.GetAaAddress(),
+		Status:    req.GetStatus(),
+		EoaStatus: req.GetEoaStatus(),
+		Cursor:    req.GetCursor(),
+		Limit:     int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, g.status(err)
+	}
+	resp := &indexerpb.ListDepositsResponse{
+		Deposits:   make([]*indexerpb.Deposit, 0, len(deposits)),
+		NextCursor: nextCursor,
+	}
+	for _, deposit := range deposits {
+		resp.Deposits = append(resp.Deposits, DepositToProto(deposit))
+	}
+	return resp, nil
+}
+
+// GetWithdraw returns the withdraw by the b2 tx hash, decoded from the WithdrawEvent log of the tx receipt
+func (g *GRPCServer) GetWithdraw(ctx context.Context, req *indexerpb.GetWithdrawRequest) (*indexerpb.Withdraw, error) {
+	if !isTxHash(req.GetB2TxHash()) {
+		return nil, g.status(fmt.Errorf("%w: b2_tx_hash %s", ErrInvalidParam, req.GetB2TxHash()))
+	}
+	withdraw, err := g.withdraws.GetWithdraw(ctx, req.GetB2TxHash())
+	if err != nil {
+		return nil, g.status(err)
+	}
+	return &indexerpb.Withdraw{
+		B2TxHash:    req.GetB2TxHash(),
+		FromAddress: withdraw.FromAddress.Hex(),
+		BtcAddress:  withdraw.ToAddress,
+		Amount:      withdraw.Amount.String(),
+	}, nil
+}
+
+// isTxHash returns whether the hash is a 0x prefixed 32 bytes hex hash
+func isTxHash(hash string) bool {
+	if len(hash) != 2+2*common.HashLength || !strings.HasPrefix(hash, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(hash[2:])
+	return err == nil
+}
+
+// WatchDeposits streams the deposit events of the filter until the client cancels
+func (g *GRPCServer) WatchDeposits(req *indexerpb.WatchDepositsRequest, stream indexerpb.IndexerQuery_WatchDepositsServer) error {
+	ctx := stream.Context()
+	cursor, err := watchCursor(ctx, g.db, req.GetAfterEventId())
+	if err != nil {
+		return g.status(err)
+	}
+	// the filter is on the deposits of the events
+	var (
+		conditions []string
+		args       []interface{}
+		scope      func(*gorm.DB) *gorm.DB
+	)
+	if req.GetSender() != "" {
+		conditions = append(conditions, fmt.Sprintf("%s.%s = ?", model.Deposit{}.TableName(), model.Deposit{}.Column().BtcFrom))
+		args = append(args, req.GetSender())
+	}
+	if req.GetAaAddress() != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(%s.%s) = LOWER(?)", model.Deposit{}.TableName(), model.Deposit{}.Column().BtcFromAAAddress))
+		args = append(args, req.GetAaAddress())
+	}
+	if len(conditions) > 0 {
+		scope = depositEventsOfDeposits(strings.Join(conditions, " AND "), args...)
+	}
+	err = watchDepositEvents(ctx, g.db, g.listener, cursor, scope, func(event model.DepositEvent, deposit model.Deposit) error {
+		return stream.Send(DepositEventToProto(event, deposit))
+	})
+	if err != nil {
+		return g.status(err)
+	}
+	return nil
+}
+
+// status converts the query error to the gRPC status
+func (g *GRPCServer) status(err error) error {
+	if _, ok := status.FromError(err); ok {
+		// the stream send error
+		return err
+	}
+	switch {
+	case errors.Is(err, ErrInvalidParam), errors.Is(err, bitcoin.ErrDepositUnknownStatus):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, ethereum.NotFound), errors.Is(err, bitcoin.ErrWithdrawEventNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		g.log.Errorw("grpc api err", "error", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// DepositEventToProto converts the deposit event and its deposit to the protobuf message
+func DepositEventToProto(event model.DepositEvent, deposit model.Deposit) *indexerpb.DepositEvent {
+	return &indexerpb.DepositEvent{
+		Id:         event.ID,
+		DepositId:  event.DepositID,
+		BtcTxHash:  event.BtcTxHash,
+		StatusType: event.StatusType,
+		FromStatus: int32(event.FromStatus),
+		ToStatus:   int32(event.ToStatus),
+		FromState:  event.FromState,
+		ToState:    event.ToState,
+		Error:      event.Error,
+		TxHash:     event.TxHash,
+		Actor:      event.Actor,
+		CreatedAt:  timestamp(event.CreatedAt),
+		Deposit:    DepositToProto(deposit),
+	}
+}
+
+// DepositToProto converts the public fields of the deposit to the protobuf message
+func DepositToProto(deposit model.Deposit) *indexerpb.Deposit {
+	return &indexerpb.Deposit{
+		Id:                deposit.ID,
+		CreatedAt:         timestamp(deposit.CreatedAt),
+		UpdatedAt:         timestamp(deposit.UpdatedAt),
+		BtcBlockNumber:    deposit.BtcBlockNumber,
+		BtcTxIndex:        deposit.BtcTxIndex,
+		BtcTxHash:         deposit.BtcTxHash,
+		BtcTxType:         int32(deposit.BtcTxType),
+		BtcFroms:          deposit.BtcFroms,
+		BtcFrom:           deposit.BtcFrom,
+		BtcTo:             deposit.BtcTo,
+		BtcFromAaAddress:  deposit.BtcFromAAAddress,
+		BtcValue:          deposit.BtcValue.String(),
+		B2TxHash:          deposit.B2TxHash,
+		B2TxStatus:        int32(deposit.B2TxStatus),
+		B2TxRetry:         int32(deposit.B2TxRetry),
+		B2EoaTxHash:       deposit.B2EoaTxHash,
+		B2EoaTxStatus:     int32(deposit.B2EoaTxStatus),
+		BtcBlockTime:      timestamp(deposit.BtcBlockTime),
+		BtcMemo:           deposit.BtcMemo,
+		RecipientStrategy: deposit.RecipientStrategy,
+		L2Value:           deposit.L2Value.String(),
+		BridgeFee:         deposit.BridgeFee.String(),
+		NetValue:          deposit.NetValue.String(),
+		RejectReason:      deposit.RejectReason,
+		ThrottleReason:    deposit.ThrottleReason,
+		RetryAttempt:      int32(deposit.RetryAttempt),
+		NextRetryAt:       timestamp(deposit.NextRetryAt),
+	}
+}
+
+// timestamp returns nil for the zero time
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/api"
+	"github.com/b2network/b2-indexer/internal/api/indexerpb"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCServerInvalidRequest(t *testing.T) {
+	server := api.NewGRPCServer(nil, nil, nil, log.NewNopLogger())
+	ctx := context.Background()
+
+	_, err := server.GetDeposit(ctx, &indexerpb.GetDepositRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListDeposits(ctx, &indexerpb.ListDepositsRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListDeposits(ctx, &indexerpb.ListDepositsRequest{Sender: "tb1q", Limit: 1000})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListDeposits(ctx, &indexerpb.ListDepositsRequest{Sender: "tb1q", Status: "unknown"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.GetWithdraw(ctx, &indexerpb.GetWithdrawRequest{B2TxHash: "0x01"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type mockWithdraws map[string]*bitcoin.WithdrawEvent
+
+func (m mockWithdraws) GetWithdraw(_ context.Context, txHash string) (*bitcoin.WithdrawEvent, error) {
+	if txHash == withdrawTxHash(2) {
+		return nil, bitcoin.ErrWithdrawEventNotFound
+	}
+	if withdraw, ok := m[txHash]; ok {
+		return withdraw, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func withdrawTxHash(i int) string {
+	return common.BigToHash(big.NewInt(int64(i))).Hex()
+}
+
+func TestGRPCServerGetWithdraw(t *testing.T) {
+	from := common.HexToAddress("0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	server := api.NewGRPCServer(nil, nil, mockWithdraws{
+		withdrawTxHash(1): {FromAddress: from, ToAddress: "tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv", Amount: big.NewInt(100000)},
+	}, log.NewNopLogger())
+	ctx := context.Background()
+
+	withdraw, err := server.GetWithdraw(ctx, &indexerpb.GetWithdrawRequest{B2TxHash: withdrawTxHash(1)})
+	require.NoError(t, err)
+	require.Equal(t, withdrawTxHash(1), withdraw.B2TxHash)
+	require.Equal(t, from.Hex(), withdraw.FromAddress)
+	require.Equal(t, "tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv", withdraw.BtcAddress)
+	require.Equal(t, "100000", withdraw.Amount)
+
+	// not a withdraw tx
+	_, err = server.GetWithdraw(ctx, &indexerpb.GetWithdrawRequest{B2TxHash: withdrawTxHash(2)})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	// not mined
+	_, err = server.GetWithdraw(ctx, &indexerpb.GetWithdrawRequest{B2TxHash: withdrawTxHash(3)})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.GetWithdraw(ctx, &indexerpb.GetWithdrawRequest{B2TxHash: "0x" + strings.Repeat("z", 64)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDepositToProto(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deposit := model.Deposit{
+		ID:               7,
+		CreatedAt:        createdAt,
+		BtcTxHash:        "btc-hash",
+		BtcFrom:          "tb1qfrom",
+		BtcFromAAAddress: "0xaa",
+		BtcValue:         model.NewBigInt(big.NewInt(100000)),
+		B2TxStatus:       model.DepositB2TxStatusSuccess,
+	}
+	pb := api.DepositToProto(deposit)
+	require.Equal(t, int64(7), pb.Id)
+	require.Equal(t, createdAt, pb.CreatedAt.AsTime())
+	require.Nil(t, pb.BtcBlockTime)
+	require.Nil(t, pb.NextRetryAt)
+	require.Equal(t, "btc-hash", pb.BtcTxHash)
+	require.Equal(t, "0xaa", pb.BtcFromAaAddress)
+	require.Equal(t, "100000", pb.BtcValue)
+	require.Equal(t, int32(model.DepositB2TxStatusSuccess), pb.B2TxStatus)
+}
@@ -0,0 +1,1154 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        (unknown)
+// source: b2indexer/v1/query.proto
+
+package indexerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetDepositRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// bitcoin tx hash
+	BtcTxHash string `protobuf:"bytes,1,opt,name=btc_tx_hash,json=btcTxHash,proto3" json:"btc_tx_hash,omitempty"`
+	// b2 network tx hash, used if btc_tx_hash is empty
+	B2TxHash string `protobuf:"bytes,2,opt,name=b2_tx_hash,json=b2TxHash,proto3" json:"b2_tx_hash,omitempty"`
+}
+
+func (x *GetDepositRequest) Reset() {
+	*x = GetDepositRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDepositRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDepositRequest) ProtoMessage() {}
+
+func (x *GetDepositRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDepositRequest.ProtoReflect.Descriptor instead.
+func (*GetDepositRequest) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetDepositRequest) GetBtcTxHash() string {
+	if x != nil {
+		return x.BtcTxHash
+	}
+	return ""
+}
+
+func (x *GetDepositRequest) GetB2TxHash() string {
+	if x != nil {
+		return x.B2TxHash
+	}
+	return ""
+}
+
+type ListDepositsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// bitcoin from address
+	Sender string `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	// b2 network aa address of the sender
+	AaAddress string `protobuf:"bytes,2,opt,name=aa_address,json=aaAddress,proto3" json:"aa_address,omitempty"`
+	// b2 tx status name or number, eg. success, pending, in_flight
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// b2 eoa tx status name or number
+	EoaStatus string `protobuf:"bytes,4,opt,name=eoa_status,json=eoaStatus,proto3" json:"eoa_status,omitempty"`
+	// next_cursor of the previous page
+	Cursor string `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// page size, default 50, max 500
+	Limit int32 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *ListDepositsRequest) Reset() {
+	*x = ListDepositsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDepositsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDepositsRequest) ProtoMessage() {}
+
+func (x *ListDepositsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDepositsRequest.ProtoReflect.Descriptor instead.
+func (*ListDepositsRequest) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListDepositsRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *ListDepositsRequest) GetAaAddress() string {
+	if x != nil {
+		return x.AaAddress
+	}
+	return ""
+}
+
+func (x *ListDepositsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListDepositsRequest) GetEoaStatus() string {
+	if x != nil {
+		return x.EoaStatus
+	}
+	return ""
+}
+
+func (x *ListDepositsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListDepositsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListDepositsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Deposits []*Deposit `protobuf:"bytes,1,rep,name=deposits,proto3" json:"deposits,omitempty"`
+	// empty on the last page
+	NextCursor string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+}
+
+func (x *ListDepositsResponse) Reset() {
+	*x = ListDepositsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDepositsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDepositsResponse) ProtoMessage() {}
+
+func (x *ListDepositsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDepositsResponse.ProtoReflect.Descriptor instead.
+func (*ListDepositsResponse) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListDepositsResponse) GetDeposits() []*Deposit {
+	if x != nil {
+		return x.Deposits
+	}
+	return nil
+}
+
+func (x *ListDepositsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetWithdrawRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	B2TxHash string `protobuf:"bytes,1,opt,name=b2_tx_hash,json=b2TxHash,proto3" json:"b2_tx_hash,omitempty"`
+}
+
+func (x *GetWithdrawRequest) Reset() {
+	*x = GetWithdrawRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetWithdrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWithdrawRequest) ProtoMessage() {}
+
+func (x *GetWithdrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWithdrawRequest.ProtoReflect.Descriptor instead.
+func (*GetWithdrawRequest) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetWithdrawRequest) GetB2TxHash() string {
+	if x != nil {
+		return x.B2TxHash
+	}
+	return ""
+}
+
+// Withdraw mirrors the bridge contract WithdrawEvent
+type Withdraw struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	B2TxHash    string `protobuf:"bytes,1,opt,name=b2_tx_hash,json=b2TxHash,proto3" json:"b2_tx_hash,omitempty"`
+	FromAddress string `protobuf:"bytes,2,opt,name=from_address,json=fromAddress,proto3" json:"from_address,omitempty"`
+	BtcAddress  string `protobuf:"bytes,3,opt,name=btc_address,json=btcAddress,proto3" json:"btc_address,omitempty"`
+	Amount      string `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (x *Withdraw) Reset() {
+	*x = Withdraw{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Withdraw) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Withdraw) ProtoMessage() {}
+
+func (x *Withdraw) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Withdraw.ProtoReflect.Descriptor instead.
+func (*Withdraw) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Withdraw) GetB2TxHash() string {
+	if x != nil {
+		return x.B2TxHash
+	}
+	return ""
+}
+
+func (x *Withdraw) GetFromAddress() string {
+	if x != nil {
+		return x.FromAddress
+	}
+	return ""
+}
+
+func (x *Withdraw) GetBtcAddress() string {
+	if x != nil {
+		return x.BtcAddress
+	}
+	return ""
+}
+
+func (x *Withdraw) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type WatchDepositsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// bitcoin from address
+	Sender string `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	// b2 network aa address of the sender
+	AaAddress string `protobuf:"bytes,2,opt,name=aa_address,json=aaAddress,proto3" json:"aa_address,omitempty"`
+	// resume after the deposit event id, starts from the latest event if 0
+	AfterEventId int64 `protobuf:"varint,3,opt,name=after_event_id,json=afterEventId,proto3" json:"after_event_id,omitempty"`
+}
+
+func (x *WatchDepositsRequest) Reset() {
+	*x = WatchDepositsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchDepositsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDepositsRequest) ProtoMessage() {}
+
+func (x *WatchDepositsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDepositsRequest.ProtoReflect.Descriptor instead.
+func (*WatchDepositsRequest) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchDepositsRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *WatchDepositsRequest) GetAaAddress() string {
+	if x != nil {
+		return x.AaAddress
+	}
+	return ""
+}
+
+func (x *WatchDepositsRequest) GetAfterEventId() int64 {
+	if x != nil {
+		return x.AfterEventId
+	}
+	return 0
+}
+
+// Deposit mirrors the public json fields of the deposit, amounts are decimal strings
+type Deposit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	BtcBlockNumber    int64                  `protobuf:"varint,4,opt,name=btc_block_number,json=btcBlockNumber,proto3" json:"btc_block_number,omitempty"`
+	BtcTxIndex        int64                  `protobuf:"varint,5,opt,name=btc_tx_index,json=btcTxIndex,proto3" json:"btc_tx_index,omitempty"`
+	BtcTxHash         string                 `protobuf:"bytes,6,opt,name=btc_tx_hash,json=btcTxHash,proto3" json:"btc_tx_hash,omitempty"`
+	BtcTxType         int32                  `protobuf:"varint,7,opt,name=btc_tx_type,json=btcTxType,proto3" json:"btc_tx_type,omitempty"`
+	BtcFroms          string                 `protobuf:"bytes,8,opt,name=btc_froms,json=btcFroms,proto3" json:"btc_froms,omitempty"`
+	BtcFrom           string                 `protobuf:"bytes,9,opt,name=btc_from,json=btcFrom,proto3" json:"btc_from,omitempty"`
+	BtcTo             string                 `protobuf:"bytes,10,opt,name=btc_to,json=btcTo,proto3" json:"btc_to,omitempty"`
+	BtcFromAaAddress  string                 `protobuf:"bytes,11,opt,name=btc_from_aa_address,json=btcFromAaAddress,proto3" json:"btc_from_aa_address,omitempty"`
+	BtcValue          string                 `protobuf:"bytes,12,opt,name=btc_value,json=btcValue,proto3" json:"btc_value,omitempty"`
+	B2TxHash          string                 `protobuf:"bytes,13,opt,name=b2_tx_hash,json=b2TxHash,proto3" json:"b2_tx_hash,omitempty"`
+	B2TxStatus        int32                  `protobuf:"varint,14,opt,name=b2_tx_status,json=b2TxStatus,proto3" json:"b2_tx_status,omitempty"`
+	B2TxRetry         int32                  `protobuf:"varint,15,opt,name=b2_tx_retry,json=b2TxRetry,proto3" json:"b2_tx_retry,omitempty"`
+	B2EoaTxHash       string                 `protobuf:"bytes,16,opt,name=b2_eoa_tx_hash,json=b2EoaTxHash,proto3" json:"b2_eoa_tx_hash,omitempty"`
+	B2EoaTxStatus     int32                  `protobuf:"varint,17,opt,name=b2_eoa_tx_status,json=b2EoaTxStatus,proto3" json:"b2_eoa_tx_status,omitempty"`
+	BtcBlockTime      *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=btc_block_time,json=btcBlockTime,proto3" json:"btc_block_time,omitempty"`
+	BtcMemo           string                 `protobuf:"bytes,19,opt,name=btc_memo,json=btcMemo,proto3" json:"btc_memo,omitempty"`
+	RecipientStrategy string                 `protobuf:"bytes,20,opt,name=recipient_strategy,json=recipientStrategy,proto3" json:"recipient_strategy,omitempty"`
+	L2Value           string                 `protobuf:"bytes,21,opt,name=l2_value,json=l2Value,proto3" json:"l2_value,omitempty"`
+	BridgeFee         string                 `protobuf:"bytes,22,opt,name=bridge_fee,json=bridgeFee,proto3" json:"bridge_fee,omitempty"`
+	NetValue          string                 `protobuf:"bytes,23,opt,name=net_value,json=netValue,proto3" json:"net_value,omitempty"`
+	RejectReason      string                 `protobuf:"bytes,24,opt,name=reject_reason,json=rejectReason,proto3" json:"reject_reason,omitempty"`
+	ThrottleReason    string                 `protobuf:"bytes,26,opt,name=throttle_reason,json=throttleReason,proto3" json:"throttle_reason,omitempty"`
+	RetryAttempt      int32                  `protobuf:"varint,27,opt,name=retry_attempt,json=retryAttempt,proto3" json:"retry_attempt,omitempty"`
+	NextRetryAt       *timestamppb.Timestamp `protobuf:"bytes,28,opt,name=next_retry_at,json=nextRetryAt,proto3" json:"next_retry_at,omitempty"`
+}
+
+func (x *Deposit) Reset() {
+	*x = Deposit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Deposit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deposit) ProtoMessage() {}
+
+func (x *Deposit) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deposit.ProtoReflect.Descriptor instead.
+func (*Deposit) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Deposit) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Deposit) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Deposit) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Deposit) GetBtcBlockNumber() int64 {
+	if x != nil {
+		return x.BtcBlockNumber
+	}
+	return 0
+}
+
+func (x *Deposit) GetBtcTxIndex() int64 {
+	if x != nil {
+		return x.BtcTxIndex
+	}
+	return 0
+}
+
+func (x *Deposit) GetBtcTxHash() string {
+	if x != nil {
+		return x.BtcTxHash
+	}
+	return ""
+}
+
+func (x *Deposit) GetBtcTxType() int32 {
+	if x != nil {
+		return x.BtcTxType
+	}
+	return 0
+}
+
+func (x *Deposit) GetBtcFroms() string {
+	if x != nil {
+		return x.BtcFroms
+	}
+	return ""
+}
+
+func (x *Deposit) GetBtcFrom() string {
+	if x != nil {
+		return x.BtcFrom
+	}
+	return ""
+}
+
+func (x *Deposit) GetBtcTo() string {
+	if x != nil {
+		return x.BtcTo
+	}
+	return ""
+}
+
+func (x *Deposit) GetBtcFromAaAddress() string {
+	if x != nil {
+		return x.BtcFromAaAddress
+	}
+	return ""
+}
+
+func (x *Deposit) GetBtcValue() string {
+	if x != nil {
+		return x.BtcValue
+	}
+	return ""
+}
+
+func (x *Deposit) GetB2TxHash() string {
+	if x != nil {
+		return x.B2TxHash
+	}
+	return ""
+}
+
+func (x *Deposit) GetB2TxStatus() int32 {
+	if x != nil {
+		return x.B2TxStatus
+	}
+	return 0
+}
+
+func (x *Deposit) GetB2TxRetry() int32 {
+	if x != nil {
+		return x.B2TxRetry
+	}
+	return 0
+}
+
+func (x *Deposit) GetB2EoaTxHash() string {
+	if x != nil {
+		return x.B2EoaTxHash
+	}
+	return ""
+}
+
+func (x *Deposit) GetB2EoaTxStatus() int32 {
+	if x != nil {
+		return x.B2EoaTxStatus
+	}
+	return 0
+}
+
+func (x *Deposit) GetBtcBlockTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BtcBlockTime
+	}
+	return nil
+}
+
+func (x *Deposit) GetBtcMemo() string {
+	if x != nil {
+		return x.BtcMemo
+	}
+	return ""
+}
+
+func (x *Deposit) GetRecipientStrategy() string {
+	if x != nil {
+		return x.RecipientStrategy
+	}
+	return ""
+}
+
+func (x *Deposit) GetL2Value() string {
+	if x != nil {
+		return x.L2Value
+	}
+	return ""
+}
+
+func (x *Deposit) GetBridgeFee() string {
+	if x != nil {
+		return x.BridgeFee
+	}
+	return ""
+}
+
+func (x *Deposit) GetNetValue() string {
+	if x != nil {
+		return x.NetValue
+	}
+	return ""
+}
+
+func (x *Deposit) GetRejectReason() string {
+	if x != nil {
+		return x.RejectReason
+	}
+	return ""
+}
+
+func (x *Deposit) GetThrottleReason() string {
+	if x != nil {
+		return x.ThrottleReason
+	}
+	return ""
+}
+
+func (x *Deposit) GetRetryAttempt() int32 {
+	if x != nil {
+		return x.RetryAttempt
+	}
+	return 0
+}
+
+func (x *Deposit) GetNextRetryAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRetryAt
+	}
+	return nil
+}
+
+// DepositEvent the deposit status transition and the deposit after it
+type DepositEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	DepositId int64  `protobuf:"varint,2,opt,name=deposit_id,json=depositId,proto3" json:"deposit_id,omitempty"`
+	BtcTxHash string `protobuf:"bytes,3,opt,name=btc_tx_hash,json=btcTxHash,proto3" json:"btc_tx_hash,omitempty"`
+	// b2_tx, b2_eoa_tx or eps status
+	StatusType string                 `protobuf:"bytes,4,opt,name=status_type,json=statusType,proto3" json:"status_type,omitempty"`
+	FromStatus int32                  `protobuf:"varint,5,opt,name=from_status,json=fromStatus,proto3" json:"from_status,omitempty"`
+	ToStatus   int32                  `protobuf:"varint,6,opt,name=to_status,json=toStatus,proto3" json:"to_status,omitempty"`
+	FromState  string                 `protobuf:"bytes,7,opt,name=from_state,json=fromState,proto3" json:"from_state,omitempty"`
+	ToState    string                 `protobuf:"bytes,8,opt,name=to_state,json=toState,proto3" json:"to_state,omitempty"`
+	Error      string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	TxHash     string                 `protobuf:"bytes,10,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	Actor      string                 `protobuf:"bytes,11,opt,name=actor,proto3" json:"actor,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Deposit    *Deposit               `protobuf:"bytes,13,opt,name=deposit,proto3" json:"deposit,omitempty"`
+}
+
+func (x *DepositEvent) Reset() {
+	*x = DepositEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_b2indexer_v1_query_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DepositEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepositEvent) ProtoMessage() {}
+
+func (x *DepositEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_b2indexer_v1_query_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepositEvent.ProtoReflect.Descriptor instead.
+func (*DepositEvent) Descriptor() ([]byte, []int) {
+	return file_b2indexer_v1_query_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DepositEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DepositEvent) GetDepositId() int64 {
+	if x != nil {
+		return x.DepositId
+	}
+	return 0
+}
+
+func (x *DepositEvent) GetBtcTxHash() string {
+	if x != nil {
+		return x.BtcTxHash
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetStatusType() string {
+	if x != nil {
+		return x.StatusType
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetFromStatus() int32 {
+	if x != nil {
+		return x.FromStatus
+	}
+	return 0
+}
+
+func (x *DepositEvent) GetToStatus() int32 {
+	if x != nil {
+		return x.ToStatus
+	}
+	return 0
+}
+
+func (x *DepositEvent) GetFromState() string {
+	if x != nil {
+		return x.FromState
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetToState() string {
+	if x != nil {
+		return x.ToState
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *DepositEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *DepositEvent) GetDeposit() *Deposit {
+	if x != nil {
+		return x.Deposit
+	}
+	return nil
+}
+
+var File_b2indexer_v1_query_proto protoreflect.FileDescriptor
+
+var file_b2indexer_v1_query_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x62, 0x32, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x51, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e,
+	0x0a, 0x0b, 0x62, 0x74, 0x63, 0x5f, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x74, 0x63, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1c,
+	0x0a, 0x0a, 0x62, 0x32, 0x5f, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x62, 0x32, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x22, 0xb1, 0x01, 0x0a,
+	0x13, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a,
+	0x61, 0x61, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x61, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6f, 0x61, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x6f, 0x61, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x22, 0x6a, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x08, 0x64, 0x65, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x32, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x52, 0x08, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e,
+	0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0x32, 0x0a, 0x12,
+	0x47, 0x65, 0x74, 0x57, 0x69, 0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1c, 0x0a, 0x0a, 0x62, 0x32, 0x5f, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x32, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68,
+	0x22, 0x84, 0x01, 0x0a, 0x08, 0x57, 0x69, 0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x12, 0x1c, 0x0a,
+	0x0a, 0x62, 0x32, 0x5f, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x62, 0x32, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x66,
+	0x72, 0x6f, 0x6d, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x62, 0x74, 0x63, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x74, 0x63, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x73, 0x0a, 0x14, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x61, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x61, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22, 0xa5, 0x08, 0x0a,
+	0x07, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x28,
+	0x0a, 0x10, 0x62, 0x74, 0x63, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x62, 0x74, 0x63, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0c, 0x62, 0x74, 0x63, 0x5f,
+	0x74, 0x78, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
+	0x62, 0x74, 0x63, 0x54, 0x78, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x74,
+	0x63, 0x5f, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x62, 0x74, 0x63, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x74,
+	0x63, 0x5f, 0x74, 0x78, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x09, 0x62, 0x74, 0x63, 0x54, 0x78, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x74,
+	0x63, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62,
+	0x74, 0x63, 0x46, 0x72, 0x6f, 0x6d, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x74, 0x63, 0x5f, 0x66,
+	0x72, 0x6f, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x74, 0x63, 0x46, 0x72,
+	0x6f, 0x6d, 0x12, 0x15, 0x0a, 0x06, 0x62, 0x74, 0x63, 0x5f, 0x74, 0x6f, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x62, 0x74, 0x63, 0x54, 0x6f, 0x12, 0x2d, 0x0a, 0x13, 0x62, 0x74, 0x63,
+	0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x61, 0x61, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x74, 0x63, 0x46, 0x72, 0x6f, 0x6d, 0x41,
+	0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x74, 0x63, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x74, 0x63,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1c, 0x0a, 0x0a, 0x62, 0x32, 0x5f, 0x74, 0x78, 0x5f, 0x68,
+	0x61, 0x73, 0x68, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x32, 0x54, 0x78, 0x48,
+	0x61, 0x73, 0x68, 0x12, 0x20, 0x0a, 0x0c, 0x62, 0x32, 0x5f, 0x74, 0x78, 0x5f, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x62, 0x32, 0x54, 0x78, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x32, 0x5f, 0x74, 0x78, 0x5f, 0x72,
+	0x65, 0x74, 0x72, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62, 0x32, 0x54, 0x78,
+	0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x23, 0x0a, 0x0e, 0x62, 0x32, 0x5f, 0x65, 0x6f, 0x61, 0x5f,
+	0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x62,
+	0x32, 0x45, 0x6f, 0x61, 0x54, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x27, 0x0a, 0x10, 0x62, 0x32,
+	0x5f, 0x65, 0x6f, 0x61, 0x5f, 0x74, 0x78, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x62, 0x32, 0x45, 0x6f, 0x61, 0x54, 0x78, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x40, 0x0a, 0x0e, 0x62, 0x74, 0x63, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x62, 0x74, 0x63, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x74, 0x63, 0x5f, 0x6d, 0x65, 0x6d,
+	0x6f, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x74, 0x63, 0x4d, 0x65, 0x6d, 0x6f,
+	0x12, 0x2d, 0x0a, 0x12, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74,
+	0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x72, 0x65,
+	0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12,
+	0x19, 0x0a, 0x08, 0x6c, 0x32, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6c, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x5f, 0x66, 0x65, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x46, 0x65, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x74,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x65,
+	0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74,
+	0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x72,
+	0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x74,
+	0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x1a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x52, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x74,
+	0x74, 0x65, 0x6d, 0x70, 0x74, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x74,
+	0x72, 0x79, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x12, 0x3e, 0x0a, 0x0d, 0x6e, 0x65, 0x78,
+	0x74, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x74, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x6e, 0x65,
+	0x78, 0x74, 0x52, 0x65, 0x74, 0x72, 0x79, 0x41, 0x74, 0x4a, 0x04, 0x08, 0x19, 0x10, 0x1a, 0x4a,
+	0x04, 0x08, 0x1d, 0x10, 0x1e, 0x52, 0x11, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63,
+	0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x22, 0xa7, 0x03, 0x0a, 0x0c, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x64, 0x65, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x74, 0x63, 0x5f, 0x74, 0x78, 0x5f, 0x68,
+	0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x74, 0x63, 0x54, 0x78,
+	0x48, 0x61, 0x73, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x66, 0x72, 0x6f, 0x6d,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6f, 0x5f, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x74, 0x6f, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x6f, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x6f, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05,
+	0x61, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x63, 0x74,
+	0x6f, 0x72, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x2f, 0x0a,
+	0x07, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x52, 0x07, 0x64, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x32, 0xc7,
+	0x02, 0x0a, 0x0c, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12,
+	0x44, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x12, 0x1f, 0x2e,
+	0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15,
+	0x2e, 0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x12, 0x55, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x73, 0x12, 0x21, 0x2e, 0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x62, 0x32, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0b,
+	0x47, 0x65, 0x74, 0x57, 0x69, 0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x12, 0x20, 0x2e, 0x62, 0x32,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x69,
+	0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x69, 0x74,
+	0x68, 0x64, 0x72, 0x61, 0x77, 0x12, 0x51, 0x0a, 0x0d, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x73, 0x12, 0x22, 0x2e, 0x62, 0x32, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x62, 0x32, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x32, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x2f, 0x62, 0x32, 0x2d, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_b2indexer_v1_query_proto_rawDescOnce sync.Once
+	file_b2indexer_v1_query_proto_rawDescData = file_b2indexer_v1_query_proto_rawDesc
+)
+
+func file_b2indexer_v1_query_proto_rawDescGZIP() []byte {
+	file_b2indexer_v1_query_proto_rawDescOnce.Do(func() {
+		file_b2indexer_v1_query_proto_rawDescData = protoimpl.X.CompressGZIP(file_b2indexer_v1_query_proto_rawDescData)
+	})
+	return file_b2indexer_v1_query_proto_rawDescData
+}
+
+var file_b2indexer_v1_query_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_b2indexer_v1_query_proto_goTypes = []interface{}{
+	(*GetDepositRequest)(nil),     // 0: b2indexer.v1.GetDepositRequest
+	(*ListDepositsRequest)(nil),   // 1: b2indexer.v1.ListDepositsRequest
+	(*ListDepositsResponse)(nil),  // 2: b2indexer.v1.ListDepositsResponse
+	(*GetWithdrawRequest)(nil),    // 3: b2indexer.v1.GetWithdrawRequest
+	(*Withdraw)(nil),              // 4: b2indexer.v1.Withdraw
+	(*WatchDepositsRequest)(nil),  // 5: b2indexer.v1.WatchDepositsRequest
+	(*Deposit)(nil),               // 6: b2indexer.v1.Deposit
+	(*DepositEvent)(nil),          // 7: b2indexer.v1.DepositEvent
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_b2indexer_v1_query_proto_depIdxs = []int32{
+	6,  // 0: b2indexer.v1.ListDepositsResponse.deposits:type_name -> b2indexer.v1.Deposit
+	8,  // 1: b2indexer.v1.Deposit.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 2: b2indexer.v1.Deposit.updated_at:type_name -> google.protobuf.Timestamp
+	8,  // 3: b2indexer.v1.Deposit.btc_block_time:type_name -> google.protobuf.Timestamp
+	8,  // 4: b2indexer.v1.Deposit.next_retry_at:type_name -> google.protobuf.Timestamp
+	8,  // 5: b2indexer.v1.DepositEvent.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 6: b2indexer.v1.DepositEvent.deposit:type_name -> b2indexer.v1.Deposit
+	0,  // 7: b2indexer.v1.IndexerQuery.GetDeposit:input_type -> b2indexer.v1.GetDepositRequest
+	1,  // 8: b2indexer.v1.IndexerQuery.ListDeposits:input_type -> b2indexer.v1.ListDepositsRequest
+	3,  // 9: b2indexer.v1.IndexerQuery.GetWithdraw:input_type -> b2indexer.v1.GetWithdrawRequest
+	5,  // 10: b2indexer.v1.IndexerQuery.WatchDeposits:input_type -> b2indexer.v1.WatchDepositsRequest
+	6,  // 11: b2indexer.v1.IndexerQuery.GetDeposit:output_type -> b2indexer.v1.Deposit
+	2,  // 12: b2indexer.v1.IndexerQuery.ListDeposits:output_type -> b2indexer.v1.ListDepositsResponse
+	4,  // 13: b2indexer.v1.IndexerQuery.GetWithdraw:output_type -> b2indexer.v1.Withdraw
+	7,  // 14: b2indexer.v1.IndexerQuery.WatchDeposits:output_type -> b2indexer.v1.DepositEvent
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_b2indexer_v1_query_proto_init() }
+func file_b2indexer_v1_query_proto_init() {
+	if File_b2indexer_v1_query_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_b2indexer_v1_query_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDepositRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDepositsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDepositsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetWithdrawRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Withdraw); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchDepositsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Deposit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_b2indexer_v1_query_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DepositEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_b2indexer_v1_query_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_b2indexer_v1_query_proto_goTypes,
+		DependencyIndexes: file_b2indexer_v1_query_proto_depIdxs,
+		MessageInfos:      file_b2indexer_v1_query_proto_msgTypes,
+	}.Build()
+	File_b2indexer_v1_query_proto = out.File
+	file_b2indexer_v1_query_proto_rawDesc = nil
+	file_b2indexer_v1_query_proto_goTypes = nil
+	file_b2indexer_v1_query_proto_depIdxs = nil
+}
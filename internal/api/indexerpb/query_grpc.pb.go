@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: b2indexer/v1/query.proto
+
+package indexerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	IndexerQuery_GetDeposit_FullMethodName    = "/b2indexer.v1.IndexerQuery/GetDeposit"
+	IndexerQuery_ListDeposits_FullMethodName  = "/b2indexer.v1.IndexerQuery/ListDeposits"
+	IndexerQuery_GetWithdraw_FullMethodName   = "/b2indexer.v1.IndexerQuery/GetWithdraw"
+	IndexerQuery_WatchDeposits_FullMethodName = "/b2indexer.v1.IndexerQuery/WatchDeposits"
+)
+
+// IndexerQueryClient is the client API for IndexerQuery service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IndexerQueryClient interface {
+	// GetDeposit returns the deposit by the bitcoin tx hash, or the b2 tx hash of the contract deposit or eoa transfer
+	GetDeposit(ctx context.Context, in *GetDepositRequest, opts ...grpc.CallOption) (*Deposit, error)
+	// ListDeposits lists the deposits of the sender or the aa address, newest first
+	ListDeposits(ctx context.Context, in *ListDepositsRequest, opts ...grpc.CallOption) (*ListDepositsResponse, error)
+	// GetWithdraw returns the withdraw by the b2 tx hash, decoded from the WithdrawEvent log of the tx receipt
+	GetWithdraw(ctx context.Context, in *GetWithdrawRequest, opts ...grpc.CallOption) (*Withdraw, error)
+	// WatchDeposits pushes the deposit status changes of the sender or the aa address, all deposits if both empty
+	WatchDeposits(ctx context.Context, in *WatchDepositsRequest, opts ...grpc.CallOption) (IndexerQuery_WatchDepositsClient, error)
+}
+
+type indexerQueryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIndexerQueryClient(cc grpc.ClientConnInterface) IndexerQueryClient {
+	return &indexerQueryClient{cc}
+}
+
+func (c *indexerQueryClient) GetDeposit(ctx context.Context, in *GetDepositRequest, opts ...grpc.CallOption) (*Deposit, error) {
+	out := new(Deposit)
+	err := c.cc.Invoke(ctx, IndexerQuery_GetDeposit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerQueryClient) ListDeposits(ctx context.Context, in *ListDepositsRequest, opts ...grpc.CallOption) (*ListDepositsResponse, error) {
+	out := new(ListDepositsResponse)
+	err := c.cc.Invoke(ctx, IndexerQuery_ListDeposits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerQueryClient) GetWithdraw(ctx context.Context, in *GetWithdrawRequest, opts ...grpc.CallOption) (*Withdraw, error) {
+	out := new(Withdraw)
+	err := c.cc.Invoke(ctx, IndexerQuery_GetWithdraw_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerQueryClient) WatchDeposits(ctx context.Context, in *WatchDepositsRequest, opts ...grpc.CallOption) (IndexerQuery_WatchDepositsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &IndexerQuery_ServiceDesc.Streams[0], IndexerQuery_WatchDeposits_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexerQueryWatchDepositsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexerQuery_WatchDepositsClient interface {
+	Recv() (*DepositEvent, error)
+	grpc.ClientStream
+}
+
+type indexerQueryWatchDepositsClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexerQueryWatchDepositsClient) Recv() (*DepositEvent, error) {
+	m := new(DepositEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IndexerQueryServer is the server API for IndexerQuery service.
+// All implementations must embed UnimplementedIndexerQueryServer
+// for forward compatibility
+type IndexerQueryServer interface {
+	// GetDeposit returns the deposit by the bitcoin tx hash, or the b2 tx hash of the contract deposit or eoa transfer
+	GetDeposit(context.Context, *GetDepositRequest) (*Deposit, error)
+	// ListDeposits lists the deposits of the sender or the aa address, newest first
+	ListDeposits(context.Context, *ListDepositsRequest) (*ListDepositsResponse, error)
+	// GetWithdraw returns the withdraw by the b2 tx hash, decoded from the WithdrawEvent log of the tx receipt
+	GetWithdraw(context.Context, *GetWithdrawRequest) (*Withdraw, error)
+	// WatchDeposits pushes the deposit status changes of the sender or the aa address, all deposits if both empty
+	WatchDeposits(*WatchDepositsRequest, IndexerQuery_WatchDepositsServer) error
+	mustEmbedUnimplementedIndexerQueryServer()
+}
+
+// UnimplementedIndexerQueryServer must be embedded to have forward compatible implementations.
+type UnimplementedIndexerQueryServer struct {
+}
+
+func (UnimplementedIndexerQueryServer) GetDeposit(context.Context, *GetDepositRequest) (*Deposit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeposit not implemented")
+}
+func (UnimplementedIndexerQueryServer) ListDeposits(context.Context, *ListDepositsRequest) (*ListDepositsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeposits not implemented")
+}
+func (UnimplementedIndexerQueryServer) GetWithdraw(context.Context, *GetWithdrawRequest) (*Withdraw, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWithdraw not implemented")
+}
+func (UnimplementedIndexerQueryServer) WatchDeposits(*WatchDepositsRequest, IndexerQuery_WatchDepositsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDeposits not implemented")
+}
+func (UnimplementedIndexerQueryServer) mustEmbedUnimplementedIndexerQueryServer() {}
+
+// UnsafeIndexerQueryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IndexerQueryServer will
+// result in compilation errors.
+type UnsafeIndexerQueryServer interface {
+	mustEmbedUnimplementedIndexerQueryServer()
+}
+
+func RegisterIndexerQueryServer(s grpc.ServiceRegistrar, srv IndexerQueryServer) {
+	s.RegisterService(&IndexerQuery_ServiceDesc, srv)
+}
+
+func _IndexerQuery_GetDeposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerQueryServer).GetDeposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerQuery_GetDeposit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerQueryServer).GetDeposit(ctx, req.(*GetDepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexerQuery_ListDeposits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDepositsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerQueryServer).ListDeposits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerQuery_ListDeposits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerQueryServer).ListDeposits(ctx, req.(*ListDepositsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexerQuery_GetWithdraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWithdrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerQueryServer).GetWithdraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerQuery_GetWithdraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerQueryServer).GetWithdraw(ctx, req.(*GetWithdrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexerQuery_WatchDeposits_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDepositsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerQueryServer).WatchDeposits(m, &indexerQueryWatchDepositsServer{stream})
+}
+
+type IndexerQuery_WatchDepositsServer interface {
+	Send(*DepositEvent) error
+	grpc.ServerStream
+}
+
+type indexerQueryWatchDepositsServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexerQueryWatchDepositsServer) Send(m *DepositEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// IndexerQuery_ServiceDesc is the grpc.ServiceDesc for IndexerQuery service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IndexerQuery_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "b2indexer.v1.IndexerQuery",
+	HandlerType: (*IndexerQueryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDeposit",
+			Handler:    _IndexerQuery_GetDeposit_Handler,
+		},
+		{
+			MethodName: "ListDeposits",
+			Handler:    _IndexerQuery_ListDeposits_Handler,
+		},
+		{
+			MethodName: "GetWithdraw",
+			Handler:    _IndexerQuery_GetWithdraw_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDeposits",
+			Handler:       _IndexerQuery_WatchDeposits_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "b2indexer/v1/query.proto",
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/metrics"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const MetricsQueryTimeout = 5 * time.Second
+
+var (
+	depositsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "deposits"),
+		"Deposits by b2 tx status.",
+		[]string{"status", "state"}, nil,
+	)
+	epsQueueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "eps_queue_depth"),
+		"EPS outbox by delivery status, pending, dead letter and ingest failed.",
+		[]string{"state"}, nil,
+	)
+)
+
+// DBCollector collects the deposits by status and the eps queue depth from the db on scrape
+type DBCollector struct {
+	db  *gorm.DB
+	log log.Logger
+}
+
+// NewDBCollector new db collector
+func NewDBCollector(db *gorm.DB, logger log.Logger) *DBCollector {
+	return &DBCollector{db: db, log: logger}
+}
+
+func (c *DBCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- depositsDesc
+	ch <- epsQueueDesc
+}
+
+type statusCount struct {
+	Status int
+	Count  int64
+}
+
+func (c *DBCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), MetricsQueryTimeout)
+	defer cancel()
+
+	deposits, err := c.countByStatus(ctx, &model.Deposit{}, model.Deposit{}.Column().B2TxStatus)
+	if err != nil {
+		c.log.Errorw("metrics count deposits err", "error", err)
+		ch <- prometheus.NewInvalidMetric(depositsDesc, err)
+	}
+	for _, v := range deposits {
+		ch <- prometheus.MustNewConstMetric(depositsDesc, prometheus.GaugeValue, float64(v.Count),
+			strconv.Itoa(v.Status), bitcoin.DepositStatusName(v.Status))
+	}
+
+	// the eps table exists only if eps enabled
+	if !c.db.Migrator().HasTable(&model.Eps{}) {
+		return
+	}
+	eps, err := c.countByStatus(ctx, &model.Eps{}, model.Eps{}.Column().Status)
+	if err != nil {
+		c.log.Errorw("metrics count eps err", "error", err)
+		ch <- prometheus.NewInvalidMetric(epsQueueDesc, err)
+	}
+	queue := map[int]int64{model.EspStatus: 0, model.EspStatusDeadLetter: 0, model.EspStatusIngestFailed: 0}
+	for _, v := range eps {
+		if _, ok := queue[v.Status]; ok {
+			queue[v.Status] = v.Count
+		}
+	}
+	for status, count := range queue {
+		ch <- prometheus.MustNewConstMetric(epsQueueDesc, prometheus.GaugeValue, float64(count), bitcoin.EpsStatusName(status))
+	}
+}
+
+func (c *DBCollector) countByStatus(ctx context.Context, table interface{}, column string) ([]statusCount, error) {
+	var counts []statusCount
+	err := c.db.WithContext(ctx).Model(table).
+		Select(fmt.Sprintf("%s AS status, COUNT(*) AS count", column)).
+		Group(column).
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPISpec returns the OpenAPI spec of the http api, the schemas are generated from the json tags of the models
+func OpenAPISpec() map[string]interface{} {
+	return map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "b2-indexer api",
+			"description": "read-only query api of bitcoin deposits and indexer state",
+			"version":     "v1",
+		},
+		"paths": map[string]interface{}{
+			"/v1/deposit": map[string]interface{}{
+				"get": operation("getDeposit", "get the deposit by the bitcoin tx hash or the b2 tx hash", []interface{}{
+					queryParam("btc_tx_hash", "bitcoin tx hash", "string"),
+					queryParam("b2_tx_hash", "b2 network tx hash of the contract deposit or the eoa transfer", "string"),
+				}, "Deposit"),
+			},
+			"/v1/deposits": map[string]interface{}{
+				"get": operation("listDeposits", "list the deposits of the sender or the aa address, newest first", []interface{}{
+					queryParam("sender", "bitcoin from address", "string"),
+					queryParam("aa_address", "b2 network aa address of the sender", "string"),
+					queryParam("status", "b2 tx status name or number, eg. success, pending, in_flight", "string"),
+					queryParam("eoa_status", "b2 eoa tx status name or number", "string"),
+					queryParam("cursor", "next_cursor of the previous page", "string"),
+					queryParam("limit", "page size, default 50, max 500", "integer"),
+				}, "DepositList"),
+			},
+			"/v1/ws": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "watchDeposits",
+					"summary": "websocket subscription of the deposit lifecycle stages: " +
+						"detected, confirmed, submitted, mined, eps_delivered, held and failed",
+					"parameters": []interface{}{
+						queryParam("btc_tx_hash", "bitcoin tx hash, the past stages are replayed first", "string"),
+						queryParam("address", "bitcoin from address, the stages from now", "string"),
+						queryParam("after_event_id", "event_id of the last received message to resume", "integer"),
+					},
+					"responses": map[string]interface{}{
+						"101": response("switching protocols, the messages are DepositStatusMessage", "DepositStatusMessage"),
+						"400": response("invalid param", "ErrorResponse"),
+						"404": response("not found", "ErrorResponse"),
+					},
+				},
+			},
+			"/v1/indexer": map[string]interface{}{
+				"get": operation("getIndexer", "get the indexed checkpoint and the bitcoin chain tip", nil, "IndexerState"),
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Deposit":              schemaOf(reflect.TypeOf(DepositResponse{})),
+				"BtcIndex":             schemaOf(reflect.TypeOf(model.BtcIndex{})),
+				"DepositList":          schemaOf(reflect.TypeOf(DepositList{})),
+				"IndexerState":         schemaOf(reflect.TypeOf(IndexerState{})),
+				"ErrorResponse":        schemaOf(reflect.TypeOf(ErrorResponse{})),
+				"DepositStatusMessage": schemaOf(reflect.TypeOf(DepositStatusMessage{})),
+			},
+		},
+	}
+}
+
+func operation(id string, summary string, params []interface{}, schema string) map[string]interface{} {
+	op := map[string]interface{}{
+		"operationId": id,
+		"summary":     summary,
+		"responses": map[string]interface{}{
+			"200": response("ok", schema),
+			"400": response("invalid param", "ErrorResponse"),
+			"404": response("not found", "ErrorResponse"),
+			"500": response("internal error", "ErrorResponse"),
+		},
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+func response(description string, schema string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": schemaRef(schema),
+			},
+		},
+	}
+}
+
+func queryParam(name string, description string, typ string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"schema":      map[string]interface{}{"type": typ},
+	}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	bigIntType    = reflect.TypeOf(model.BigInt{})
+	schemaNames   = map[reflect.Type]string{
+		reflect.TypeOf(DepositResponse{}): "Deposit",
+		reflect.TypeOf(model.BtcIndex{}):  "BtcIndex",
+	}
+)
+
+// schemaOf returns the schema of the type by the json tags, embedded structs are flattened
+func schemaOf(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case deletedAtType:
+		return map[string]interface{}{"type": "string", "format": "date-time", "nullable": true}
+	case bigIntType:
+		return map[string]interface{}{"type": "integer"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaOrRef(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		addProperties(t, properties)
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return map[string]interface{}{}
+}
+
+func schemaOrRef(t reflect.Type) map[string]interface{} {
+	if name, ok := schemaNames[t]; ok {
+		return schemaRef(name)
+	}
+	return schemaOf(t)
+}
+
+func addProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" {
+			addProperties(field.Type, properties)
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = schemaOrRef(field.Type)
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+var (
+	ErrInvalidParam = errors.New("invalid param")
+	ErrNotFound     = errors.New("not found")
+)
+
+// DepositQuery the deposits filter of the sender or the aa address, status names or numbers
+type DepositQuery struct {
+	Sender    string
+	AAAddress string
+	Status    string
+	EoaStatus string
+	Cursor    string
+	Limit     int
+}
+
+// DepositList the deposits page, next cursor is empty on the last page
+type DepositList struct {
+	Deposits   []DepositResponse `json:"deposits"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// DepositResponse the public fields of the deposit, the internal bridge state is not exposed,
+// eg. the worker lease, the signed raw tx, the compliance reason and the handle error
+type DepositResponse struct {
+	ID                int64        `json:"id"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+	BtcBlockNumber    int64        `json:"btc_block_number"`
+	BtcTxIndex        int64        `json:"btc_tx_index"`
+	BtcTxHash         string       `json:"btc_tx_hash"`
+	BtcTxType         int          `json:"btc_tx_type"`
+	BtcFroms          string       `json:"btc_froms"`
+	BtcFrom           string       `json:"btc_from"`
+	BtcTo             string       `json:"btc_to"`
+	BtcFromAAAddress  string       `json:"btc_from_aa_address"`
+	BtcValue          model.BigInt `json:"btc_value"`
+	B2TxHash          string       `json:"b2_tx_hash"`
+	B2TxStatus        int          `json:"b2_tx_status"`
+	B2TxRetry         int          `json:"b2_tx_retry"`
+	B2EoaTxHash       string       `json:"b2_eoa_tx_hash"`
+	B2EoaTxStatus     int          `json:"b2_eoa_tx_status"`
+	BtcBlockTime      time.Time    `json:"btc_block_time"`
+	BtcMemo           string       `json:"btc_memo"`
+	RecipientStrategy string       `json:"recipient_strategy"`
+	L2Value           model.BigInt `json:"l2_value"`
+	BridgeFee         model.BigInt `json:"bridge_fee"`
+	NetValue          model.BigInt `json:"net_value"`
+	RejectReason      string       `json:"reject_reason"`
+	ThrottleReason    string       `json:"throttle_reason"`
+	RetryAttempt      int          `json:"retry_attempt"`
+	NextRetryAt       time.Time    `json:"next_retry_at"`
+}
+
+// NewDepositResponse returns the public fields of the deposit
+func NewDepositResponse(deposit model.Deposit) DepositResponse {
+	return DepositResponse{
+		ID:                deposit.ID,
+		CreatedAt:         deposit.CreatedAt,
+		UpdatedAt:         deposit.UpdatedAt,
+		BtcBlockNumber:    deposit.BtcBlockNumber,
+		BtcTxIndex:        deposit.BtcTxIndex,
+		BtcTxHash:         deposit.BtcTxHash,
+		BtcTxType:         deposit.BtcTxType,
+		BtcFroms:          deposit.BtcFroms,
+		BtcFrom:           deposit.BtcFrom,
+		BtcTo:             deposit.BtcTo,
+		BtcFromAAAddress:  deposit.BtcFromAAAddress,
+		BtcValue:          deposit.BtcValue,
+		B2TxHash:          deposit.B2TxHash,
+		B2TxStatus:        deposit.B2TxStatus,
+		B2TxRetry:         deposit.B2TxRetry,
+		B2EoaTxHash:       deposit.B2EoaTxHash,
+		B2EoaTxStatus:     deposit.B2EoaTxStatus,
+		BtcBlockTime:      deposit.BtcBlockTime,
+		BtcMemo:           deposit.BtcMemo,
+		RecipientStrategy: deposit.RecipientStrategy,
+		L2Value:           deposit.L2Value,
+		BridgeFee:         deposit.BridgeFee,
+		NetValue:          deposit.NetValue,
+		RejectReason:      deposit.RejectReason,
+		ThrottleReason:    deposit.ThrottleReason,
+		RetryAttempt:      deposit.RetryAttempt,
+		NextRetryAt:       deposit.NextRetryAt,
+	}
+}
+
+// GetDeposit returns the deposit by the bitcoin tx hash, or the b2 tx hash of the contract deposit or eoa transfer
+func GetDeposit(ctx context.Context, db *gorm.DB, btcTxHash string, b2TxHash string) (model.Deposit, error) {
+	if btcTxHash == "" && b2TxHash == "" {
+		return model.Deposit{}, fmt.Errorf("%w: btc_tx_hash or b2_tx_hash is required", ErrInvalidParam)
+	}
+	db = db.WithContext(ctx)
+	if btcTxHash != "" {
+		db = db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().BtcTxHash), btcTxHash)
+	} else {
+		db = db.Where(fmt.Sprintf("%s = ? OR %s = ?", model.Deposit{}.Column().B2TxHash, model.Deposit{}.Column().B2EoaTxHash),
+			b2TxHash, b2TxHash)
+	}
+	var deposits []model.Deposit
+	if err := db.Order("id").Limit(1).Find(&deposits).Error; err != nil {
+		return model.Deposit{}, err
+	}
+	if len(deposits) == 0 {
+		return model.Deposit{}, fmt.Errorf("deposit %w", ErrNotFound)
+	}
+	return deposits[0], nil
+}
+
+// ListDeposits lists the deposits of the query in id descending order, returns the next cursor, empty on the last page
+func ListDeposits(ctx context.Context, db *gorm.DB, q DepositQuery) ([]model.Deposit, string, error) {
+	scope, err := q.scope()
+	if err != nil {
+		return nil, "", err
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	if limit < 0 || limit > MaxListLimit {
+		return nil, "", fmt.Errorf("%w: limit %d, 1 to %d", ErrInvalidParam, limit, MaxListLimit)
+	}
+	db = db.WithContext(ctx).Scopes(scope)
+	if q.Cursor != "" {
+		cursor, err := strconv.ParseInt(q.Cursor, 10, 64)
+		if err != nil || cursor <= 0 {
+			return nil, "", fmt.Errorf("%w: cursor %s", ErrInvalidParam, q.Cursor)
+		}
+		db = db.Where("id < ?", cursor)
+	}
+	var deposits []model.Deposit
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&deposits).Error; err != nil {
+		return nil, "", err
+	}
+	if len(deposits) > limit {
+		deposits = deposits[:limit]
+		return deposits, strconv.FormatInt(deposits[limit-1].ID, 10), nil
+	}
+	return deposits, "", nil
+}
+
+// scope validates the query, returns the deposits filter
+func (q DepositQuery) scope() (func(*gorm.DB) *gorm.DB, error) {
+	if q.Sender == "" && q.AAAddress == "" {
+		return nil, fmt.Errorf("%w: sender or aa_address is required", ErrInvalidParam)
+	}
+	status, eoaStatus := -1, -1
+	var err error
+	if q.Status != "" {
+		if status, err = bitcoin.ParseDepositStatus(q.Status); err != nil {
+			return nil, err
+		}
+	}
+	if q.EoaStatus != "" {
+		if eoaStatus, err = bitcoin.ParseDepositEoaStatus(q.EoaStatus); err != nil {
+			return nil, err
+		}
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if q.Sender != "" {
+			db = db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().BtcFrom), q.Sender)
+		}
+		if q.AAAddress != "" {
+			db = db.Where(fmt.Sprintf("LOWER(%s) = LOWER(?)", model.Deposit{}.Column().BtcFromAAAddress), q.AAAddress)
+		}
+		if q.Status != "" {
+			db = db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().B2TxStatus), status)
+		}
+		if q.EoaStatus != "" {
+			db = db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().B2EoaTxStatus), eoaStatus)
+		}
+		return db
+	}, nil
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// WatchPollInterval the watch polls the deposit events, the deposit notification wakes it earlier
+	WatchPollInterval = 5 * time.Second
+	// re-scan the deposit events committed late by concurrent transactions
+	WatchLookback   = time.Minute
+	WatchBatchLimit = 100
+)
+
+// watchDepositEvents calls handle with the deposit events after the cursor and their deposits until the context done.
+// Every poll looks back from the cursor on the first page for the events committed late, the next pages start
+// strictly after the last event, the handled events are skipped.
+// The scope filters the deposit events in the query, eg. by the bitcoin tx hash or the sender of the deposits.
+func watchDepositEvents(
+	ctx context.Context,
+	db *gorm.DB,
+	listener *bitcoin.DepositListener,
+	cursor model.SyncCursor,
+	scope func(*gorm.DB) *gorm.DB,
+	handle func(model.DepositEvent, model.Deposit) error,
+) error {
+	wakeup := listener.Subscribe()
+	defer listener.Unsubscribe(wakeup)
+
+	handled := map[int64]time.Time{}
+	ticker := time.NewTicker(WatchPollInterval)
+	defer ticker.Stop()
+	for {
+		page, lookback := cursor, WatchLookback
+		for {
+			var events []model.DepositEvent
+			query := db.WithContext(ctx).Scopes(bitcoin.DepositEventsAfterCursor(page, lookback, WatchBatchLimit))
+			if scope != nil {
+				query = query.Scopes(scope)
+			}
+			if err := query.Find(&events).Error; err != nil {
+				return err
+			}
+			var fresh []model.DepositEvent
+			for _, event := range events {
+				bitcoin.AdvanceSyncCursor(&cursor, event.CreatedAt, event.ID)
+				if _, ok := handled[event.ID]; ok {
+					continue
+				}
+				handled[event.ID] = event.CreatedAt
+				fresh = append(fresh, event)
+			}
+			if err := handleDepositEvents(ctx, db, fresh, handle); err != nil {
+				return err
+			}
+			if len(events) < WatchBatchLimit {
+				break
+			}
+			page.CursorTime, page.CursorID = events[len(events)-1].CreatedAt, events[len(events)-1].ID
+			lookback = 0
+		}
+		for id, createdAt := range handled {
+			if createdAt.Before(cursor.CursorTime.Add(-2 * WatchLookback)) {
+				delete(handled, id)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// depositEventsOfDeposits scopes the deposit events of the deposits matching the condition on the deposit columns,
+// eg. the sender, so that the watch pages over the subscribed events only
+func depositEventsOfDeposits(query string, args ...interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s.id = %s.%s AND %s)",
+			model.Deposit{}.TableName(), model.Deposit{}.TableName(),
+			model.DepositEvent{}.TableName(), model.DepositEvent{}.Column().DepositID, query), args...)
+	}
+}
+
+// handleDepositEvents loads the deposits of the events, and calls handle in the event order
+func handleDepositEvents(
+	ctx context.Context,
+	db *gorm.DB,
+	events []model.DepositEvent,
+	handle func(model.DepositEvent, model.Deposit) error,
+) error {
+	if len(events) == 0 {
+		return nil
+	}
+	depositIDs := make([]int64, 0, len(events))
+	for _, event := range events {
+		depositIDs = append(depositIDs, event.DepositID)
+	}
+	var deposits []model.Deposit
+	if err := db.WithContext(ctx).Where("id IN ?", depositIDs).Find(&deposits).Error; err != nil {
+		return err
+	}
+	depositMap := make(map[int64]model.Deposit, len(deposits))
+	for _, deposit := range deposits {
+		depositMap[deposit.ID] = deposit
+	}
+	for _, event := range events {
+		if err := handle(event, depositMap[event.DepositID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchCursor returns the cursor of the event id, the latest event if 0
+func watchCursor(ctx context.Context, db *gorm.DB, eventID int64) (model.SyncCursor, error) {
+	var cursor model.SyncCursor
+	query := db.WithContext(ctx)
+	if eventID > 0 {
+		query = query.Where("id = ?", eventID)
+	} else {
+		query = query.Order("id DESC")
+	}
+	var events []model.DepositEvent
+	if err := query.Limit(1).Find(&events).Error; err != nil {
+		return cursor, err
+	}
+	if len(events) == 0 {
+		if eventID > 0 {
+			return cursor, fmt.Errorf("deposit event %d %w", eventID, ErrNotFound)
+		}
+		return cursor, nil
+	}
+	cursor.CursorTime, cursor.CursorID = events[0].CreatedAt, events[0].ID
+	return cursor, nil
+}
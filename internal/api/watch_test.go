@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/api"
+	"github.com/b2network/b2-indexer/internal/api/indexerpb"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/testutil"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// mockWatchStream collects the sent deposit events
+type mockWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	mu     sync.Mutex
+	events []*indexerpb.DepositEvent
+}
+
+func (m *mockWatchStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockWatchStream) Send(event *indexerpb.DepositEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockWatchStream) eventIDs() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int64, 0, len(m.events))
+	for _, event := range m.events {
+		ids = append(ids, event.Id)
+	}
+	return ids
+}
+
+func createDepositEvents(t *testing.T, db *gorm.DB, deposit model.Deposit, n int, createdAt time.Time) []int64 {
+	events := make([]model.DepositEvent, n)
+	for i := range events {
+		events[i] = model.DepositEvent{
+			CreatedAt:  createdAt,
+			DepositID:  deposit.ID,
+			BtcTxHash:  deposit.BtcTxHash,
+			StatusType: model.DepositEventStatusB2Tx,
+			FromStatus: model.DepositStatusNone,
+			ToStatus:   model.DepositB2TxStatusPending,
+		}
+	}
+	require.NoError(t, db.Create(&events).Error)
+	ids := make([]int64, 0, n)
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestLocalWatchDeposits(t *testing.T) {
+	db := testutil.LocalDB(t)
+	deposit := model.Deposit{BtcTxHash: fmt.Sprintf("%064d", 1), BtcFrom: "tb1qfrom", BtcFroms: "[]"}
+	require.NoError(t, db.Create(&deposit).Error)
+	// the watch starts from the latest event
+	createDepositEvents(t, db, deposit, 1, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &mockWatchStream{ctx: ctx}
+	server := api.NewGRPCServer(db, nil, nil, log.NewNopLogger())
+	done := make(chan error, 1)
+	go func() { done <- server.WatchDeposits(&indexerpb.WatchDepositsRequest{Sender: "tb1qfrom"}, stream) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+	waitPoll := 3 * api.WatchPollInterval
+
+	// more events than a batch inside the lookback window
+	expected := createDepositEvents(t, db, deposit, api.WatchBatchLimit+50, time.Now())
+	require.Eventually(t, func() bool { return len(stream.eventIDs()) >= len(expected) }, waitPoll, 100*time.Millisecond)
+
+	// the late committed event inside the window and the new event after the full window
+	expected = append(expected, createDepositEvents(t, db, deposit, 1, time.Now().Add(-api.WatchLookback/2))...)
+	expected = append(expected, createDepositEvents(t, db, deposit, 1, time.Now())...)
+	require.Eventually(t, func() bool { return len(stream.eventIDs()) >= len(expected) }, waitPoll, 100*time.Millisecond)
+	// the events of the other senders are filtered in the query
+	other := model.Deposit{BtcTxHash: fmt.Sprintf("%064d", 2), BtcFrom: "tb1qother", BtcFroms: "[]"}
+	require.NoError(t, db.Create(&other).Error)
+	createDepositEvents(t, db, other, api.WatchBatchLimit+50, time.Now())
+	expected = append(expected, createDepositEvents(t, db, deposit, 1, time.Now())...)
+	require.Eventually(t, func() bool { return len(stream.eventIDs()) >= len(expected) }, waitPoll, 100*time.Millisecond)
+	// sent once
+	time.Sleep(api.WatchPollInterval)
+	require.ElementsMatch(t, expected, stream.eventIDs())
+}
+
+func TestLocalWSAddress(t *testing.T) {
+	db := testutil.LocalDB(t)
+	deposit := model.Deposit{BtcTxHash: fmt.Sprintf("%064d", 1), BtcFrom: "tb1qfrom", BtcFroms: "[]"}
+	other := model.Deposit{BtcTxHash: fmt.Sprintf("%064d", 2), BtcFrom: "tb1qother", BtcFroms: "[]"}
+	require.NoError(t, db.Create(&deposit).Error)
+	require.NoError(t, db.Create(&other).Error)
+	createDepositEvents(t, db, deposit, 1, time.Now())
+
+	server := httptest.NewServer(api.NewServer(db, nil, nil, log.NewNopLogger()).Handler())
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/v1/ws?address=tb1qfrom", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	createDepositEvents(t, db, other, api.WatchBatchLimit+50, time.Now())
+	expected := createDepositEvents(t, db, deposit, 1, time.Now())
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*api.WatchPollInterval)))
+	var msg api.DepositStatusMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, expected[0], msg.EventID)
+	require.Equal(t, "tb1qfrom", msg.BtcFrom)
+}
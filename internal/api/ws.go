@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	WSWriteTimeout = 10 * time.Second
+	// WSPongTimeout the connection is closed if no pong in the timeout, pings are sent at 90% of it
+	WSPongTimeout  = time.Minute
+	WSPingInterval = WSPongTimeout * 9 / 10
+	WSReadLimit    = 1024
+)
+
+// DepositStatusMessage the websocket message of the deposit lifecycle stage
+type DepositStatusMessage struct {
+	Stage      string    `json:"stage"`
+	EventID    int64     `json:"event_id"`
+	DepositID  int64     `json:"deposit_id"`
+	BtcTxHash  string    `json:"btc_tx_hash"`
+	BtcFrom    string    `json:"btc_from"`
+	BtcValue   string    `json:"btc_value"`
+	StatusType string    `json:"status_type"`
+	State      string    `json:"state"`
+	TxHash     string    `json:"tx_hash"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// the api is read-only and cookie-less, the wallet frontends of any origin can subscribe
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// handleWS subscribes the deposit lifecycle stages by the bitcoin tx hash or the bitcoin from address.
+// The tx hash subscription replays the stages of the deposit first, the address subscription starts from now.
+// after_event_id resumes from the last received event_id on reconnect.
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	btcTxHash, address := query.Get("btc_tx_hash"), query.Get("address")
+	if (btcTxHash == "") == (address == "") {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: fmt.Sprintf("%s: one of btc_tx_hash or address is required", ErrInvalidParam),
+		})
+		return
+	}
+	var afterEventID int64
+	if v := query.Get("after_event_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("%s: after_event_id %s", ErrInvalidParam, v)})
+			return
+		}
+		afterEventID = id
+	}
+
+	var (
+		cursor model.SyncCursor
+		scope  func(*gorm.DB) *gorm.DB
+		err    error
+	)
+	if btcTxHash != "" {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where(fmt.Sprintf("%s.%s = ?", model.DepositEvent{}.TableName(), model.DepositEvent{}.Column().BtcTxHash), btcTxHash)
+		}
+	} else {
+		scope = depositEventsOfDeposits(fmt.Sprintf("%s.%s = ?", model.Deposit{}.TableName(), model.Deposit{}.Column().BtcFrom), address)
+	}
+	if afterEventID > 0 || btcTxHash == "" {
+		cursor, err = watchCursor(r.Context(), s.db, afterEventID)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader replied the error
+		s.log.Warnw("websocket upgrade err", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.wsKeepalive(ctx, cancel, conn)
+
+	err = watchDepositEvents(ctx, s.db, s.listener, cursor, scope, func(event model.DepositEvent, deposit model.Deposit) error {
+		for _, stage := range bitcoin.DepositStages(event) {
+			msg := NewDepositStatusMessage(stage, event, deposit)
+			_ = conn.SetWriteDeadline(time.Now().Add(WSWriteTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		s.log.Errorw("websocket watch err", "error", err)
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "internal error"),
+			time.Now().Add(WSWriteTimeout))
+	}
+}
+
+// wsKeepalive reads the client frames for the pongs and close, pings the client,
+// cancels the watch once the connection is gone
+func (s *Server) wsKeepalive(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	conn.SetReadLimit(WSReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(WSPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(WSPongTimeout))
+	})
+	go func() {
+		defer cancel()
+		for {
+			// the subscription is set by the url, client messages are ignored
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(WSPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WSWriteTimeout)); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// NewDepositStatusMessage returns the websocket message of the stage
+func NewDepositStatusMessage(stage string, event model.DepositEvent, deposit model.Deposit) DepositStatusMessage {
+	return DepositStatusMessage{
+		Stage:      stage,
+		EventID:    event.ID,
+		DepositID:  event.DepositID,
+		BtcTxHash:  event.BtcTxHash,
+		BtcFrom:    deposit.BtcFrom,
+		BtcValue:   deposit.BtcValue.String(),
+		StatusType: event.StatusType,
+		State:      event.ToState,
+		TxHash:     event.TxHash,
+		Error:      event.Error,
+		CreatedAt:  event.CreatedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func approvalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approval",
+		Short: "manage the large deposits wait operator approval",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		approvalListCmd(),
+		reviewCmd("approve", "approve the large deposit", "large deposit", bitcoin.ApproveDeposit),
+		reviewCmd("reject", "reject the large deposit, it will be added to the refund queue", "large deposit", bitcoin.RejectDeposit),
+	)
+	return cmd
+}
+
+func approvalListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "list large deposits wait approval",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt(FlagLimit)
+			if err != nil {
+				return err
+			}
+			deposits, err := bitcoin.PendingApprovalDeposits(db, limit)
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, deposits)
+		},
+	}
+	cmd.Flags().Int(FlagLimit, 100, "max number of deposits")
+	return cmd
+}
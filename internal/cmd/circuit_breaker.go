@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+const FlagName = "name"
+
+func circuitBreakerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "circuit-breaker",
+		Short: "manage the circuit breakers which pause all minting",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		circuitBreakerListCmd(),
+		circuitBreakerResetCmd(),
+	)
+	return cmd
+}
+
+func circuitBreakerListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "list circuit breakers",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			var breakers []model.CircuitBreaker
+			if err := db.Order("id").Find(&breakers).Error; err != nil {
+				return err
+			}
+			return printJSON(cmd, breakers)
+		},
+	}
+}
+
+func circuitBreakerResetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "reset",
+		Short:   "reset the open circuit breaker, minting resumes",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			name, err := cmd.Flags().GetString(FlagName)
+			if err != nil {
+				return err
+			}
+			operator, err := cmd.Flags().GetString(FlagOperator)
+			if err != nil {
+				return err
+			}
+			if err := bitcoin.ResetCircuitBreaker(db, name, operator); err != nil {
+				return err
+			}
+			cmd.Printf("circuit breaker %s reset by %s\n", name, operator)
+			return nil
+		},
+	}
+	cmd.Flags().String(FlagName, bitcoin.CircuitBreakerRateLimit, "circuit breaker name")
+	cmd.Flags().String(FlagOperator, "", "operator name")
+	_ = cmd.MarkFlagRequired(FlagOperator)
+	return cmd
+}
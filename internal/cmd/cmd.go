@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 
 	"github.com/b2network/b2-indexer/internal/server"
 	"github.com/b2network/b2-indexer/pkg/log"
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 )
 
 const (
@@ -35,6 +37,16 @@ func rootCmd() *cobra.Command {
 	}
 
 	rootCmd.AddCommand(startCmd())
+	rootCmd.AddCommand(eoaFallbackCmd())
+	rootCmd.AddCommand(feeLedgerCmd())
+	rootCmd.AddCommand(refundCmd())
+	rootCmd.AddCommand(complianceCmd())
+	rootCmd.AddCommand(approvalCmd())
+	rootCmd.AddCommand(circuitBreakerCmd())
+	rootCmd.AddCommand(depositCmd())
+	rootCmd.AddCommand(epsCmd())
+	rootCmd.AddCommand(webhookCmd())
+	rootCmd.AddCommand(openAPICmd())
 	return rootCmd
 }
 
@@ -70,3 +82,59 @@ func GetServerContextFromCmd(cmd *cobra.Command) *server.Context {
 
 	return server.NewDefaultContext()
 }
+
+// preRunWithConfig load config and db into the command context
+func preRunWithConfig(cmd *cobra.Command, _ []string) error {
+	home, err := cmd.Flags().GetString(FlagHome)
+	if err != nil {
+		return err
+	}
+	return server.InterceptConfigsPreRunHandler(cmd, home)
+}
+
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	cmd.Println(string(out))
+	return nil
+}
+
+// reviewCmd operator review command of the deposit, subject is printed in the result
+func reviewCmd(use string, short string, subject string, review func(*gorm.DB, int64, string, string) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   short,
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			operator, err := cmd.Flags().GetString(FlagOperator)
+			if err != nil {
+				return err
+			}
+			reason, err := cmd.Flags().GetString(FlagReason)
+			if err != nil {
+				return err
+			}
+			if err := review(db, id, operator, reason); err != nil {
+				return err
+			}
+			cmd.Printf("deposit %d %s %s by %s\n", id, subject, use, operator)
+			return nil
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "deposit id")
+	cmd.Flags().String(FlagOperator, "", "operator name, recorded in the audit trail")
+	cmd.Flags().String(FlagReason, "", "review reason")
+	_ = cmd.MarkFlagRequired(FlagID)
+	_ = cmd.MarkFlagRequired(FlagOperator)
+	return cmd
+}
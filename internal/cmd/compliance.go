@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func complianceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compliance",
+		Short: "manage the deposits held by compliance screening",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		complianceListCmd(),
+		complianceReleaseCmd(),
+	)
+	return cmd
+}
+
+func complianceListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "list deposits in compliance hold",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt(FlagLimit)
+			if err != nil {
+				return err
+			}
+			var deposits []model.Deposit
+			err = db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().B2TxStatus), model.DepositB2TxStatusComplianceHold).
+				Order("id").
+				Limit(limit).
+				Find(&deposits).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, deposits)
+		},
+	}
+	cmd.Flags().Int(FlagLimit, 100, "max number of deposits")
+	return cmd
+}
+
+func complianceReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "release",
+		Short:   "release the deposit in compliance hold, it will be bridged without screening",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			operator, err := cmd.Flags().GetString(FlagOperator)
+			if err != nil {
+				return err
+			}
+			reason, err := cmd.Flags().GetString(FlagReason)
+			if err != nil {
+				return err
+			}
+			if err := bitcoin.ReleaseComplianceHold(db, id, operator, reason); err != nil {
+				return err
+			}
+			cmd.Printf("deposit %d compliance hold released by %s\n", id, operator)
+			return nil
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "deposit id")
+	cmd.Flags().String(FlagOperator, "", "operator name")
+	cmd.Flags().String(FlagReason, "", "release reason")
+	_ = cmd.MarkFlagRequired(FlagID)
+	_ = cmd.MarkFlagRequired(FlagOperator)
+	return cmd
+}
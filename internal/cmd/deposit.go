@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func depositCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit",
+		Short: "query deposits",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		depositEventsCmd(),
+	)
+	return cmd
+}
+
+func depositEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "events",
+		Short:   "show the status transition timeline of the deposit",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			var events []model.DepositEvent
+			err = db.Where(fmt.Sprintf("%s = ?", model.DepositEvent{}.Column().DepositID), id).
+				Order("id").
+				Find(&events).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, events)
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "deposit id")
+	_ = cmd.MarkFlagRequired(FlagID)
+	return cmd
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagID       = "id"
+	FlagOperator = "operator"
+	FlagReason   = "reason"
+	FlagLimit    = "limit"
+)
+
+func eoaFallbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eoa-fallback",
+		Short: "manage the eoa transfer fallback of failed deposits",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		eoaFallbackListCmd(),
+		reviewCmd("approve", "approve the pending eoa transfer", "eoa fallback", bitcoin.ApproveEoaFallback),
+		reviewCmd("reject", "reject the pending eoa transfer", "eoa fallback", bitcoin.RejectEoaFallback),
+	)
+	return cmd
+}
+
+func eoaFallbackListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "list deposits wait eoa transfer approval",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt(FlagLimit)
+			if err != nil {
+				return err
+			}
+			var deposits []model.Deposit
+			err = db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().B2EoaTxStatus), model.DepositB2EoaTxStatusPendingApproval).
+				Order("id").
+				Limit(limit).
+				Find(&deposits).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, deposits)
+		},
+	}
+	cmd.Flags().Int(FlagLimit, 100, "max number of deposits")
+	return cmd
+}
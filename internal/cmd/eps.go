@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func epsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eps",
+		Short: "manage the eps deliveries",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		epsDeadLettersCmd(),
+		epsDeliveriesCmd(),
+		epsRedeliverCmd(),
+	)
+	return cmd
+}
+
+func epsDeadLettersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dead-letters",
+		Short:   "list eps dead letter after max delivery attempts",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt(FlagLimit)
+			if err != nil {
+				return err
+			}
+			var epsList []model.Eps
+			err = db.Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().Status), model.EspStatusDeadLetter).
+				Order("id").
+				Limit(limit).
+				Find(&epsList).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, epsList)
+		},
+	}
+	cmd.Flags().Int(FlagLimit, 100, "max number of eps")
+	return cmd
+}
+
+func epsDeliveriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deliveries",
+		Short:   "show the delivery log of the eps",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			var deliveries []model.EpsDelivery
+			err = db.Where(fmt.Sprintf("%s = ?", model.EpsDelivery{}.Column().EpsID), id).
+				Order("id").
+				Find(&deliveries).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, deliveries)
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "eps id")
+	_ = cmd.MarkFlagRequired(FlagID)
+	return cmd
+}
+
+func epsRedeliverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "redeliver",
+		Short:   "redeliver the dead letter eps",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			if err := bitcoin.RedeliverEps(db, id); err != nil {
+				return err
+			}
+			cmd.Printf("eps %d redeliver\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "eps id")
+	_ = cmd.MarkFlagRequired(FlagID)
+	return cmd
+}
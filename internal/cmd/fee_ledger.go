@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagFrom   = "from"
+	FlagTo     = "to"
+	FlagOutput = "output"
+
+	feeLedgerDateLayout = "2006-01-02"
+)
+
+var feeLedgerHeader = []string{
+	"id",
+	"btc_tx_hash",
+	"btc_block_number",
+	"btc_block_time",
+	"btc_from",
+	"to_address",
+	"gross_value",
+	"bridge_fee",
+	"net_value",
+	"l2_value",
+	"b2_tx_hash",
+	"b2_eoa_tx_hash",
+}
+
+func feeLedgerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "fee-ledger",
+		Short:   "export the bridge fee ledger of bridged deposits as csv",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			from, err := getTimeFlag(cmd, FlagFrom)
+			if err != nil {
+				return err
+			}
+			to, err := getTimeFlag(cmd, FlagTo)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(FlagOutput)
+			if err != nil {
+				return err
+			}
+
+			query := db.Where(fmt.Sprintf("%s = ? OR %s = ?", model.Deposit{}.Column().B2TxStatus, model.Deposit{}.Column().B2EoaTxStatus),
+				model.DepositB2TxStatusSuccess, model.DepositB2EoaTxStatusSuccess)
+			if !from.IsZero() {
+				query = query.Where(fmt.Sprintf("%s >= ?", model.Deposit{}.Column().BtcBlockTime), from)
+			}
+			if !to.IsZero() {
+				query = query.Where(fmt.Sprintf("%s < ?", model.Deposit{}.Column().BtcBlockTime), to)
+			}
+			var deposits []model.Deposit
+			if err := query.Order("id").Find(&deposits).Error; err != nil {
+				return err
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return writeFeeLedger(w, deposits)
+		},
+	}
+	cmd.Flags().String(FlagHome, "", "The application home directory")
+	cmd.Flags().String(FlagFrom, "", "start of btc block time, inclusive, format 2006-01-02 or RFC3339")
+	cmd.Flags().String(FlagTo, "", "end of btc block time, exclusive, format 2006-01-02 or RFC3339")
+	cmd.Flags().String(FlagOutput, "", "output csv file, stdout if empty")
+	return cmd
+}
+
+func writeFeeLedger(w io.Writer, deposits []model.Deposit) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(feeLedgerHeader); err != nil {
+		return err
+	}
+	for _, deposit := range deposits {
+		err := cw.Write([]string{
+			fmt.Sprint(deposit.ID),
+			deposit.BtcTxHash,
+			fmt.Sprint(deposit.BtcBlockNumber),
+			deposit.BtcBlockTime.UTC().Format(time.RFC3339),
+			deposit.BtcFrom,
+			deposit.BtcFromAAAddress,
+			deposit.BtcValue.String(),
+			deposit.BridgeFee.String(),
+			deposit.NetValue.String(),
+			deposit.L2Value.String(),
+			deposit.B2TxHash,
+			deposit.B2EoaTxHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func getTimeFlag(cmd *cobra.Command, name string) (time.Time, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+	if t, err := time.Parse(feeLedgerDateLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s: %s", name, value)
+	}
+	return t, nil
+}
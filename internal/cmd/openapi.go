@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func openAPICmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "openapi",
+		Short: "print the OpenAPI spec of the http api, docs/openapi.json is generated by it",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out, err := json.MarshalIndent(api.OpenAPISpec(), "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return err
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func refundCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refund",
+		Short: "manage the refund queue of rejected deposits",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		refundListCmd(),
+		reviewCmd("approve", "approve the refund, failed refund can be approved again", "refund", bitcoin.ApproveRefund),
+		reviewCmd("reject", "reject the refund", "refund", bitcoin.RejectRefund),
+	)
+	return cmd
+}
+
+func refundListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "list refunds wait approval or failed",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt(FlagLimit)
+			if err != nil {
+				return err
+			}
+			var refunds []model.Refund
+			err = db.Where(fmt.Sprintf("%s IN (?)", model.Refund{}.Column().Status),
+				[]int{model.RefundStatusPendingApproval, model.RefundStatusFailed}).
+				Order("id").
+				Limit(limit).
+				Find(&refunds).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, refunds)
+		},
+	}
+	cmd.Flags().Int(FlagLimit, 100, "max number of refunds")
+	return cmd
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func webhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "manage the deposit webhook deliveries",
+	}
+	cmd.PersistentFlags().String(FlagHome, "", "The application home directory")
+	cmd.AddCommand(
+		webhookDeliveriesCmd(),
+		webhookDeadLettersCmd(),
+		webhookRedeliverCmd(),
+	)
+	return cmd
+}
+
+func webhookDeliveriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deliveries",
+		Short:   "show the webhook delivery history of the deposit",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			var deliveries []model.WebhookDelivery
+			err = db.Where(fmt.Sprintf("%s = ?", model.WebhookDelivery{}.Column().DepositID), id).
+				Order("id").
+				Find(&deliveries).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, deliveries)
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "deposit id")
+	_ = cmd.MarkFlagRequired(FlagID)
+	return cmd
+}
+
+func webhookDeadLettersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dead-letters",
+		Short:   "list webhook deliveries dead letter after max attempts",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt(FlagLimit)
+			if err != nil {
+				return err
+			}
+			var deliveries []model.WebhookDelivery
+			err = db.Where(fmt.Sprintf("%s = ?", model.WebhookDelivery{}.Column().Status), model.WebhookDeliveryStatusDeadLetter).
+				Order("id").
+				Limit(limit).
+				Find(&deliveries).Error
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, deliveries)
+		},
+	}
+	cmd.Flags().Int(FlagLimit, 100, "max number of deliveries")
+	return cmd
+}
+
+func webhookRedeliverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "redeliver",
+		Short:   "redeliver the dead letter webhook delivery",
+		PreRunE: preRunWithConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db, err := server.GetDBContextFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetInt64(FlagID)
+			if err != nil {
+				return err
+			}
+			if err := bitcoin.RedeliverWebhook(db, id); err != nil {
+				return err
+			}
+			cmd.Printf("webhook delivery %d redeliver\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().Int64(FlagID, 0, "webhook delivery id")
+	_ = cmd.MarkFlagRequired(FlagID)
+	return cmd
+}
@@ -22,6 +22,29 @@ type Config struct {
 	DatabaseMaxIdleConns    int    `mapstructure:"database-max-idle-conns"  env:"INDEXER_DATABASE_MAX_IDLE_CONNS" envDefault:"10"`
 	DatabaseMaxOpenConns    int    `mapstructure:"database-max-open-conns" env:"INDEXER_DATABASE_MAX_OPEN_CONNS" envDefault:"20"`
 	DatabaseConnMaxLifetime int    `mapstructure:"database-conn-max-lifetime" env:"INDEXER_DATABASE_CONN_MAX_LIFETIME" envDefault:"3600"`
+	// HTTPListenAddress defines the read-only http api listen address, empty means disabled
+	HTTPListenAddress string `mapstructure:"http-listen-address" env:"INDEXER_HTTP_LISTEN_ADDRESS"`
+	// GRPCListenAddress defines the gRPC query api listen address, empty means disabled
+	GRPCListenAddress string `mapstructure:"grpc-listen-address" env:"INDEXER_GRPC_LISTEN_ADDRESS"`
+	// AdminListenAddress defines the operator admin http api listen address, empty means disabled
+	AdminListenAddress string `mapstructure:"admin-listen-address" env:"INDEXER_ADMIN_LISTEN_ADDRESS"`
+	// AdminToken defines the bearer token of the admin http api, required if the admin api is enabled
+	AdminToken string `mapstructure:"admin-token" env:"INDEXER_ADMIN_TOKEN"`
+	// HealthListenAddress defines the liveness and readiness probes listen address, independent of the http api,
+	// empty means the probes are served by the http api only
+	HealthListenAddress string `mapstructure:"health-listen-address" env:"INDEXER_HEALTH_LISTEN_ADDRESS"`
+	// HealthMaxLag defines the max bitcoin blocks the indexer is behind the chain tip while ready
+	HealthMaxLag int64 `mapstructure:"health-max-lag" env:"INDEXER_HEALTH_MAX_LAG" envDefault:"6"`
+	// HealthHeartbeatTimeout defines the seconds since the last service loop heartbeat a service is stuck
+	HealthHeartbeatTimeout int64 `mapstructure:"health-heartbeat-timeout" env:"INDEXER_HEALTH_HEARTBEAT_TIMEOUT" envDefault:"600"`
+	// TraceEnable defines whether to export the opentelemetry deposit traces by OTLP
+	TraceEnable bool `mapstructure:"trace-enable" env:"INDEXER_TRACE_ENABLE"`
+	// TraceEndpoint defines the OTLP gRPC collector endpoint
+	TraceEndpoint string `mapstructure:"trace-endpoint" env:"INDEXER_TRACE_ENDPOINT" envDefault:"127.0.0.1:4317"`
+	// TraceInsecure defines whether to connect the collector without tls
+	TraceInsecure bool `mapstructure:"trace-insecure" env:"INDEXER_TRACE_INSECURE" envDefault:"true"`
+	// TraceSampleRatio defines the ratio of the traces sampled, none sampled if <= 0, all sampled if >= 1
+	TraceSampleRatio float64 `mapstructure:"trace-sample-ratio" env:"INDEXER_TRACE_SAMPLE_RATIO" envDefault:"1"`
 }
 
 // BitconConfig defines the bitcoin config
@@ -44,6 +67,15 @@ type BitconConfig struct {
 	EnableIndexer bool `mapstructure:"enable-indexer" env:"BITCOIN_ENABLE_INDEXER"`
 	// IndexerListenAddress defines the address to listen on
 	IndexerListenAddress string `mapstructure:"indexer-listen-address" env:"BITCOIN_INDEXER_LISTEN_ADDRESS"`
+	// IndexerMinDeposit defines the min deposit amount(sats), smaller deposits are rejected
+	IndexerMinDeposit int64 `mapstructure:"indexer-min-deposit" env:"BITCOIN_INDEXER_MIN_DEPOSIT"`
+	// IndexerBlacklist defines the bitcoin from addresses whose deposits are rejected
+	IndexerBlacklist []string `mapstructure:"indexer-blacklist" env:"BITCOIN_INDEXER_BLACKLIST"`
+	// IndexerScriptTypes defines the allowed from address script types, empty means all allowed,
+	// eg. pubkeyhash, scripthash, witness_v0_keyhash, witness_v0_scripthash, witness_v1_taproot
+	IndexerScriptTypes []string `mapstructure:"indexer-script-types" env:"BITCOIN_INDEXER_SCRIPT_TYPES"`
+	// EnableRefund defines whether to enable sending approved refunds by the bitcoin wallet
+	EnableRefund bool `mapstructure:"enable-refund" env:"BITCOIN_ENABLE_REFUND"`
 	// Bridge defines the bridge config
 	Bridge BridgeConfig `mapstructure:"bridge"`
 	// Fee defines the bitcoin tx fee
@@ -51,6 +83,8 @@ type BitconConfig struct {
 	// Evm defines the evm config
 	Evm EvmConfig `mapstructure:"evm"`
 	Eps EpsConfig `mapstructure:"eps"`
+	// Webhook defines the deposit webhook config
+	Webhook WebhookConfig `mapstructure:"webhook"`
 }
 
 type BridgeConfig struct {
@@ -72,6 +106,68 @@ type BridgeConfig struct {
 	AASCARegistry string `mapstructure:"aa-sca-registry" env:"BITCOIN_BRIDGE_AA_SCA_REGISTRY"`
 	// AAKernelFactory defines the  contract AAKernelFactory address
 	AAKernelFactory string `mapstructure:"aa-kernel-factory" env:"BITCOIN_BRIDGE_AA_KERNEL_FACTORY"`
+	// RecipientStrategies defines the l2 recipient resolution strategies in priority order,
+	// supported: aa, static, memo, contract
+	RecipientStrategies []string `mapstructure:"recipient-strategies" env:"BITCOIN_BRIDGE_RECIPIENT_STRATEGIES" envDefault:"aa"`
+	// StaticRecipients defines the static bitcoin address -> l2 address mapping table,
+	// each item format: <bitcoin address>:<l2 address>
+	StaticRecipients []string `mapstructure:"static-recipients" env:"BITCOIN_BRIDGE_STATIC_RECIPIENTS"`
+	// RecipientRegistry defines the recipient registry contract address
+	RecipientRegistry string `mapstructure:"recipient-registry" env:"BITCOIN_BRIDGE_RECIPIENT_REGISTRY"`
+	// AmountDecimals defines the l2 amount decimals of each contract method, item format: <method>:<decimals>,
+	// default depositV2:8, transfer:18
+	AmountDecimals []string `mapstructure:"amount-decimals" env:"BITCOIN_BRIDGE_AMOUNT_DECIMALS"`
+	// AmountRounding defines the amount rounding rule when converting to fewer decimals,
+	// supported: down, up, half-up, exact
+	AmountRounding string `mapstructure:"amount-rounding" env:"BITCOIN_BRIDGE_AMOUNT_ROUNDING" envDefault:"down"`
+	// FeeType defines the bridge fee type deducted from deposits,
+	// supported: none, flat, percentage, tiered
+	FeeType string `mapstructure:"fee-type" env:"BITCOIN_BRIDGE_FEE_TYPE" envDefault:"none"`
+	// FeeFlat defines the flat bridge fee(sats) per deposit
+	FeeFlat int64 `mapstructure:"fee-flat" env:"BITCOIN_BRIDGE_FEE_FLAT"`
+	// FeeRate defines the percentage bridge fee rate in basis points, 1 bps = 0.01%
+	FeeRate int64 `mapstructure:"fee-rate" env:"BITCOIN_BRIDGE_FEE_RATE"`
+	// FeeTiers defines the tiered bridge fee rate by deposit amount, item format: <min amount(sats)>:<rate bps>
+	FeeTiers []string `mapstructure:"fee-tiers" env:"BITCOIN_BRIDGE_FEE_TIERS"`
+	// FeeMin defines the min bridge fee(sats) of percentage and tiered fee
+	FeeMin int64 `mapstructure:"fee-min" env:"BITCOIN_BRIDGE_FEE_MIN"`
+	// FeeMax defines the max bridge fee(sats) of percentage and tiered fee, 0 means unlimited
+	FeeMax int64 `mapstructure:"fee-max" env:"BITCOIN_BRIDGE_FEE_MAX"`
+	// EoaFallbackPolicy defines the eoa transfer fallback policy when the deposit tx receipt failed,
+	// supported: disabled, threshold, manual
+	EoaFallbackPolicy string `mapstructure:"eoa-fallback-policy" env:"BITCOIN_BRIDGE_EOA_FALLBACK_POLICY" envDefault:"disabled"`
+	// EoaFallbackMaxAmount defines the threshold policy max deposit amount(sats), eoa transfer only below it
+	EoaFallbackMaxAmount int64 `mapstructure:"eoa-fallback-max-amount" env:"BITCOIN_BRIDGE_EOA_FALLBACK_MAX_AMOUNT"`
+	// EoaFallbackDailyLimit defines the max eoa transferred net amount(sats) in 24 hours, the transfers wait the window
+	// if exceeded, 0 means unlimited
+	EoaFallbackDailyLimit int64 `mapstructure:"eoa-fallback-daily-limit" env:"BITCOIN_BRIDGE_EOA_FALLBACK_DAILY_LIMIT"`
+	// ScreeningBlocklists defines the block list files, deposits from or to the listed addresses are held,
+	// relative paths are under the config directory
+	ScreeningBlocklists []string `mapstructure:"screening-blocklists" env:"BITCOIN_BRIDGE_SCREENING_BLOCKLISTS"`
+	// ScreeningReloadInterval defines the block list files modification check interval in seconds
+	ScreeningReloadInterval int64 `mapstructure:"screening-reload-interval" env:"BITCOIN_BRIDGE_SCREENING_RELOAD_INTERVAL" envDefault:"60"`
+	// ApprovalThreshold defines the deposit amount(sats) that requires operator approval before bridge, 0 means disabled
+	ApprovalThreshold int64 `mapstructure:"approval-threshold" env:"BITCOIN_BRIDGE_APPROVAL_THRESHOLD"`
+	// RateLimits defines the bridged volume limits, item format: <scope>:<metric>:<window>:<limit>,
+	// scope: global, sender; metric: amount(sats), count; window: go duration, eg. 1h, 24h
+	RateLimits []string `mapstructure:"rate-limits" env:"BITCOIN_BRIDGE_RATE_LIMITS"`
+	// DepositWorkers defines the number of workers handling deposits concurrently, deposits of the same sender are handled in order
+	DepositWorkers int64 `mapstructure:"deposit-workers" env:"BITCOIN_BRIDGE_DEPOSIT_WORKERS" envDefault:"4"`
+	// RetryBackoffs defines the deposit retry exponential backoff by error class, item format: <class>:<base>:<max>,
+	// class: network, screening, insufficient_balance, gas_insufficient, throttled, failed; base, max: go duration
+	RetryBackoffs []string `mapstructure:"retry-backoffs" env:"BITCOIN_BRIDGE_RETRY_BACKOFFS"`
+	// AlertURL defines the webhook url to post alerts, eg. circuit breaker open
+	AlertURL string `mapstructure:"alert-url" env:"BITCOIN_BRIDGE_ALERT_URL"`
+	// BalanceMonitorInterval defines the contract and signer balance check interval in seconds
+	BalanceMonitorInterval int64 `mapstructure:"balance-monitor-interval" env:"BITCOIN_BRIDGE_BALANCE_MONITOR_INTERVAL" envDefault:"60"`
+	// ContractBalanceWarning defines the contract balance(wei) below which alerts are sent, empty means disabled
+	ContractBalanceWarning string `mapstructure:"contract-balance-warning" env:"BITCOIN_BRIDGE_CONTRACT_BALANCE_WARNING"`
+	// ContractBalanceMin defines the contract balance(wei) below which minting is paused, empty means disabled
+	ContractBalanceMin string `mapstructure:"contract-balance-min" env:"BITCOIN_BRIDGE_CONTRACT_BALANCE_MIN"`
+	// SignerBalanceWarning defines the signer balance(wei) below which alerts are sent, empty means disabled
+	SignerBalanceWarning string `mapstructure:"signer-balance-warning" env:"BITCOIN_BRIDGE_SIGNER_BALANCE_WARNING"`
+	// SignerBalanceMin defines the signer balance(wei) below which minting is paused, empty means disabled
+	SignerBalanceMin string `mapstructure:"signer-balance-min" env:"BITCOIN_BRIDGE_SIGNER_BALANCE_MIN"`
 }
 
 type EvmConfig struct {
@@ -87,6 +183,18 @@ type EpsConfig struct {
 	EnableEps     bool   `mapstructure:"enable-eps" env:"ENABLE_EPS"`
 	URL           string `mapstructure:"url" env:"EPS_URL"`
 	Authorization string `mapstructure:"authorization" env:"EPS_AUTHORIZATION"`
+	// MaxAttempts defines the max delivery attempts, the delivery is dead letter after it
+	MaxAttempts int64 `mapstructure:"max-attempts" env:"EPS_MAX_ATTEMPTS" envDefault:"10"`
+}
+
+type WebhookConfig struct {
+	// EnableWebhook defines whether to enable the deposit lifecycle webhook notifications
+	EnableWebhook bool `mapstructure:"enable-webhook" env:"ENABLE_WEBHOOK"`
+	// Endpoints defines the webhook endpoints `<url>|<secret>|<events>`, events separated by `;`,
+	// empty events means all events
+	Endpoints []string `mapstructure:"endpoints" env:"WEBHOOK_ENDPOINTS"`
+	// MaxAttempts defines the max delivery attempts, the delivery is dead letter after it
+	MaxAttempts int64 `mapstructure:"max-attempts" env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"10"`
 }
 
 const (
@@ -105,6 +213,8 @@ func LoadConfig(homePath string) (*Config, error) {
 	v.SetEnvPrefix(AppConfigEnvPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 	v.AutomaticEnv()
+	// the config file omits the ratio, keep sampling all as the env default, 0 samples none
+	v.SetDefault("trace-sample-ratio", 1)
 
 	// try load config from file
 	err := v.ReadInConfig()
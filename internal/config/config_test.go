@@ -28,6 +28,40 @@ func TestBitcoinConfig(t *testing.T) {
 	os.Unsetenv("BITCOIN_BRIDGE_GAS_LIMIT")
 	os.Unsetenv("BITCOIN_BRIDGE_AA_SCA_REGISTRY")
 	os.Unsetenv("BITCOIN_BRIDGE_AA_KERNEL_FACTORY")
+	os.Unsetenv("BITCOIN_BRIDGE_RECIPIENT_STRATEGIES")
+	os.Unsetenv("BITCOIN_BRIDGE_STATIC_RECIPIENTS")
+	os.Unsetenv("BITCOIN_BRIDGE_RECIPIENT_REGISTRY")
+	os.Unsetenv("BITCOIN_BRIDGE_EOA_FALLBACK_POLICY")
+	os.Unsetenv("BITCOIN_BRIDGE_EOA_FALLBACK_MAX_AMOUNT")
+	os.Unsetenv("BITCOIN_BRIDGE_EOA_FALLBACK_DAILY_LIMIT")
+	os.Unsetenv("BITCOIN_BRIDGE_AMOUNT_DECIMALS")
+	os.Unsetenv("BITCOIN_BRIDGE_AMOUNT_ROUNDING")
+	os.Unsetenv("BITCOIN_BRIDGE_FEE_TYPE")
+	os.Unsetenv("BITCOIN_BRIDGE_FEE_FLAT")
+	os.Unsetenv("BITCOIN_BRIDGE_FEE_RATE")
+	os.Unsetenv("BITCOIN_BRIDGE_FEE_TIERS")
+	os.Unsetenv("BITCOIN_BRIDGE_FEE_MIN")
+	os.Unsetenv("BITCOIN_BRIDGE_FEE_MAX")
+	os.Unsetenv("BITCOIN_INDEXER_MIN_DEPOSIT")
+	os.Unsetenv("BITCOIN_INDEXER_BLACKLIST")
+	os.Unsetenv("BITCOIN_INDEXER_SCRIPT_TYPES")
+	os.Unsetenv("BITCOIN_ENABLE_REFUND")
+	os.Unsetenv("BITCOIN_BRIDGE_SCREENING_BLOCKLISTS")
+	os.Unsetenv("BITCOIN_BRIDGE_SCREENING_RELOAD_INTERVAL")
+	os.Unsetenv("BITCOIN_BRIDGE_APPROVAL_THRESHOLD")
+	os.Unsetenv("BITCOIN_BRIDGE_RATE_LIMITS")
+	os.Unsetenv("BITCOIN_BRIDGE_ALERT_URL")
+	os.Unsetenv("BITCOIN_BRIDGE_BALANCE_MONITOR_INTERVAL")
+	os.Unsetenv("BITCOIN_BRIDGE_CONTRACT_BALANCE_WARNING")
+	os.Unsetenv("BITCOIN_BRIDGE_CONTRACT_BALANCE_MIN")
+	os.Unsetenv("BITCOIN_BRIDGE_SIGNER_BALANCE_WARNING")
+	os.Unsetenv("BITCOIN_BRIDGE_SIGNER_BALANCE_MIN")
+	os.Unsetenv("BITCOIN_BRIDGE_RETRY_BACKOFFS")
+	os.Unsetenv("BITCOIN_BRIDGE_DEPOSIT_WORKERS")
+	os.Unsetenv("EPS_MAX_ATTEMPTS")
+	os.Unsetenv("ENABLE_WEBHOOK")
+	os.Unsetenv("WEBHOOK_ENDPOINTS")
+	os.Unsetenv("WEBHOOK_MAX_ATTEMPTS")
 	os.Unsetenv("ENABLE_EPS")
 	os.Unsetenv("EPS_URL")
 	os.Unsetenv("EPS_AUTHORIZATION")
@@ -49,9 +83,43 @@ func TestBitcoinConfig(t *testing.T) {
 	require.Equal(t, uint64(3000), config.Bridge.GasLimit)
 	require.Equal(t, "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DFF3", config.Bridge.AASCARegistry)
 	require.Equal(t, "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DFF4", config.Bridge.AAKernelFactory)
+	require.Equal(t, []string{"static", "memo", "aa"}, config.Bridge.RecipientStrategies)
+	require.Equal(t, "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DFF5", config.Bridge.RecipientRegistry)
+	require.Equal(t, []string{"tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n:0xB457BF68D71a17Fa5030269Fb895e29e6cD2DFF6"}, config.Bridge.StaticRecipients)
+	require.Equal(t, "threshold", config.Bridge.EoaFallbackPolicy)
+	require.Equal(t, int64(100000), config.Bridge.EoaFallbackMaxAmount)
+	require.Equal(t, int64(1000000), config.Bridge.EoaFallbackDailyLimit)
+	require.Equal(t, []string{"depositV2:18"}, config.Bridge.AmountDecimals)
+	require.Equal(t, "exact", config.Bridge.AmountRounding)
+	require.Equal(t, "tiered", config.Bridge.FeeType)
+	require.Equal(t, int64(1000), config.Bridge.FeeFlat)
+	require.Equal(t, int64(30), config.Bridge.FeeRate)
+	require.Equal(t, []string{"0:30", "1000000:10"}, config.Bridge.FeeTiers)
+	require.Equal(t, int64(500), config.Bridge.FeeMin)
+	require.Equal(t, int64(100000), config.Bridge.FeeMax)
+	require.Equal(t, int64(10000), config.IndexerMinDeposit)
+	require.Equal(t, []string{"tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n"}, config.IndexerBlacklist)
+	require.Equal(t, []string{"witness_v0_keyhash", "witness_v1_taproot"}, config.IndexerScriptTypes)
+	require.Equal(t, true, config.EnableRefund)
+	require.Equal(t, []string{"ofac.txt", "/etc/b2-indexer/local.txt"}, config.Bridge.ScreeningBlocklists)
+	require.Equal(t, int64(30), config.Bridge.ScreeningReloadInterval)
+	require.Equal(t, int64(100000000), config.Bridge.ApprovalThreshold)
+	require.Equal(t, []string{"global:amount:24h:1000000000", "sender:count:1h:10"}, config.Bridge.RateLimits)
+	require.Equal(t, "http://127.0.0.1:9093/alert", config.Bridge.AlertURL)
+	require.Equal(t, int64(30), config.Bridge.BalanceMonitorInterval)
+	require.Equal(t, "10000000000000000000", config.Bridge.ContractBalanceWarning)
+	require.Equal(t, "1000000000000000000", config.Bridge.ContractBalanceMin)
+	require.Equal(t, "100000000000000000", config.Bridge.SignerBalanceWarning)
+	require.Equal(t, "10000000000000000", config.Bridge.SignerBalanceMin)
+	require.Equal(t, []string{"network:5s:5m", "failed:30m:12h"}, config.Bridge.RetryBackoffs)
+	require.Equal(t, int64(8), config.Bridge.DepositWorkers)
+	require.Equal(t, int64(5), config.Eps.MaxAttempts)
 	require.Equal(t, true, config.Eps.EnableEps)
 	require.Equal(t, "127.0.0.1", config.Eps.URL)
 	require.Equal(t, "", config.Eps.Authorization)
+	require.Equal(t, true, config.Webhook.EnableWebhook)
+	require.Equal(t, []string{"http://127.0.0.1:9095/hook|secret|deposit.minted;deposit.failed", "https://partner.example.com/b2|partner-secret|"}, config.Webhook.Endpoints)
+	require.Equal(t, int64(6), config.Webhook.MaxAttempts)
 }
 
 func TestBitcoinConfigEnv(t *testing.T) {
@@ -71,12 +139,46 @@ func TestBitcoinConfigEnv(t *testing.T) {
 	os.Setenv("BITCOIN_BRIDGE_GAS_LIMIT", "23333")
 	os.Setenv("BITCOIN_BRIDGE_AA_SCA_REGISTRY", "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF23")
 	os.Setenv("BITCOIN_BRIDGE_AA_KERNEL_FACTORY", "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF24")
+	os.Setenv("BITCOIN_BRIDGE_RECIPIENT_STRATEGIES", "memo,aa")
+	os.Setenv("BITCOIN_BRIDGE_STATIC_RECIPIENTS", "tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz:0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF25")
+	os.Setenv("BITCOIN_BRIDGE_RECIPIENT_REGISTRY", "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF26")
 	os.Setenv("BITCOIN_EVM_ENABLE_LISTENER", "false")
 	os.Setenv("BITCOIN_EVM_DEPOSIT", "0x01bee1bfa4116bd0440a1108ef6cb6a2f6eb9b611d8f53260aec20d39e84ee88")
 	os.Setenv("BITCOIN_EVM_WITHDRAW", "0xda335c6ae73006d1145bdcf9a98bc76d789b653b13fe6200e6fc4c5dd54add85")
+	os.Setenv("BITCOIN_BRIDGE_EOA_FALLBACK_POLICY", "manual")
+	os.Setenv("BITCOIN_BRIDGE_EOA_FALLBACK_MAX_AMOUNT", "200000")
+	os.Setenv("BITCOIN_BRIDGE_EOA_FALLBACK_DAILY_LIMIT", "2000000")
+	os.Setenv("BITCOIN_BRIDGE_AMOUNT_DECIMALS", "depositV2:8,transfer:18")
+	os.Setenv("BITCOIN_BRIDGE_AMOUNT_ROUNDING", "half-up")
+	os.Setenv("BITCOIN_BRIDGE_FEE_TYPE", "percentage")
+	os.Setenv("BITCOIN_BRIDGE_FEE_FLAT", "2000")
+	os.Setenv("BITCOIN_BRIDGE_FEE_RATE", "20")
+	os.Setenv("BITCOIN_BRIDGE_FEE_TIERS", "0:20,100000:15")
+	os.Setenv("BITCOIN_BRIDGE_FEE_MIN", "600")
+	os.Setenv("BITCOIN_BRIDGE_FEE_MAX", "200000")
+	os.Setenv("BITCOIN_INDEXER_MIN_DEPOSIT", "20000")
+	os.Setenv("BITCOIN_INDEXER_BLACKLIST", "tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n,tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv")
+	os.Setenv("BITCOIN_INDEXER_SCRIPT_TYPES", "witness_v1_taproot")
+	os.Setenv("BITCOIN_ENABLE_REFUND", "false")
+	os.Setenv("BITCOIN_BRIDGE_SCREENING_BLOCKLISTS", "sanctions.txt")
+	os.Setenv("BITCOIN_BRIDGE_SCREENING_RELOAD_INTERVAL", "120")
+	os.Setenv("BITCOIN_BRIDGE_APPROVAL_THRESHOLD", "50000000")
+	os.Setenv("BITCOIN_BRIDGE_RATE_LIMITS", "global:amount:1h:100000000,sender:amount:24h:10000000")
+	os.Setenv("BITCOIN_BRIDGE_ALERT_URL", "http://127.0.0.1:9094/alert")
+	os.Setenv("BITCOIN_BRIDGE_BALANCE_MONITOR_INTERVAL", "120")
+	os.Setenv("BITCOIN_BRIDGE_CONTRACT_BALANCE_WARNING", "20000000000000000000")
+	os.Setenv("BITCOIN_BRIDGE_CONTRACT_BALANCE_MIN", "2000000000000000000")
+	os.Setenv("BITCOIN_BRIDGE_SIGNER_BALANCE_WARNING", "200000000000000000")
+	os.Setenv("BITCOIN_BRIDGE_SIGNER_BALANCE_MIN", "20000000000000000")
+	os.Setenv("BITCOIN_BRIDGE_RETRY_BACKOFFS", "insufficient_balance:1m:2h")
+	os.Setenv("BITCOIN_BRIDGE_DEPOSIT_WORKERS", "2")
+	os.Setenv("EPS_MAX_ATTEMPTS", "3")
 	os.Setenv("ENABLE_EPS", "true")
 	os.Setenv("EPS_URL", "127.0.0.1")
 	os.Setenv("EPS_AUTHORIZATION", "")
+	os.Setenv("ENABLE_WEBHOOK", "true")
+	os.Setenv("WEBHOOK_ENDPOINTS", "http://127.0.0.1:9096/hook|secret|deposit.held")
+	os.Setenv("WEBHOOK_MAX_ATTEMPTS", "4")
 
 	config, err := config.LoadBitcoinConfig("./")
 	require.NoError(t, err)
@@ -96,9 +198,43 @@ func TestBitcoinConfigEnv(t *testing.T) {
 	require.Equal(t, uint64(23333), config.Bridge.GasLimit)
 	require.Equal(t, "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF23", config.Bridge.AASCARegistry)
 	require.Equal(t, "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF24", config.Bridge.AAKernelFactory)
+	require.Equal(t, []string{"memo", "aa"}, config.Bridge.RecipientStrategies)
+	require.Equal(t, []string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz:0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF25"}, config.Bridge.StaticRecipients)
+	require.Equal(t, "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DF26", config.Bridge.RecipientRegistry)
+	require.Equal(t, "manual", config.Bridge.EoaFallbackPolicy)
+	require.Equal(t, int64(200000), config.Bridge.EoaFallbackMaxAmount)
+	require.Equal(t, int64(2000000), config.Bridge.EoaFallbackDailyLimit)
+	require.Equal(t, []string{"depositV2:8", "transfer:18"}, config.Bridge.AmountDecimals)
+	require.Equal(t, "half-up", config.Bridge.AmountRounding)
+	require.Equal(t, "percentage", config.Bridge.FeeType)
+	require.Equal(t, int64(2000), config.Bridge.FeeFlat)
+	require.Equal(t, int64(20), config.Bridge.FeeRate)
+	require.Equal(t, []string{"0:20", "100000:15"}, config.Bridge.FeeTiers)
+	require.Equal(t, int64(600), config.Bridge.FeeMin)
+	require.Equal(t, int64(200000), config.Bridge.FeeMax)
+	require.Equal(t, int64(20000), config.IndexerMinDeposit)
+	require.Equal(t, []string{"tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n", "tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv"}, config.IndexerBlacklist)
+	require.Equal(t, []string{"witness_v1_taproot"}, config.IndexerScriptTypes)
+	require.Equal(t, false, config.EnableRefund)
+	require.Equal(t, []string{"sanctions.txt"}, config.Bridge.ScreeningBlocklists)
+	require.Equal(t, int64(120), config.Bridge.ScreeningReloadInterval)
+	require.Equal(t, int64(50000000), config.Bridge.ApprovalThreshold)
+	require.Equal(t, []string{"global:amount:1h:100000000", "sender:amount:24h:10000000"}, config.Bridge.RateLimits)
+	require.Equal(t, "http://127.0.0.1:9094/alert", config.Bridge.AlertURL)
+	require.Equal(t, int64(120), config.Bridge.BalanceMonitorInterval)
+	require.Equal(t, "20000000000000000000", config.Bridge.ContractBalanceWarning)
+	require.Equal(t, "2000000000000000000", config.Bridge.ContractBalanceMin)
+	require.Equal(t, "200000000000000000", config.Bridge.SignerBalanceWarning)
+	require.Equal(t, "20000000000000000", config.Bridge.SignerBalanceMin)
+	require.Equal(t, []string{"insufficient_balance:1m:2h"}, config.Bridge.RetryBackoffs)
+	require.Equal(t, int64(2), config.Bridge.DepositWorkers)
+	require.Equal(t, int64(3), config.Eps.MaxAttempts)
 	require.Equal(t, true, config.Eps.EnableEps)
 	require.Equal(t, "127.0.0.1", config.Eps.URL)
 	require.Equal(t, "", config.Eps.Authorization)
+	require.Equal(t, true, config.Webhook.EnableWebhook)
+	require.Equal(t, []string{"http://127.0.0.1:9096/hook|secret|deposit.held"}, config.Webhook.Endpoints)
+	require.Equal(t, int64(4), config.Webhook.MaxAttempts)
 }
 
 func TestChainParams(t *testing.T) {
@@ -150,6 +286,17 @@ func TestConfig(t *testing.T) {
 	os.Unsetenv("INDEXER_DATABASE_MAX_IDLE_CONNS")
 	os.Unsetenv("INDEXER_DATABASE_MAX_OPEN_CONNS")
 	os.Unsetenv("INDEXER_DATABASE_CONN_MAX_LIFETIME")
+	os.Unsetenv("INDEXER_HTTP_LISTEN_ADDRESS")
+	os.Unsetenv("INDEXER_GRPC_LISTEN_ADDRESS")
+	os.Unsetenv("INDEXER_ADMIN_LISTEN_ADDRESS")
+	os.Unsetenv("INDEXER_ADMIN_TOKEN")
+	os.Unsetenv("INDEXER_HEALTH_LISTEN_ADDRESS")
+	os.Unsetenv("INDEXER_HEALTH_MAX_LAG")
+	os.Unsetenv("INDEXER_HEALTH_HEARTBEAT_TIMEOUT")
+	os.Unsetenv("INDEXER_TRACE_ENABLE")
+	os.Unsetenv("INDEXER_TRACE_ENDPOINT")
+	os.Unsetenv("INDEXER_TRACE_INSECURE")
+	os.Unsetenv("INDEXER_TRACE_SAMPLE_RATIO")
 
 	config, err := config.LoadConfig("./testdata")
 	require.NoError(t, err)
@@ -160,6 +307,28 @@ func TestConfig(t *testing.T) {
 	require.Equal(t, 1, config.DatabaseMaxIdleConns)
 	require.Equal(t, 2, config.DatabaseMaxOpenConns)
 	require.Equal(t, 3600, config.DatabaseConnMaxLifetime)
+	require.Equal(t, "127.0.0.1:8080", config.HTTPListenAddress)
+	require.Equal(t, "127.0.0.1:9090", config.GRPCListenAddress)
+	require.Equal(t, "127.0.0.1:8090", config.AdminListenAddress)
+	require.Equal(t, "admin-token", config.AdminToken)
+	require.Equal(t, "127.0.0.1:8070", config.HealthListenAddress)
+	require.Equal(t, int64(3), config.HealthMaxLag)
+	require.Equal(t, int64(300), config.HealthHeartbeatTimeout)
+	require.Equal(t, true, config.TraceEnable)
+	require.Equal(t, "otel-collector:4317", config.TraceEndpoint)
+	require.Equal(t, false, config.TraceInsecure)
+	require.Equal(t, 0.5, config.TraceSampleRatio)
+}
+
+func TestConfigSampleRatioDefault(t *testing.T) {
+	os.Unsetenv("INDEXER_TRACE_SAMPLE_RATIO")
+	homePath := t.TempDir()
+	require.NoError(t, os.WriteFile(homePath+"/indexer.toml", []byte("trace-enable = true\n"), 0o600))
+
+	config, err := config.LoadConfig(homePath)
+	require.NoError(t, err)
+	require.Equal(t, true, config.TraceEnable)
+	require.Equal(t, float64(1), config.TraceSampleRatio)
 }
 
 func TestConfigEnv(t *testing.T) {
@@ -170,6 +339,17 @@ func TestConfigEnv(t *testing.T) {
 	os.Setenv("INDEXER_DATABASE_MAX_IDLE_CONNS", "12")
 	os.Setenv("INDEXER_DATABASE_MAX_OPEN_CONNS", "22")
 	os.Setenv("INDEXER_DATABASE_CONN_MAX_LIFETIME", "2100")
+	os.Setenv("INDEXER_HTTP_LISTEN_ADDRESS", ":8081")
+	os.Setenv("INDEXER_GRPC_LISTEN_ADDRESS", ":9091")
+	os.Setenv("INDEXER_ADMIN_LISTEN_ADDRESS", ":8091")
+	os.Setenv("INDEXER_ADMIN_TOKEN", "env-admin-token")
+	os.Setenv("INDEXER_HEALTH_LISTEN_ADDRESS", ":8071")
+	os.Setenv("INDEXER_HEALTH_MAX_LAG", "10")
+	os.Setenv("INDEXER_HEALTH_HEARTBEAT_TIMEOUT", "1200")
+	os.Setenv("INDEXER_TRACE_ENABLE", "true")
+	os.Setenv("INDEXER_TRACE_ENDPOINT", "127.0.0.1:4318")
+	os.Setenv("INDEXER_TRACE_INSECURE", "false")
+	os.Setenv("INDEXER_TRACE_SAMPLE_RATIO", "0.1")
 	config, err := config.LoadConfig("./")
 	require.NoError(t, err)
 	require.Equal(t, "/data/test", config.RootDir)
@@ -179,4 +359,15 @@ func TestConfigEnv(t *testing.T) {
 	require.Equal(t, 12, config.DatabaseMaxIdleConns)
 	require.Equal(t, 22, config.DatabaseMaxOpenConns)
 	require.Equal(t, 2100, config.DatabaseConnMaxLifetime)
+	require.Equal(t, ":8081", config.HTTPListenAddress)
+	require.Equal(t, ":9091", config.GRPCListenAddress)
+	require.Equal(t, ":8091", config.AdminListenAddress)
+	require.Equal(t, "env-admin-token", config.AdminToken)
+	require.Equal(t, ":8071", config.HealthListenAddress)
+	require.Equal(t, int64(10), config.HealthMaxLag)
+	require.Equal(t, int64(1200), config.HealthHeartbeatTimeout)
+	require.Equal(t, true, config.TraceEnable)
+	require.Equal(t, "127.0.0.1:4318", config.TraceEndpoint)
+	require.Equal(t, false, config.TraceInsecure)
+	require.Equal(t, 0.1, config.TraceSampleRatio)
 }
@@ -0,0 +1,292 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+
+	CheckTimeout = 5 * time.Second
+)
+
+// check name
+const (
+	CheckDatabase   = "database"
+	CheckBitcoind   = "bitcoind"
+	CheckEvm        = "evm"
+	CheckIndexerLag = "indexer_lag"
+	CheckHeartbeat  = "heartbeat"
+)
+
+var (
+	ErrBitcoindIBD       = errors.New("bitcoind in initial block download")
+	ErrIndexerLag        = errors.New("indexer lag exceeds max lag")
+	ErrHeartbeatMissing  = errors.New("service loop never beat")
+	ErrHeartbeatTimeout  = errors.New("service loop heartbeat timeout")
+	ErrBtcIndexNotLoaded = errors.New("btc index not loaded")
+	ErrCheckTimeout      = errors.New("check timeout")
+)
+
+// BitcoinInfo returns the bitcoin chain info, the indexer bitcoin client
+type BitcoinInfo interface {
+	BlockChainInfo() (*btcjson.GetBlockChainInfoResult, error)
+}
+
+// Report the health report of the probe
+type Report struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+	Checks []Check   `json:"checks"`
+}
+
+// Check the result of a dependency or service check
+type Check struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// Checker checks the dependencies and the service loops for the kubernetes probes.
+// The liveness only checks the service loop heartbeats, a stuck loop needs restart;
+// the readiness also checks the database, bitcoind, evm rpc and the indexer lag.
+type Checker struct {
+	db         *gorm.DB
+	bitcoin    BitcoinInfo
+	ethRPCURL  string
+	maxLag     int64
+	timeout    time.Duration
+	services   []string
+	heartbeats *Heartbeats
+}
+
+// NewChecker new health checker, bitcoind and indexer lag are not checked if bitcoin is nil,
+// evm is not checked if ethRPCURL is empty. services are the expected heartbeat names.
+func NewChecker(
+	db *gorm.DB,
+	bitcoin BitcoinInfo,
+	ethRPCURL string,
+	maxLag int64,
+	heartbeatTimeout time.Duration,
+	services []string,
+	heartbeats *Heartbeats,
+) *Checker {
+	return &Checker{
+		db:         db,
+		bitcoin:    bitcoin,
+		ethRPCURL:  ethRPCURL,
+		maxLag:     maxLag,
+		timeout:    heartbeatTimeout,
+		services:   services,
+		heartbeats: heartbeats,
+	}
+}
+
+// Liveness returns the report of the service loop heartbeats
+func (c *Checker) Liveness(_ context.Context) Report {
+	return newReport(c.heartbeatChecks(time.Now()))
+}
+
+// Readiness returns the report of the dependencies and the service loop heartbeats
+func (c *Checker) Readiness(ctx context.Context) Report {
+	checks := []Check{run(CheckDatabase, func() (map[string]interface{}, error) {
+		return nil, c.pingDB(ctx)
+	})}
+	if c.bitcoin != nil {
+		var info *btcjson.GetBlockChainInfoResult
+		checks = append(checks, run(CheckBitcoind, func() (map[string]interface{}, error) {
+			var err error
+			info, err = c.blockChainInfo(ctx)
+			if err != nil {
+				return nil, err
+			}
+			details := map[string]interface{}{
+				"chain":                  info.Chain,
+				"blocks":                 info.Blocks,
+				"headers":                info.Headers,
+				"initial_block_download": info.InitialBlockDownload,
+			}
+			if info.InitialBlockDownload {
+				return details, ErrBitcoindIBD
+			}
+			return details, nil
+		}))
+		checks = append(checks, run(CheckIndexerLag, func() (map[string]interface{}, error) {
+			return c.indexerLag(ctx, info)
+		}))
+	}
+	if c.ethRPCURL != "" {
+		checks = append(checks, run(CheckEvm, func() (map[string]interface{}, error) {
+			return c.evmChainID(ctx)
+		}))
+	}
+	checks = append(checks, c.heartbeatChecks(time.Now())...)
+	return newReport(checks)
+}
+
+// LivenessHandler serves the liveness report, 503 if failed
+func (c *Checker) LivenessHandler() http.Handler {
+	return reportHandler(c.Liveness)
+}
+
+// ReadinessHandler serves the readiness report, 503 if failed
+func (c *Checker) ReadinessHandler() http.Handler {
+	return reportHandler(c.Readiness)
+}
+
+// Handler serves the liveness probe at /healthz and the readiness probe at /readyz
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", c.LivenessHandler())
+	mux.Handle("/readyz", c.ReadinessHandler())
+	return mux
+}
+
+func reportHandler(report func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := report(r.Context())
+		status := http.StatusOK
+		if resp.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func (c *Checker) pingDB(ctx context.Context) error {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// blockChainInfo gets the bitcoin chain info within the check timeout,
+// the bitcoind rpc client has no context, a hung call is left to finish in background
+func (c *Checker) blockChainInfo(ctx context.Context) (*btcjson.GetBlockChainInfoResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	type result struct {
+		info *btcjson.GetBlockChainInfoResult
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		info, err := c.bitcoin.BlockChainInfo()
+		done <- result{info: info, err: err}
+	}()
+	select {
+	case res := <-done:
+		return res.info, res.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrCheckTimeout, ctx.Err())
+	}
+}
+
+// indexerLag checks the indexed block against the bitcoind blocks, skipped if bitcoind failed
+func (c *Checker) indexerLag(ctx context.Context, info *btcjson.GetBlockChainInfoResult) (map[string]interface{}, error) {
+	if info == nil {
+		return nil, fmt.Errorf("%s check failed", CheckBitcoind)
+	}
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	var indexes []model.BtcIndex
+	if err := c.db.WithContext(ctx).Order("id").Limit(1).Find(&indexes).Error; err != nil {
+		return nil, err
+	}
+	if len(indexes) == 0 {
+		return nil, ErrBtcIndexNotLoaded
+	}
+	lag := int64(info.Blocks) - indexes[0].BtcIndexBlock
+	details := map[string]interface{}{
+		"indexed_block": indexes[0].BtcIndexBlock,
+		"chain_tip":     info.Blocks,
+		"lag":           lag,
+		"max_lag":       c.maxLag,
+	}
+	if lag > c.maxLag {
+		return details, ErrIndexerLag
+	}
+	return details, nil
+}
+
+func (c *Checker) evmChainID(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, c.ethRPCURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"chain_id": chainID.String()}, nil
+}
+
+func (c *Checker) heartbeatChecks(now time.Time) []Check {
+	checks := make([]Check, 0, len(c.services))
+	for _, name := range c.services {
+		check := Check{Name: CheckHeartbeat + ":" + name, Status: StatusOK}
+		last, ok := c.heartbeats.Last(name)
+		switch {
+		case !ok:
+			check.Status, check.Error = StatusFail, ErrHeartbeatMissing.Error()
+		case now.Sub(last) > c.timeout:
+			check.Status, check.Error = StatusFail, ErrHeartbeatTimeout.Error()
+		}
+		if ok {
+			check.Details = map[string]interface{}{
+				"last_beat":   last,
+				"age_seconds": int64(now.Sub(last).Seconds()),
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func run(name string, check func() (map[string]interface{}, error)) Check {
+	start := time.Now()
+	details, err := check()
+	result := Check{
+		Name:       name,
+		Status:     StatusOK,
+		Details:    details,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func newReport(checks []Check) Report {
+	report := Report{Status: StatusOK, Time: time.Now(), Checks: checks}
+	for _, check := range checks {
+		if check.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
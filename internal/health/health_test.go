@@ -0,0 +1,193 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type bitcoinInfo struct {
+	info *btcjson.GetBlockChainInfoResult
+}
+
+func (b bitcoinInfo) BlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	return b.info, nil
+}
+
+// hangingBitcoinInfo blocks until released, as a hung bitcoind rpc
+type hangingBitcoinInfo struct {
+	release chan struct{}
+}
+
+func (b hangingBitcoinInfo) BlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	<-b.release
+	return nil, nil
+}
+
+func TestHeartbeats(t *testing.T) {
+	heartbeats := health.NewHeartbeats()
+	_, ok := heartbeats.Last("indexer")
+	require.False(t, ok)
+
+	heartbeats.Beat("indexer")
+	last, ok := heartbeats.Last("indexer")
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now(), last, time.Second)
+}
+
+func TestLiveness(t *testing.T) {
+	heartbeats := health.NewHeartbeats()
+	heartbeats.Beat("indexer")
+	heartbeats.Beat("bridge")
+
+	checker := health.NewChecker(nil, nil, "", 6, time.Minute, []string{"indexer", "bridge"}, heartbeats)
+	report := checker.Liveness(context.Background())
+	require.Equal(t, health.StatusOK, report.Status)
+	require.Len(t, report.Checks, 2)
+	require.Equal(t, "heartbeat:indexer", report.Checks[0].Name)
+
+	// the loop never beat
+	checker = health.NewChecker(nil, nil, "", 6, time.Minute, []string{"indexer", "eps"}, heartbeats)
+	report = checker.Liveness(context.Background())
+	require.Equal(t, health.StatusFail, report.Status)
+	require.Equal(t, health.StatusOK, report.Checks[0].Status)
+	require.Equal(t, health.StatusFail, report.Checks[1].Status)
+	require.Equal(t, health.ErrHeartbeatMissing.Error(), report.Checks[1].Error)
+
+	// the loop is stuck
+	time.Sleep(10 * time.Millisecond)
+	checker = health.NewChecker(nil, nil, "", 6, time.Millisecond, []string{"indexer"}, heartbeats)
+	report = checker.Liveness(context.Background())
+	require.Equal(t, health.StatusFail, report.Status)
+	require.Equal(t, health.ErrHeartbeatTimeout.Error(), report.Checks[0].Error)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	heartbeats := health.NewHeartbeats()
+	heartbeats.Beat("indexer")
+
+	testCases := []struct {
+		name     string
+		services []string
+		code     int
+		status   string
+	}{
+		{"ok", []string{"indexer"}, http.StatusOK, health.StatusOK},
+		{"no service", nil, http.StatusOK, health.StatusOK},
+		{"fail", []string{"indexer", "eps"}, http.StatusServiceUnavailable, health.StatusFail},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := health.NewChecker(nil, nil, "", 6, time.Minute, tc.services, heartbeats)
+			rec := httptest.NewRecorder()
+			checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			require.Equal(t, tc.code, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var report health.Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			require.Equal(t, tc.status, report.Status)
+			require.Len(t, report.Checks, len(tc.services))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	heartbeats := health.NewHeartbeats()
+	heartbeats.Beat("indexer")
+	checker := health.NewChecker(nil, nil, "", 6, time.Minute, []string{"indexer"}, heartbeats)
+
+	rec := httptest.NewRecorder()
+	checker.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// only the probes are served
+	rec = httptest.NewRecorder()
+	checker.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestReadinessUnavailable(t *testing.T) {
+	// the database is unreachable
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=postgres dbname=postgres sslmode=disable connect_timeout=1"),
+		&gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	heartbeats := health.NewHeartbeats()
+	heartbeats.Beat("indexer")
+	btc := bitcoinInfo{info: &btcjson.GetBlockChainInfoResult{
+		Chain:                "regtest",
+		Blocks:               100,
+		Headers:              200,
+		InitialBlockDownload: true,
+	}}
+	checker := health.NewChecker(db, btc, "", 6, time.Minute, []string{"indexer"}, heartbeats)
+
+	rec := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Equal(t, health.StatusFail, report.Status)
+	checks := make(map[string]health.Check, len(report.Checks))
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	require.Equal(t, health.StatusFail, checks[health.CheckDatabase].Status)
+	require.Equal(t, health.StatusFail, checks[health.CheckBitcoind].Status)
+	require.Equal(t, health.ErrBitcoindIBD.Error(), checks[health.CheckBitcoind].Error)
+	require.Equal(t, true, checks[health.CheckBitcoind].Details["initial_block_download"])
+	require.Equal(t, health.StatusFail, checks[health.CheckIndexerLag].Status)
+	require.Equal(t, health.StatusOK, checks["heartbeat:indexer"].Status)
+	_, ok := checks[health.CheckEvm]
+	require.False(t, ok)
+}
+
+func TestReadinessBitcoindTimeout(t *testing.T) {
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=postgres dbname=postgres sslmode=disable connect_timeout=1"),
+		&gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	btc := hangingBitcoinInfo{release: make(chan struct{})}
+	defer close(btc.release)
+	checker := health.NewChecker(db, btc, "", 6, time.Minute, nil, health.NewHeartbeats())
+
+	start := time.Now()
+	report := checker.Readiness(context.Background())
+	require.Less(t, time.Since(start), 2*health.CheckTimeout)
+	checks := make(map[string]health.Check, len(report.Checks))
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	require.Equal(t, health.StatusFail, checks[health.CheckBitcoind].Status)
+	require.Contains(t, checks[health.CheckBitcoind].Error, health.ErrCheckTimeout.Error())
+	require.Equal(t, health.StatusFail, checks[health.CheckIndexerLag].Status)
+}
+
+func TestWaitBeat(t *testing.T) {
+	heartbeats := health.NewHeartbeats()
+	heartbeats.Beat("eps")
+	since := time.Now()
+
+	// the beat before since is not a start signal
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, heartbeats.WaitBeat(ctx, since, "eps"), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		heartbeats.Beat("eps")
+		heartbeats.Beat("webhook")
+	}()
+	require.NoError(t, heartbeats.WaitBeat(context.Background(), since, "eps", "webhook"))
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// heartbeatPollInterval the interval WaitBeat checks the heartbeats
+const heartbeatPollInterval = 100 * time.Millisecond
+
+// Heartbeats the last loop time of the service loops, a loop beats every round
+type Heartbeats struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// DefaultHeartbeats the heartbeats of the service loops in the process
+var DefaultHeartbeats = NewHeartbeats()
+
+// NewHeartbeats returns the heartbeats
+func NewHeartbeats() *Heartbeats {
+	return &Heartbeats{last: make(map[string]time.Time)}
+}
+
+// Beat records the loop heartbeat now
+func (h *Heartbeats) Beat(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last[name] = time.Now()
+}
+
+// Last returns the last heartbeat of the loop, false if never beat
+func (h *Heartbeats) Last(name string) (time.Time, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	last, ok := h.last[name]
+	return last, ok
+}
+
+// WaitBeat blocks until all the loops beat after since, the first beat of a loop signals it started
+func (h *Heartbeats) WaitBeat(ctx context.Context, since time.Time, names ...string) error {
+	ticker := time.NewTicker(heartbeatPollInterval)
+	defer ticker.Stop()
+	for {
+		var waiting []string
+		for _, name := range names {
+			if last, ok := h.Last(name); !ok || last.Before(since) {
+				waiting = append(waiting, name)
+			}
+		}
+		if len(waiting) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: no heartbeat of %v", ctx.Err(), waiting)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Beat records the loop heartbeat to the default heartbeats
+func Beat(name string) {
+	DefaultHeartbeats.Beat(name)
+}
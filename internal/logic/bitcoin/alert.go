@@ -0,0 +1,54 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/go-resty/resty/v2"
+)
+
+const AlertTimeout = 10 * time.Second
+
+// Alert alert message posted to the alert webhook
+type Alert struct {
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Alerter logs the alert and posts it to the alert webhook if configured
+type Alerter struct {
+	url string
+	log log.Logger
+}
+
+// NewAlerter new alerter
+func NewAlerter(url string, logger log.Logger) *Alerter {
+	return &Alerter{url: url, log: logger}
+}
+
+// Alert send alert, errors are logged only
+func (a *Alerter) Alert(title string, message string) {
+	alert := Alert{Title: title, Message: message, Time: time.Now()}
+	a.log.Errorw("alert", "title", title, "message", message)
+	if a.url == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		a.log.Errorw("alert marshal err", "error", err)
+		return
+	}
+	resp, err := resty.New().SetTimeout(AlertTimeout).R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(string(body)).
+		Post(a.url)
+	if err != nil {
+		a.log.Errorw("alert post err", "error", err)
+		return
+	}
+	if resp.IsError() {
+		a.log.Errorw("alert post res err", "res", resp.Status())
+	}
+}
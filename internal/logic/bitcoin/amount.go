@@ -0,0 +1,136 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// BtcDecimals bitcoin amount decimals, 1 btc = 10^8 sats
+	BtcDecimals = 8
+
+	// contract method of the converted amount
+	AmountMethodDeposit  = "depositV2" // bridge contract deposit
+	AmountMethodTransfer = "transfer"  // eoa native transfer
+
+	// rounding rule when the l2 decimals less than bitcoin decimals
+	AmountRoundingDown   = "down"    // round toward zero, never mint more than received
+	AmountRoundingUp     = "up"      // round away from zero
+	AmountRoundingHalfUp = "half-up" // round half away from zero
+	AmountRoundingExact  = "exact"   // reject amounts that cannot be converted exactly
+)
+
+var (
+	ErrAmountUnknownMethod   = errors.New("unknown amount method")
+	ErrAmountUnknownRounding = errors.New("unknown amount rounding")
+	ErrAmountInexact         = errors.New("amount cannot be converted exactly")
+)
+
+// defaultAmountDecimals the l2 decimals of each contract method
+var defaultAmountDecimals = map[string]int{
+	AmountMethodDeposit:  8,
+	AmountMethodTransfer: 18,
+}
+
+// AmountConverter converts amounts between satoshis and l2 units
+type AmountConverter struct {
+	decimals map[string]int
+	rounding string
+}
+
+// NewAmountConverter new amount converter, decimals item format: <method>:<decimals>
+func NewAmountConverter(decimals []string, rounding string) (*AmountConverter, error) {
+	if rounding == "" {
+		rounding = AmountRoundingDown
+	}
+	switch rounding {
+	case AmountRoundingDown, AmountRoundingUp, AmountRoundingHalfUp, AmountRoundingExact:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrAmountUnknownRounding, rounding)
+	}
+
+	c := &AmountConverter{
+		decimals: make(map[string]int, len(defaultAmountDecimals)),
+		rounding: rounding,
+	}
+	for method, d := range defaultAmountDecimals {
+		c.decimals[method] = d
+	}
+	for _, item := range decimals {
+		method, value, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid amount decimals: %s", item)
+		}
+		d, err := strconv.Atoi(value)
+		if err != nil || d < 0 || d > 77 {
+			return nil, fmt.Errorf("invalid amount decimals: %s", item)
+		}
+		c.decimals[method] = d
+	}
+	return c, nil
+}
+
+// Decimals returns the l2 decimals of the method
+func (c *AmountConverter) Decimals(method string) (int, error) {
+	d, ok := c.decimals[method]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrAmountUnknownMethod, method)
+	}
+	return d, nil
+}
+
+// ToL2 converts satoshis to the l2 units of the method
+func (c *AmountConverter) ToL2(method string, sats *big.Int) (*big.Int, error) {
+	d, err := c.Decimals(method)
+	if err != nil {
+		return nil, err
+	}
+	return c.scale(sats, d-BtcDecimals)
+}
+
+// ToSats converts the l2 units of the method to satoshis
+func (c *AmountConverter) ToSats(method string, amount *big.Int) (*big.Int, error) {
+	d, err := c.Decimals(method)
+	if err != nil {
+		return nil, err
+	}
+	return c.scale(amount, BtcDecimals-d)
+}
+
+// scale multiplies the amount by 10^exp, a negative exp divides with the rounding rule
+func (c *AmountConverter) scale(amount *big.Int, exp int) (*big.Int, error) {
+	if amount == nil {
+		return nil, fmt.Errorf("amount is nil")
+	}
+	if exp >= 0 {
+		return new(big.Int).Mul(amount, pow10(exp)), nil
+	}
+
+	divisor := pow10(-exp)
+	quo, rem := new(big.Int).QuoRem(amount, divisor, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo, nil
+	}
+
+	switch c.rounding {
+	case AmountRoundingExact:
+		return nil, fmt.Errorf("%w: %s with %d decimals", ErrAmountInexact, amount, -exp)
+	case AmountRoundingUp:
+		return quo.Add(quo, big.NewInt(int64(amount.Sign()))), nil
+	case AmountRoundingHalfUp:
+		// |rem| * 2 >= divisor
+		if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(divisor) >= 0 {
+			return quo.Add(quo, big.NewInt(int64(amount.Sign()))), nil
+		}
+		return quo, nil
+	default:
+		return quo, nil
+	}
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,142 @@
+package bitcoin_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAmountConverter(t *testing.T) {
+	converter, err := bitcoin.NewAmountConverter(nil, "")
+	require.NoError(t, err)
+	decimals, err := converter.Decimals(bitcoin.AmountMethodDeposit)
+	require.NoError(t, err)
+	require.Equal(t, 8, decimals)
+	decimals, err = converter.Decimals(bitcoin.AmountMethodTransfer)
+	require.NoError(t, err)
+	require.Equal(t, 18, decimals)
+
+	converter, err = bitcoin.NewAmountConverter([]string{"depositV2:18", "mint:6"}, bitcoin.AmountRoundingUp)
+	require.NoError(t, err)
+	decimals, err = converter.Decimals(bitcoin.AmountMethodDeposit)
+	require.NoError(t, err)
+	require.Equal(t, 18, decimals)
+	decimals, err = converter.Decimals("mint")
+	require.NoError(t, err)
+	require.Equal(t, 6, decimals)
+
+	_, err = converter.Decimals("unknown")
+	require.ErrorIs(t, err, bitcoin.ErrAmountUnknownMethod)
+
+	_, err = bitcoin.NewAmountConverter([]string{"depositV2"}, "")
+	require.Error(t, err)
+	_, err = bitcoin.NewAmountConverter([]string{"depositV2:-1"}, "")
+	require.Error(t, err)
+	_, err = bitcoin.NewAmountConverter(nil, "floor")
+	require.ErrorIs(t, err, bitcoin.ErrAmountUnknownRounding)
+}
+
+func TestAmountConverterToL2(t *testing.T) {
+	testCases := []struct {
+		name     string
+		decimals []string
+		rounding string
+		method   string
+		sats     int64
+		expected string
+		err      error
+	}{
+		{
+			name:     "deposit: same decimals",
+			method:   bitcoin.AmountMethodDeposit,
+			sats:     123456,
+			expected: "123456",
+		},
+		{
+			name:     "transfer: 18 decimals",
+			method:   bitcoin.AmountMethodTransfer,
+			sats:     123456,
+			expected: "1234560000000000",
+		},
+		{
+			name:     "fewer decimals: round down",
+			decimals: []string{"mint:6"},
+			rounding: bitcoin.AmountRoundingDown,
+			method:   "mint",
+			sats:     123456,
+			expected: "1234",
+		},
+		{
+			name:     "fewer decimals: round up",
+			decimals: []string{"mint:6"},
+			rounding: bitcoin.AmountRoundingUp,
+			method:   "mint",
+			sats:     123401,
+			expected: "1235",
+		},
+		{
+			name:     "fewer decimals: half up below half",
+			decimals: []string{"mint:6"},
+			rounding: bitcoin.AmountRoundingHalfUp,
+			method:   "mint",
+			sats:     123449,
+			expected: "1234",
+		},
+		{
+			name:     "fewer decimals: half up at half",
+			decimals: []string{"mint:6"},
+			rounding: bitcoin.AmountRoundingHalfUp,
+			method:   "mint",
+			sats:     123450,
+			expected: "1235",
+		},
+		{
+			name:     "fewer decimals: exact",
+			decimals: []string{"mint:6"},
+			rounding: bitcoin.AmountRoundingExact,
+			method:   "mint",
+			sats:     123400,
+			expected: "1234",
+		},
+		{
+			name:     "fewer decimals: inexact",
+			decimals: []string{"mint:6"},
+			rounding: bitcoin.AmountRoundingExact,
+			method:   "mint",
+			sats:     123401,
+			err:      bitcoin.ErrAmountInexact,
+		},
+		{
+			name:   "unknown method",
+			method: "mint",
+			sats:   1,
+			err:    bitcoin.ErrAmountUnknownMethod,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converter, err := bitcoin.NewAmountConverter(tc.decimals, tc.rounding)
+			require.NoError(t, err)
+			amount, err := converter.ToL2(tc.method, big.NewInt(tc.sats))
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, amount.String())
+		})
+	}
+}
+
+func TestAmountConverterToSats(t *testing.T) {
+	converter, err := bitcoin.NewAmountConverter(nil, "")
+	require.NoError(t, err)
+	amount, ok := new(big.Int).SetString("1234560000000009", 10)
+	require.True(t, ok)
+	sats, err := converter.ToSats(bitcoin.AmountMethodTransfer, amount)
+	require.NoError(t, err)
+	require.Equal(t, "123456", sats.String())
+}
@@ -0,0 +1,70 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDepositNotPendingApproval = errors.New("deposit is not pending approval")
+	ErrOperatorEmpty             = errors.New("operator is empty")
+)
+
+// PendingApprovalDeposits returns the large deposits wait operator approval in id order
+func PendingApprovalDeposits(db *gorm.DB, limit int) ([]model.Deposit, error) {
+	var deposits []model.Deposit
+	err := db.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().B2TxStatus), model.DepositB2TxStatusPendingApproval).
+		Order("id").
+		Limit(limit).
+		Find(&deposits).Error
+	return deposits, err
+}
+
+// ApproveDeposit operator approve the large deposit, the bridge deposit service picks it up again
+func ApproveDeposit(db *gorm.DB, depositID int64, operator string, remark string) error {
+	return reviewDeposit(db, depositID, operator, remark, model.DepositB2TxStatusPending)
+}
+
+// RejectDeposit operator reject the large deposit, it is added to the refund queue
+func RejectDeposit(db *gorm.DB, depositID int64, operator string, remark string) error {
+	return reviewDeposit(db, depositID, operator, remark, model.DepositB2TxStatusApprovalRejected)
+}
+
+func reviewDeposit(db *gorm.DB, depositID int64, operator string, remark string, status int) error {
+	if operator == "" {
+		return ErrOperatorEmpty
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var deposit model.Deposit
+		err := TransitDeposit(tx, depositID, func(d *model.Deposit) error {
+			if d.B2TxStatus != model.DepositB2TxStatusPendingApproval {
+				return ErrDepositNotPendingApproval
+			}
+			deposit = *d
+			return nil
+		}, map[string]interface{}{
+			model.Deposit{}.Column().B2TxStatus:       status,
+			model.Deposit{}.Column().ApprovalOperator: operator,
+			model.Deposit{}.Column().ApprovalRemark:   remark,
+			model.Deposit{}.Column().ApprovalAt:       time.Now(),
+		}, operator, remark)
+		if err != nil {
+			return err
+		}
+		if status != model.DepositB2TxStatusApprovalRejected {
+			return nil
+		}
+		return tx.Create(&model.Refund{
+			DepositID:    deposit.ID,
+			BtcTxHash:    deposit.BtcTxHash,
+			ToAddress:    deposit.BtcFrom,
+			Amount:       deposit.BtcValue,
+			RejectReason: fmt.Sprintf("large deposit rejected by %s: %s", operator, remark),
+			Status:       model.RefundStatusPendingApproval,
+		}).Error
+	})
+}
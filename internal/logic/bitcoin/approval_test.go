@@ -0,0 +1,70 @@
+package bitcoin_test
+
+import (
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLocalApproveDeposit(t *testing.T) {
+	db := localDB(t)
+	deposit := createDeposit(t, db, model.DepositB2TxStatusPendingApproval)
+	other := createDeposit(t, db, model.DepositB2TxStatusPending)
+
+	pending, err := bitcoin.PendingApprovalDeposits(db, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, deposit.ID, pending[0].ID)
+
+	require.ErrorIs(t, bitcoin.ApproveDeposit(db, deposit.ID, "", "ok"), bitcoin.ErrOperatorEmpty)
+	require.NoError(t, bitcoin.ApproveDeposit(db, deposit.ID, "alice", "ok"))
+	var approved model.Deposit
+	require.NoError(t, db.First(&approved, deposit.ID).Error)
+	require.Equal(t, model.DepositB2TxStatusPending, approved.B2TxStatus)
+	require.Equal(t, "alice", approved.ApprovalOperator)
+	require.Equal(t, "ok", approved.ApprovalRemark)
+	require.False(t, approved.ApprovalAt.IsZero())
+
+	// double approval
+	require.ErrorIs(t, bitcoin.ApproveDeposit(db, deposit.ID, "bob", "again"), bitcoin.ErrDepositNotPendingApproval)
+	require.NoError(t, db.First(&approved, deposit.ID).Error)
+	require.Equal(t, "alice", approved.ApprovalOperator)
+
+	// not pending approval
+	require.ErrorIs(t, bitcoin.ApproveDeposit(db, other.ID, "alice", "ok"), bitcoin.ErrDepositNotPendingApproval)
+	require.ErrorIs(t, bitcoin.RejectDeposit(db, other.ID, "alice", "no"), bitcoin.ErrDepositNotPendingApproval)
+	require.ErrorIs(t, bitcoin.ApproveDeposit(db, other.ID+100, "alice", "ok"), gorm.ErrRecordNotFound)
+
+	pending, err = bitcoin.PendingApprovalDeposits(db, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	var events int64
+	require.NoError(t, db.Model(&model.DepositEvent{}).Where("deposit_id = ?", deposit.ID).Count(&events).Error)
+	require.Equal(t, int64(1), events)
+}
+
+func TestLocalRejectDeposit(t *testing.T) {
+	db := localDB(t)
+	deposit := createDeposit(t, db, model.DepositB2TxStatusPendingApproval)
+
+	require.NoError(t, bitcoin.RejectDeposit(db, deposit.ID, "alice", "suspicious"))
+	var rejected model.Deposit
+	require.NoError(t, db.First(&rejected, deposit.ID).Error)
+	require.Equal(t, model.DepositB2TxStatusApprovalRejected, rejected.B2TxStatus)
+
+	var refund model.Refund
+	require.NoError(t, db.Where("deposit_id = ?", deposit.ID).First(&refund).Error)
+	require.Equal(t, deposit.BtcFrom, refund.ToAddress)
+	require.Equal(t, model.RefundStatusPendingApproval, refund.Status)
+
+	// double reject creates no second refund
+	require.ErrorIs(t, bitcoin.RejectDeposit(db, deposit.ID, "bob", "again"), bitcoin.ErrDepositNotPendingApproval)
+	require.ErrorIs(t, bitcoin.ApproveDeposit(db, deposit.ID, "bob", "ok"), bitcoin.ErrDepositNotPendingApproval)
+	var refunds int64
+	require.NoError(t, db.Model(&model.Refund{}).Where("deposit_id = ?", deposit.ID).Count(&refunds).Error)
+	require.Equal(t, int64(1), refunds)
+}
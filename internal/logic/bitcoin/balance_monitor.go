@@ -0,0 +1,203 @@
+package bitcoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/cometbft/cometbft/libs/service"
+	"gorm.io/gorm"
+)
+
+const (
+	BalanceMonitorServiceName = "BitcoinBalanceMonitorService"
+	BalanceMonitorTimeout     = 10 * time.Second
+
+	// circuit breaker opened by the balance monitor, closed automatically when balance restored
+	CircuitBreakerBalance  = "balance"
+	balanceMonitorOperator = "balance-monitor"
+
+	// balance level
+	BalanceLevelOK       = "ok"
+	BalanceLevelWarning  = "warning"
+	BalanceLevelCritical = "critical"
+)
+
+// BalanceThresholds balance thresholds(wei), nil means disabled
+type BalanceThresholds struct {
+	Warning *big.Int
+	Min     *big.Int
+}
+
+// NewBalanceThresholds parse balance thresholds
+func NewBalanceThresholds(warning string, min string) (BalanceThresholds, error) {
+	var t BalanceThresholds
+	var err error
+	if t.Warning, err = parseBalance(warning); err != nil {
+		return t, err
+	}
+	if t.Min, err = parseBalance(min); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+func parseBalance(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("invalid balance threshold %s", s)
+	}
+	return v, nil
+}
+
+// Enabled returns whether any threshold is set
+func (t BalanceThresholds) Enabled() bool {
+	return t.Warning != nil || t.Min != nil
+}
+
+// Level returns the balance level
+func (t BalanceThresholds) Level(balance *big.Int) string {
+	if t.Min != nil && balance.Cmp(t.Min) < 0 {
+		return BalanceLevelCritical
+	}
+	if t.Warning != nil && balance.Cmp(t.Warning) < 0 {
+		return BalanceLevelWarning
+	}
+	return BalanceLevelOK
+}
+
+// BalanceMonitorService monitors the contract and signer balance,
+// pauses minting below the min balance and resumes once restored
+type BalanceMonitorService struct {
+	service.BaseService
+
+	bridge   types.BITCOINBridge
+	contract BalanceThresholds
+	signer   BalanceThresholds
+	interval time.Duration
+	// last level of each account, alert when changed
+	levels  map[string]string
+	alerter *Alerter
+
+	db  *gorm.DB
+	log log.Logger
+}
+
+// NewBalanceMonitorService returns a new service instance, nil if no threshold configured
+func NewBalanceMonitorService(
+	bridge types.BITCOINBridge,
+	bridgeCfg config.BridgeConfig,
+	db *gorm.DB,
+	logger log.Logger,
+) (*BalanceMonitorService, error) {
+	contract, err := NewBalanceThresholds(bridgeCfg.ContractBalanceWarning, bridgeCfg.ContractBalanceMin)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewBalanceThresholds(bridgeCfg.SignerBalanceWarning, bridgeCfg.SignerBalanceMin)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.Enabled() && !signer.Enabled() {
+		return nil, nil
+	}
+	interval := time.Duration(bridgeCfg.BalanceMonitorInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ms := &BalanceMonitorService{
+		bridge:   bridge,
+		contract: contract,
+		signer:   signer,
+		interval: interval,
+		levels:   make(map[string]string),
+		alerter:  NewAlerter(bridgeCfg.AlertURL, logger),
+		db:       db,
+		log:      logger,
+	}
+	ms.BaseService = *service.NewBaseService(nil, BalanceMonitorServiceName, ms)
+	return ms, nil
+}
+
+// OnStart
+func (ms *BalanceMonitorService) OnStart() error {
+	if !ms.db.Migrator().HasTable(&model.CircuitBreaker{}) {
+		err := ms.db.AutoMigrate(&model.CircuitBreaker{})
+		if err != nil {
+			ms.log.Errorw("balance monitor create table", "error", err.Error())
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(ms.interval)
+	defer ticker.Stop()
+	for {
+		health.Beat(BalanceMonitorServiceName)
+		ms.check()
+		select {
+		case <-ms.Quit():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check balances, pause or resume minting
+func (ms *BalanceMonitorService) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), BalanceMonitorTimeout)
+	defer cancel()
+	contractBalance, signerBalance, err := ms.bridge.Balances(ctx)
+	if err != nil {
+		// keep the pause state when balance unknown
+		ms.log.Errorw("balance monitor get balances err", "error", err)
+		return
+	}
+	contractLevel := ms.updateLevel("contract", ms.contract.Level(contractBalance), contractBalance)
+	signerLevel := ms.updateLevel("signer", ms.signer.Level(signerBalance), signerBalance)
+	ms.log.Infow("balance monitor",
+		"contractBalance", contractBalance,
+		"contractLevel", contractLevel,
+		"signerBalance", signerBalance,
+		"signerLevel", signerLevel)
+
+	if contractLevel == BalanceLevelCritical || signerLevel == BalanceLevelCritical {
+		reason := fmt.Sprintf("balance below min, contract %s(%s) signer %s(%s)",
+			contractBalance, contractLevel, signerBalance, signerLevel)
+		if _, err := OpenCircuitBreaker(ms.db, CircuitBreakerBalance, reason); err != nil {
+			ms.log.Errorw("balance monitor open circuit breaker err", "error", err)
+		}
+		return
+	}
+	err = ResetCircuitBreaker(ms.db, CircuitBreakerBalance, balanceMonitorOperator)
+	if err == nil {
+		ms.alerter.Alert("balance restored, minting resumed",
+			fmt.Sprintf("contract %s signer %s", contractBalance, signerBalance))
+	} else if !errors.Is(err, ErrCircuitBreakerNotOpen) {
+		ms.log.Errorw("balance monitor reset circuit breaker err", "error", err)
+	}
+}
+
+// updateLevel records the account balance level, alerts when it becomes warning or critical
+func (ms *BalanceMonitorService) updateLevel(account string, level string, balance *big.Int) string {
+	last := ms.levels[account]
+	ms.levels[account] = level
+	if level == last || level == BalanceLevelOK {
+		return level
+	}
+	title := fmt.Sprintf("%s balance %s", account, level)
+	if level == BalanceLevelCritical {
+		title += ", minting paused"
+	}
+	ms.alerter.Alert(title, fmt.Sprintf("%s balance %s", account, balance))
+	return level
+}
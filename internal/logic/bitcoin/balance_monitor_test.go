@@ -0,0 +1,60 @@
+package bitcoin_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceThresholds(t *testing.T) {
+	thresholds, err := bitcoin.NewBalanceThresholds("2000000000000000000", "1000000000000000000")
+	require.NoError(t, err)
+	require.True(t, thresholds.Enabled())
+
+	ether, _ := new(big.Int).SetString("1000000000000000000", 10)
+	require.Equal(t, bitcoin.BalanceLevelOK, thresholds.Level(new(big.Int).Mul(ether, big.NewInt(2))))
+	require.Equal(t, bitcoin.BalanceLevelWarning, thresholds.Level(ether))
+	require.Equal(t, bitcoin.BalanceLevelCritical, thresholds.Level(new(big.Int).Sub(ether, big.NewInt(1))))
+
+	// only min threshold
+	thresholds, err = bitcoin.NewBalanceThresholds("", "100")
+	require.NoError(t, err)
+	require.Equal(t, bitcoin.BalanceLevelOK, thresholds.Level(big.NewInt(100)))
+	require.Equal(t, bitcoin.BalanceLevelCritical, thresholds.Level(big.NewInt(99)))
+
+	thresholds, err = bitcoin.NewBalanceThresholds("", "")
+	require.NoError(t, err)
+	require.False(t, thresholds.Enabled())
+	require.Equal(t, bitcoin.BalanceLevelOK, thresholds.Level(big.NewInt(0)))
+
+	_, err = bitcoin.NewBalanceThresholds("1e18", "")
+	require.Error(t, err)
+	_, err = bitcoin.NewBalanceThresholds("", "-1")
+	require.Error(t, err)
+}
+
+func TestLocalBalanceMonitorStop(t *testing.T) {
+	db := localDB(t)
+	ms, err := bitcoin.NewBalanceMonitorService(&mockBridge{}, config.BridgeConfig{
+		SignerBalanceWarning: "1",
+	}, db, log.NewNopLogger())
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- ms.Start() }()
+	require.Eventually(t, func() bool {
+		return running("(*BalanceMonitorService).OnStart")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ms.Stop())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("balance monitor not stopped")
+	}
+}
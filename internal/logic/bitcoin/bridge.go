@@ -16,6 +16,8 @@ import (
 
 	b2aa "github.com/b2network/b2-go-aa-utils"
 	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/tracing"
+	btypes "github.com/b2network/b2-indexer/internal/types"
 	"github.com/b2network/b2-indexer/pkg/log"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -25,6 +27,8 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -32,6 +36,8 @@ var (
 	ErrBrdigeDepositContractInsufficientBalance = errors.New("insufficient balance")
 	ErrBridgeWaitMinedStatus                    = errors.New("tx wait mined status failed")
 	ErrBridgeFromGasInsufficient                = errors.New("gas required exceeds allowanc")
+	ErrBridgeNonceTooLow                        = errors.New("nonce too low")
+	ErrBridgeTxAlreadyKnown                     = errors.New("already known")
 )
 
 // Bridge bridge
@@ -47,7 +53,9 @@ type Bridge struct {
 	// AA contract address
 	AASCARegistry   common.Address
 	AAKernelFactory common.Address
-	logger          log.Logger
+	// recipient resolver chain
+	recipientResolver *RecipientResolverChain
+	logger            log.Logger
 }
 type B2ExplorerStatus struct {
 	GasPrices struct {
@@ -69,14 +77,11 @@ func NewBridge(bridgeCfg config.BridgeConfig, abiFileDir string, log log.Logger)
 		return nil, err
 	}
 
-	var ABI string
+	ABI := LoadBridgeABI(bridgeCfg, abiFileDir)
 
-	abi, err := os.ReadFile(path.Join(abiFileDir, bridgeCfg.ABI))
+	recipientResolver, err := NewRecipientResolverChainWithConfig(bridgeCfg, rpcURL.String())
 	if err != nil {
-		// load default abi
-		ABI = config.DefaultDepositAbi
-	} else {
-		ABI = string(abi)
+		return nil, err
 	}
 
 	return &Bridge{
@@ -90,58 +95,124 @@ func NewBridge(bridgeCfg config.BridgeConfig, abiFileDir string, log log.Logger)
 		logger:               log,
 		BaseGasPriceMultiple: bridgeCfg.GasPriceMultiple,
 		B2ExplorerURL:        bridgeCfg.B2ExplorerURL,
+		recipientResolver:    recipientResolver,
 	}, nil
 }
 
-// Deposit to ethereum
-func (b *Bridge) Deposit(hash string, bitcoinAddress string, amount int64) (*types.Transaction, []byte, string, error) {
-	if bitcoinAddress == "" {
-		return nil, nil, "", fmt.Errorf("bitcoin address is empty")
+// LoadBridgeABI returns the bridge contract abi of the config file, the default abi if the file is not readable
+func LoadBridgeABI(bridgeCfg config.BridgeConfig, abiFileDir string) string {
+	abi, err := os.ReadFile(path.Join(abiFileDir, bridgeCfg.ABI))
+	if err != nil {
+		// load default abi
+		return config.DefaultDepositAbi
 	}
+	return string(abi)
+}
 
-	if hash == "" {
-		return nil, nil, "", fmt.Errorf("tx id is empty")
+// ResolveRecipient resolves the l2 recipient address by the configured strategies
+func (b *Bridge) ResolveRecipient(ctx context.Context, req *btypes.RecipientRequest) (string, string, error) {
+	return b.recipientResolver.Resolve(ctx, req)
+}
+
+// Deposit to ethereum, toAddress is the resolved l2 recipient address, amount is in l2 units
+func (b *Bridge) Deposit(hash string, toAddress string, amount *big.Int) (*types.Transaction, []byte, error) {
+	data, err := b.depositData(hash, toAddress, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.logger.Infow("deposit", "txId", hash, "amount", amount.String(), "toAddress", toAddress)
+	tx, err := b.sendTransaction(context.Background(), b.EthPrivKey, b.ContractAddress, data, new(big.Int).SetInt64(0))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	ctx := context.Background()
+	return tx, data, nil
+}
 
-	toAddress, err := b.BitcoinAddressToEthAddress(bitcoinAddress)
+// PrepareDeposit builds the deposit tx without nonce, signed by SignTx, so that the tx can be persisted first
+func (b *Bridge) PrepareDeposit(ctx context.Context, hash string, toAddress string, amount *big.Int) (*btypes.UnsignedTx, error) {
+	data, err := b.depositData(hash, toAddress, amount)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("btc address to eth address err:%w", err)
+		return nil, err
+	}
+	b.logger.Infow("prepare deposit", "txId", hash, "amount", amount.String(), "toAddress", toAddress)
+	return b.prepareTransaction(ctx, b.EthPrivKey, b.ContractAddress, data, new(big.Int).SetInt64(0))
+}
+
+// PrepareTransfer builds the native transfer tx without nonce, toAddress is the resolved l2 recipient address,
+// amount is in l2 native units
+func (b *Bridge) PrepareTransfer(ctx context.Context, toAddress string, amount *big.Int) (*btypes.UnsignedTx, error) {
+	if toAddress == "" {
+		return nil, fmt.Errorf("to address is empty")
+	}
+	return b.prepareTransaction(ctx, b.EthPrivKey, common.HexToAddress(toAddress), nil, amount)
+}
+
+// SignTx signs the prepared tx with the pending nonce of the bridge signer
+func (b *Bridge) SignTx(ctx context.Context, utx *btypes.UnsignedTx) (*types.Transaction, error) {
+	return b.signPrepared(ctx, b.EthPrivKey, utx)
+}
+
+func (b *Bridge) depositData(hash string, toAddress string, amount *big.Int) ([]byte, error) {
+	if toAddress == "" {
+		return nil, fmt.Errorf("to address is empty")
+	}
+
+	if hash == "" {
+		return nil, fmt.Errorf("tx id is empty")
 	}
 
-	data, err := b.ABIPack(b.ABI, "depositV2", common.HexToHash(hash), common.HexToAddress(toAddress), new(big.Int).SetInt64(amount))
+	data, err := b.ABIPack(b.ABI, "depositV2", common.HexToHash(hash), common.HexToAddress(toAddress), amount)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("abi pack err:%w", err)
+		return nil, fmt.Errorf("abi pack err:%w", err)
 	}
-	b.logger.Infow("deposit", "txId", hash, "bitcoinAddress", bitcoinAddress, "amount", amount, "aaAddress", toAddress)
-	tx, err := b.sendTransaction(ctx, b.EthPrivKey, b.ContractAddress, data, new(big.Int).SetInt64(0))
+	return data, nil
+}
+
+// Broadcast sends the signed tx, a tx already in the tx pool is not an error
+func (b *Bridge) Broadcast(ctx context.Context, tx *types.Transaction) error {
+	client, err := ethclient.Dial(b.EthRPCURL)
 	if err != nil {
-		return nil, nil, "", err
+		return err
 	}
+	defer client.Close()
 
-	return tx, data, toAddress, nil
+	err = client.SendTransaction(ctx, tx)
+	if err != nil {
+		if strings.Contains(err.Error(), ErrBridgeTxAlreadyKnown.Error()) {
+			return nil
+		}
+		if strings.Contains(err.Error(), ErrBridgeNonceTooLow.Error()) {
+			return fmt.Errorf("%w: %s", ErrBridgeNonceTooLow, err.Error())
+		}
+		return err
+	}
+	return nil
 }
 
-// Transfer to ethereum
-// TODO: temp handle, future remove
-func (b *Bridge) Transfer(bitcoinAddress string, amount int64) (*types.Transaction, error) {
-	if bitcoinAddress == "" {
-		return nil, fmt.Errorf("bitcoin address is empty")
+// TransactionReceipt returns the receipt of the tx, ethereum.NotFound if the tx is not mined
+func (b *Bridge) TransactionReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
+	client, err := ethclient.Dial(b.EthRPCURL)
+	if err != nil {
+		return nil, err
 	}
+	defer client.Close()
 
-	ctx := context.Background()
+	return client.TransactionReceipt(ctx, common.HexToHash(txHash))
+}
 
-	toAddress, err := b.BitcoinAddressToEthAddress(bitcoinAddress)
-	if err != nil {
-		return nil, fmt.Errorf("btc address to eth address err:%w", err)
+// Transfer to ethereum, toAddress is the resolved l2 recipient address, amount is in l2 native units
+// TODO: temp handle, future remove
+func (b *Bridge) Transfer(ctx context.Context, toAddress string, amount *big.Int) (*types.Transaction, error) {
+	if toAddress == "" {
+		return nil, fmt.Errorf("to address is empty")
 	}
 
 	receipt, err := b.sendTransaction(
 		ctx,
 		b.EthPrivKey,
 		common.HexToAddress(toAddress), nil,
-		new(big.Int).Mul(new(big.Int).SetInt64(amount), new(big.Int).SetInt64(10000000000)),
+		amount,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("eth call err:%w", err)
@@ -153,17 +224,41 @@ func (b *Bridge) Transfer(bitcoinAddress string, amount int64) (*types.Transacti
 func (b *Bridge) sendTransaction(ctx context.Context, fromPriv *ecdsa.PrivateKey,
 	toAddress common.Address, data []byte, value *big.Int,
 ) (*types.Transaction, error) {
-	client, err := ethclient.Dial(b.EthRPCURL)
+	signedTx, err := b.signTransaction(ctx, fromPriv, toAddress, data, value)
 	if err != nil {
 		return nil, err
 	}
 
-	publicKey := fromPriv.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("error casting public key to ECDSA")
+	// send tx
+	err = b.Broadcast(ctx, signedTx)
+	if err != nil {
+		return nil, err
 	}
-	nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(*publicKeyECDSA))
+
+	return signedTx, nil
+}
+
+func (b *Bridge) signTransaction(ctx context.Context, fromPriv *ecdsa.PrivateKey,
+	toAddress common.Address, data []byte, value *big.Int,
+) (*types.Transaction, error) {
+	utx, err := b.prepareTransaction(ctx, fromPriv, toAddress, data, value)
+	if err != nil {
+		return nil, err
+	}
+	return b.signPrepared(ctx, fromPriv, utx)
+}
+
+// prepareTransaction gets the gas price, estimates the gas and gets the chain id, all but the nonce of the tx
+func (b *Bridge) prepareTransaction(ctx context.Context, fromPriv *ecdsa.PrivateKey,
+	toAddress common.Address, data []byte, value *big.Int,
+) (*btypes.UnsignedTx, error) {
+	client, err := ethclient.Dial(b.EthRPCURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	fromAddress, err := signerAddress(fromPriv)
 	if err != nil {
 		return nil, err
 	}
@@ -193,10 +288,9 @@ func (b *Bridge) sendTransaction(ctx context.Context, fromPriv *ecdsa.PrivateKey
 	actualGasPrice := new(big.Int).Set(gasPrice)
 	log.Infof("gas price:%v", new(big.Float).Quo(new(big.Float).SetInt(actualGasPrice), big.NewFloat(1e9)).String())
 	log.Infof("gas price:%v", actualGasPrice.String())
-	log.Infof("nonce:%v", nonce)
-	log.Infof("from address:%v", crypto.PubkeyToAddress(*publicKeyECDSA))
+	log.Infof("from address:%v", fromAddress)
 	callMsg := ethereum.CallMsg{
-		From:     crypto.PubkeyToAddress(*publicKeyECDSA),
+		From:     fromAddress,
 		To:       &toAddress,
 		Value:    value,
 		GasPrice: actualGasPrice,
@@ -206,7 +300,10 @@ func (b *Bridge) sendTransaction(ctx context.Context, fromPriv *ecdsa.PrivateKey
 	}
 
 	// use eth_estimateGas only check deposit err
+	_, span := tracing.Start(ctx, "b2.estimate_gas", trace.WithAttributes(
+		attribute.String("b2.gas_price", actualGasPrice.String())))
 	gas, err := client.EstimateGas(ctx, callMsg)
+	tracing.End(span, err)
 	if err != nil {
 		// Other errors may occur that need to be handled
 		// The estimated gas cannot block the sending of a transaction
@@ -226,38 +323,62 @@ func (b *Bridge) sendTransaction(ctx context.Context, fromPriv *ecdsa.PrivateKey
 		// estimate gas err, return, try again
 		return nil, err
 	}
-	gas *= 2
-	legacyTx := types.LegacyTx{
-		Nonce:    nonce,
-		To:       &toAddress,
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &btypes.UnsignedTx{
+		To:       toAddress,
 		Value:    value,
-		Gas:      gas,
+		Data:     data,
+		Gas:      gas * 2,
 		GasPrice: actualGasPrice,
-	}
+		ChainID:  chainID,
+	}, nil
+}
 
-	if data != nil {
-		legacyTx.Data = data
+// signPrepared signs the prepared tx with the pending nonce of the signer
+func (b *Bridge) signPrepared(ctx context.Context, fromPriv *ecdsa.PrivateKey, utx *btypes.UnsignedTx) (*types.Transaction, error) {
+	client, err := ethclient.Dial(b.EthRPCURL)
+	if err != nil {
+		return nil, err
 	}
+	defer client.Close()
 
-	tx := types.NewTx(&legacyTx)
-
-	chainID, err := client.ChainID(ctx)
+	fromAddress, err := signerAddress(fromPriv)
 	if err != nil {
 		return nil, err
 	}
-	// sign tx
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), fromPriv)
+	nonce, err := client.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
 		return nil, err
 	}
+	log.Infof("nonce:%v", nonce)
+	to := utx.To
+	legacyTx := types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Value:    utx.Value,
+		Gas:      utx.Gas,
+		GasPrice: utx.GasPrice,
+	}
 
-	// send tx
-	err = client.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return nil, err
+	if utx.Data != nil {
+		legacyTx.Data = utx.Data
 	}
 
-	return signedTx, nil
+	// sign tx
+	return types.SignTx(types.NewTx(&legacyTx), types.NewEIP155Signer(utx.ChainID), fromPriv)
+}
+
+// signerAddress returns the address of the private key
+func signerAddress(priv *ecdsa.PrivateKey) (common.Address, error) {
+	publicKeyECDSA, ok := priv.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return common.Address{}, fmt.Errorf("error casting public key to ECDSA")
+	}
+	return crypto.PubkeyToAddress(*publicKeyECDSA), nil
 }
 
 // ABIPack the given method name to conform the ABI. Method call's data
@@ -302,6 +423,25 @@ func (b *Bridge) WaitMined(ctx context.Context, tx *types.Transaction, _ []byte)
 	return receipt, nil
 }
 
+// Balances returns the bridge contract balance and the signer native balance
+func (b *Bridge) Balances(ctx context.Context) (*big.Int, *big.Int, error) {
+	client, err := ethclient.Dial(b.EthRPCURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.Close()
+
+	contractBalance, err := client.BalanceAt(ctx, b.ContractAddress, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	signerBalance, err := client.BalanceAt(ctx, crypto.PubkeyToAddress(b.EthPrivKey.PublicKey), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contractBalance, signerBalance, nil
+}
+
 func (b *Bridge) gasPrices() (*big.Int, error) {
 	client := resty.New()
 	resp, err := client.R().
@@ -2,32 +2,77 @@ package bitcoin
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/big"
+	"sync"
 	"time"
 
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/internal/metrics"
 	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/tracing"
 	"github.com/b2network/b2-indexer/internal/types"
 	"github.com/b2network/b2-indexer/pkg/log"
 	"github.com/cometbft/cometbft/libs/service"
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
 const (
 	BridgeDepositServiceName = "BitcoinBridgeDepositService"
 	BatchDepositWaitTimeout  = 10 * time.Second
-	DepositErrTimeout        = 10 * time.Second
 	BatchDepositLimit        = 100
 	WaitMinedTimeout         = 2 * time.Hour
-	HandleDepositTimeout     = 1 * time.Second
+	ReceiptWatchTimeout      = 5 * time.Second
+	BalanceMetricsInterval   = time.Minute
 	DepositRetry             = 100 // temp fix, Increase retry times
+
+	// BridgeDepositReceiptHeartbeat the heartbeat name of the receipt watch loop,
+	// the eoa transfers are watched by receipt in the eoa worker, not in the receipt loop
+	BridgeDepositReceiptHeartbeat = BridgeDepositServiceName + "/receipt"
+)
+
+var (
+	ErrDepositBroadcast     = errors.New("deposit broadcast failed")
+	ErrEoaTransferThrottled = errors.New("eoa transfer throttled")
 )
 
 // BridgeDepositService l1->l2
 type BridgeDepositService struct {
 	service.BaseService
 
-	bridge types.BITCOINBridge
+	bridge            types.BITCOINBridge
+	amountConverter   *AmountConverter
+	feeSchedule       *BridgeFeeSchedule
+	eoaFallbackPolicy *EoaFallbackPolicy
+	screener          types.AddressScreener
+	approvalThreshold int64
+	rateLimiter       *RateLimiter
+	alerter           *Alerter
+	retryBackoffs     map[string]RetryBackoff
+	// workers handle the claimed deposits concurrently
+	workers int
+	// submitMu serializes rate limit check, nonce allocation and broadcast of the workers and the eoa worker
+	submitMu sync.Mutex
+	// workerID owns the deposit leases, replicas claim different deposits
+	workerID string
+	// watcherID owns the leases of the in-flight deposits watched by receipt
+	watcherID string
+	// eoaWorkerID owns the leases of the deposits wait eoa transfer
+	eoaWorkerID string
+	// wakeup is notified when deposits change, nil means polling only
+	wakeup <-chan struct{}
+	// waitMinedSpans the open wait mined spans by deposit id, started once and ended when the tx is final
+	waitMinedSpans   map[int64]waitMinedSpan
+	waitMinedSpansMu sync.Mutex
 
 	db  *gorm.DB
 	log log.Logger
@@ -36,61 +81,293 @@ type BridgeDepositService struct {
 // NewBridgeDepositService returns a new service instance.
 func NewBridgeDepositService(
 	bridge types.BITCOINBridge,
+	bridgeCfg config.BridgeConfig,
+	screener types.AddressScreener,
+	listener *DepositListener,
 	db *gorm.DB,
 	logger log.Logger,
-) *BridgeDepositService {
-	is := &BridgeDepositService{bridge: bridge, db: db, log: logger}
+) (*BridgeDepositService, error) {
+	amountConverter, err := NewAmountConverter(bridgeCfg.AmountDecimals, bridgeCfg.AmountRounding)
+	if err != nil {
+		return nil, err
+	}
+	feeSchedule, err := NewBridgeFeeSchedule(bridgeCfg)
+	if err != nil {
+		return nil, err
+	}
+	eoaFallbackPolicy, err := NewEoaFallbackPolicy(bridgeCfg)
+	if err != nil {
+		return nil, err
+	}
+	rateLimits, err := ParseRateLimits(bridgeCfg.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+	retryBackoffs, err := ParseRetryBackoffs(bridgeCfg.RetryBackoffs)
+	if err != nil {
+		return nil, err
+	}
+	workers := int(bridgeCfg.DepositWorkers)
+	if workers < 1 {
+		workers = 1
+	}
+	workerID := NewWorkerID()
+	is := &BridgeDepositService{
+		bridge:            bridge,
+		amountConverter:   amountConverter,
+		feeSchedule:       feeSchedule,
+		eoaFallbackPolicy: eoaFallbackPolicy,
+		screener:          screener,
+		approvalThreshold: bridgeCfg.ApprovalThreshold,
+		rateLimiter:       NewRateLimiter(rateLimits, db),
+		alerter:           NewAlerter(bridgeCfg.AlertURL, logger),
+		retryBackoffs:     retryBackoffs,
+		workers:           workers,
+		workerID:          workerID,
+		watcherID:         workerID + "/receipt",
+		eoaWorkerID:       workerID + "/eoa",
+		wakeup:            listener.Subscribe(),
+		waitMinedSpans:    make(map[int64]waitMinedSpan),
+		db:                db,
+		log:               logger,
+	}
 	is.BaseService = *service.NewBaseService(nil, BridgeDepositServiceName, is)
-	return is
+	return is, nil
 }
 
 // OnStart
 func (bis *BridgeDepositService) OnStart() error {
+	if !bis.db.Migrator().HasTable(&model.EoaFallback{}) {
+		err := bis.db.AutoMigrate(&model.EoaFallback{})
+		if err != nil {
+			bis.log.Errorw("bridge deposit create table", "error", err.Error())
+			return err
+		}
+	}
+	if !bis.db.Migrator().HasTable(&model.CircuitBreaker{}) {
+		err := bis.db.AutoMigrate(&model.CircuitBreaker{})
+		if err != nil {
+			bis.log.Errorw("bridge deposit create table", "error", err.Error())
+			return err
+		}
+	}
+
+	backfilled, err := BackfillDepositSubmittedAt(bis.db)
+	if err != nil {
+		bis.log.Errorw("bridge deposit backfill submitted time", "error", err.Error())
+		return err
+	}
+	if backfilled > 0 {
+		bis.log.Infow("bridge deposit backfill submitted time", "deposits", backfilled)
+	}
+
+	// keep the claimed deposits leased while handling, the leases of a crashed worker expire
+	go bis.renewLeases()
+	// the submitted txs are tracked by receipt, including the in-flight deposits before restart
+	go bis.watchReceipts()
+	// the eoa transfers are sent and watched by receipt in their own worker, it never blocks the workers and the receipt watcher
+	go bis.watchEoaFallbacks()
+	go bis.observeBalances()
+
+	// the first heartbeat signals the loop started
+	health.Beat(BridgeDepositServiceName)
 	ticker := time.NewTicker(BatchDepositWaitTimeout)
 	for {
-		<-ticker.C
+		// new deposits wake up immediately, polling is the safety net
+		select {
+		case <-ticker.C:
+		case <-bis.wakeup:
+		case <-bis.Quit():
+			return nil
+		}
 		ticker.Reset(BatchDepositWaitTimeout)
+		health.Beat(BridgeDepositServiceName)
+		if bis.paused() {
+			continue
+		}
 		// Query condition
 		// 1. tx status is pending
 		// 2. contract insufficient balance
 		// 3. invoke contract from account insufficient balance
-		// TODO: 4. max retry,temp handle
-		var deposits []model.Deposit
-		err := bis.db.
-			Where(
-				fmt.Sprintf("%s.%s IN (?)", model.Deposit{}.TableName(), model.Deposit{}.Column().B2TxStatus),
-				[]int{
-					model.DepositB2TxStatusPending,
-					model.DepositB2TxStatusInsufficientBalance,
-					model.DepositB2TxStatusFromAccountGasInsufficient,
-					model.DepositB2TxStatusFailed,
-				},
-			).
-			Limit(BatchDepositLimit).
-			Find(&deposits).Error
+		// 4. failed or throttled
+		// 5. in-flight tx dropped
+		// only the deposits due to retry are handled, the failed deposits back off by error class
+		deposits, err := bis.claimDueDeposits(bis.workerID, []int{
+			model.DepositB2TxStatusPending,
+			model.DepositB2TxStatusInsufficientBalance,
+			model.DepositB2TxStatusFromAccountGasInsufficient,
+			model.DepositB2TxStatusFailed,
+			model.DepositB2TxStatusThrottled,
+			model.DepositB2TxStatusDropped,
+		})
 		if err != nil {
 			bis.log.Errorw("failed find tx from db", "error", err)
 		}
 
-		bis.log.Infow("start handle deposit", "deposit batch num", len(deposits))
-		for _, deposit := range deposits {
-			err := bis.HandleDeposit(deposit)
-			if err != nil {
-				bis.log.Errorw("handle deposit failed", "error", err, "deposit", deposit)
+		bis.log.Infow("start handle deposit", "deposit batch num", len(deposits), "workers", bis.workers)
+		bis.handleBatch(deposits)
+		// the deposits not handled, eg. paused
+		bis.releaseLeases(bis.workerID)
+	}
+}
+
+// handleBatch handles the deposits by the worker pool,
+// the deposits of the same sender are handled by the same worker in order
+func (bis *BridgeDepositService) handleBatch(deposits []model.Deposit) {
+	shards := make([][]model.Deposit, bis.workers)
+	for _, deposit := range deposits {
+		shard := DepositShard(deposit.BtcFrom, bis.workers)
+		shards[shard] = append(shards[shard], deposit)
+	}
+
+	var wg sync.WaitGroup
+	for _, shard := range shards {
+		if len(shard) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(deposits []model.Deposit) {
+			defer wg.Done()
+			for _, deposit := range deposits {
+				// circuit breaker may be opened by the previous deposit
+				if bis.paused() {
+					return
+				}
+				err := bis.HandleDeposit(deposit)
+				if err != nil {
+					bis.log.Errorw("handle deposit failed", "error", err, "deposit", deposit)
+				}
+				bis.releaseLeases(bis.workerID, deposit.ID)
 			}
+		}(shard)
+	}
+	wg.Wait()
+}
 
-			time.Sleep(HandleDepositTimeout)
+// DepositShard returns the worker of the sender
+func DepositShard(sender string, workers int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(sender))
+	return int(h.Sum32() % uint32(workers))
+}
+
+// claimDueDeposits claims the deposits in the statuses and due to retry
+func (bis *BridgeDepositService) claimDueDeposits(owner string, statuses []int) ([]model.Deposit, error) {
+	return ClaimDeposits(bis.db, owner, DepositLeaseTimeout, BatchDepositLimit, func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where(
+				fmt.Sprintf("%s.%s IN (?)", model.Deposit{}.TableName(), model.Deposit{}.Column().B2TxStatus),
+				statuses,
+			).
+			Where(
+				fmt.Sprintf("%s.%s IS NULL OR %s.%s <= ?",
+					model.Deposit{}.TableName(), model.Deposit{}.Column().NextRetryAt,
+					model.Deposit{}.TableName(), model.Deposit{}.Column().NextRetryAt),
+				time.Now(),
+			).
+			Order(fmt.Sprintf("%s NULLS FIRST, id", model.Deposit{}.Column().NextRetryAt))
+	})
+}
+
+// renewLeases renews the deposit leases held by the workers, the receipt watcher and the eoa worker until the service stops
+func (bis *BridgeDepositService) renewLeases() {
+	ticker := time.NewTicker(DepositLeaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-bis.Quit():
+			return
+		}
+		for _, owner := range []string{bis.workerID, bis.watcherID, bis.eoaWorkerID} {
+			if err := RenewDepositLeases(bis.db, owner, DepositLeaseTimeout); err != nil {
+				bis.log.Errorw("renew deposit leases err", "error", err, "worker", owner)
+			}
 		}
 	}
 }
 
-func (bis *BridgeDepositService) HandleDeposit(deposit model.Deposit) error {
+// releaseLeases releases the deposit leases held by the owner, all leases if no deposit id
+func (bis *BridgeDepositService) releaseLeases(owner string, ids ...int64) {
+	if err := ReleaseDepositLeases(bis.db, owner, ids...); err != nil {
+		bis.log.Errorw("release deposit leases err", "error", err, "worker", owner)
+	}
+}
+
+func (bis *BridgeDepositService) HandleDeposit(deposit model.Deposit) (err error) {
+	ctx, span := StartDepositSpan(&deposit, "bridge.handle_deposit")
+	defer func() {
+		span.SetAttributes(attribute.Int(tracing.AttrB2TxStatus, deposit.B2TxStatus))
+		tracing.End(span, err)
+	}()
+	// large deposit wait operator approval, only approved deposit is bridged
+	if bis.requireApproval(deposit) {
+		deposit.B2TxStatus = model.DepositB2TxStatusPendingApproval
+		bis.log.Warnw("large deposit wait operator approval",
+			"threshold", bis.approvalThreshold,
+			"btcTxHash", deposit.BtcTxHash,
+			"amount", deposit.BtcValue)
+		return bis.saveDeposit(deposit, fmt.Sprintf("amount reaches approval threshold %d", bis.approvalThreshold))
+	}
+	// the deposit alone exceeds a rate limit, throttling it would trip the circuit breaker on every retry
+	if limit := bis.oversized(deposit); limit != nil {
+		deposit.B2TxStatus = model.DepositB2TxStatusPendingApproval
+		bis.log.Warnw("oversized deposit wait operator approval",
+			"limit", limit.String(),
+			"btcTxHash", deposit.BtcTxHash,
+			"amount", deposit.BtcValue)
+		return bis.saveDeposit(deposit, fmt.Sprintf("amount exceeds rate limit %s", limit))
+	}
 	// set init status
 	deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusPending
-	// send deposit tx
-	b2Tx, _, aaAddress, err := bis.bridge.Deposit(deposit.BtcTxHash, deposit.BtcFrom, deposit.BtcValue)
+	// the workers prepare concurrently, only the submit is serialized
+	var (
+		errText string
+		b2Tx    *ethtypes.Transaction
+	)
+	toAddress, l2Value, err := bis.prepareDeposit(ctx, &deposit)
+	if err == nil {
+		var throttled bool
+		b2Tx, throttled, err = bis.submitDeposit(ctx, &deposit, toAddress, l2Value)
+		if throttled {
+			return err
+		}
+	}
 	if err != nil {
+		errText = err.Error()
+		span.RecordError(err)
 		switch {
+		case errors.Is(err, ErrComplianceHold):
+			deposit.B2TxStatus = model.DepositB2TxStatusComplianceHold
+			bis.log.Warnw("invoke deposit held by compliance screening, wait operator release",
+				"reason", deposit.ComplianceReason,
+				"btcTxHash", deposit.BtcTxHash,
+				"data", deposit)
+		case errors.Is(err, ErrScreeningUnavailable):
+			deposit.B2TxStatus = model.DepositB2TxStatusPending
+			bis.log.Errorw("invoke deposit compliance screening unavailable",
+				"error", err.Error(),
+				"btcTxHash", deposit.BtcTxHash)
+		case errors.Is(err, ErrDepositBroadcast):
+			// keep in-flight, the persisted tx is rebroadcast by recovery
+			deposit.B2TxStatus = model.DepositB2TxStatusInFlight
+			bis.log.Errorw("invoke deposit broadcast tx err, wait recovery",
+				"error", err.Error(),
+				"b2TxHash", deposit.B2TxHash,
+				"btcTxHash", deposit.BtcTxHash)
+		case errors.Is(err, ErrAmountInexact):
+			// the conversion is deterministic, retry never succeeds
+			deposit.B2TxStatus = model.DepositB2TxStatusAmountInexact
+			bis.log.Errorw("invoke deposit amount convert err, need handle manually",
+				"error", err.Error(),
+				"btcTxHash", deposit.BtcTxHash,
+				"data", deposit)
+		case errors.Is(err, ErrBridgeFeeExceedsAmount):
+			deposit.B2TxStatus = model.DepositB2TxStatusFeeExceedsAmount
+			bis.log.Errorw("invoke deposit amount not enough to pay bridge fee",
+				"error", err.Error(),
+				"btcTxHash", deposit.BtcTxHash,
+				"data", deposit)
 		case errors.Is(err, ErrBrdigeDepositTxHashExist):
 			deposit.B2TxStatus = model.DepositB2TxStatusTxHashExist
 			bis.log.Errorw("invoke deposit send tx hash exist",
@@ -125,93 +402,826 @@ func (bis *BridgeDepositService) HandleDeposit(deposit model.Deposit) error {
 					"btcTxHash", deposit.BtcTxHash,
 					"data", deposit)
 			}
-			// The call may not succeed due to network reasons. sleep wait for a while
-			time.Sleep(DepositErrTimeout)
 		}
 	} else {
-		deposit.B2TxStatus = model.DepositB2TxStatusSuccess
-		deposit.B2TxHash = b2Tx.Hash().String()
-		deposit.BtcFromAAAddress = aaAddress
-		bis.log.Infow("invoke deposit send tx success, wait mined",
+		// the receipt watcher tracks the tx, the worker is free for the next deposit
+		bis.log.Infow("invoke deposit send tx success, wait receipt",
+			"b2TxHash", b2Tx.Hash().String(),
 			"btcTxHash", deposit.BtcTxHash,
-			"data", deposit)
-		// wait tx mined, may be wait long time so set timeout ctx
-		ctx1, cancel1 := context.WithTimeout(context.Background(), WaitMinedTimeout)
-		defer cancel1()
-		b2txReceipt, err := bis.bridge.WaitMined(ctx1, b2Tx, nil)
+			"traceID", tracing.TraceID(ctx))
+	}
+
+	// The call may not succeed due to network reasons. retry after the backoff of the error class
+	bis.scheduleRetry(&deposit, RetryClass(deposit.B2TxStatus, err), errText)
+	err = bis.saveDeposit(deposit, errText)
+	if err != nil {
+		return err
+	}
+	bis.log.Infow("handle deposit success", "btcTxHash", deposit.BtcTxHash, "deposit", deposit,
+		"traceID", tracing.TraceID(ctx))
+	return nil
+}
+
+// throttle throttles the deposit exceeds the rate limit, it waits the window allows
+func (bis *BridgeDepositService) throttle(deposit *model.Deposit) (bool, error) {
+	limit, err := bis.rateLimiter.Check(deposit)
+	if err != nil || limit == nil {
+		return false, err
+	}
+	deposit.B2TxStatus = model.DepositB2TxStatusThrottled
+	deposit.ThrottleReason = limit.String()
+	bis.log.Warnw("deposit throttled by rate limit",
+		"limit", deposit.ThrottleReason,
+		"btcTxHash", deposit.BtcTxHash,
+		"amount", deposit.BtcValue)
+	if limit.Scope == RateLimitScopeGlobal {
+		bis.openCircuitBreaker(CircuitBreakerRateLimit,
+			fmt.Sprintf("%s hit by deposit %s", deposit.ThrottleReason, deposit.BtcTxHash))
+	}
+	bis.scheduleRetry(deposit, RetryClassThrottled, deposit.ThrottleReason)
+	return true, bis.saveDeposit(*deposit, deposit.ThrottleReason)
+}
+
+// watchReceipts tracks the submitted deposit txs until mined
+func (bis *BridgeDepositService) watchReceipts() {
+	ticker := time.NewTicker(ReceiptWatchTimeout)
+	defer ticker.Stop()
+	for {
+		health.Beat(BridgeDepositReceiptHeartbeat)
+		deposits, err := bis.claimDueDeposits(bis.watcherID, []int{model.DepositB2TxStatusInFlight})
 		if err != nil {
-			// try eoa transfer, only b2tx recepit status != 1
-			// NOTE: eoa tx is temp handle, It will be removed in the future
-			switch {
-			case errors.Is(err, ErrBridgeWaitMinedStatus):
-				deposit.B2TxStatus = model.DepositB2TxStatusWaitMinedStatusFailed
-				bis.log.Errorw("invoke deposit wait mined err try again by eoa transfer",
-					"error", err.Error(),
-					"btcTxHash", deposit.BtcTxHash,
-					"b2txReceipt", b2txReceipt,
-					"data", deposit)
-				b2EoaTx, err := bis.bridge.Transfer(deposit.BtcFrom, deposit.BtcValue)
-				if err != nil {
-					deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusFailed
-					bis.log.Errorw("invoke eoa transfer tx unknown err",
-						"error", err.Error(),
-						"btcTxHash", deposit.BtcTxHash,
-						"data", deposit)
-				} else {
-					deposit.B2EoaTxHash = b2EoaTx.Hash().String()
-					// eoa wait mined
-					ctx2, cancel2 := context.WithTimeout(context.Background(), WaitMinedTimeout)
-					defer cancel2()
-					_, err := bis.bridge.WaitMined(ctx2, b2EoaTx, nil)
-					if err != nil {
-						deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusWaitMinedFailed
-						bis.log.Errorw("invoke eoa transfer wait mined err",
-							"error", err.Error(),
-							"btcTxHash", deposit.BtcTxHash,
-							"data", deposit)
-
-						if errors.Is(err, context.DeadlineExceeded) {
-							deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusContextDeadlineExceeded
-							bis.log.Error("invoke eoa transfer wait mined context deadline exceeded")
-						}
-					} else {
-						deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusSuccess
-						bis.log.Infow("invoke eoa transfer success",
-							"btcTxHash", deposit.BtcTxHash,
-							"data", deposit)
-					}
-				}
-			case errors.Is(err, context.DeadlineExceeded):
-				// handle ctx deadline timeout
-				// Indicates that the chain is unavailable at this time
-				// This particular error needs to be recorded and handled manually
-				deposit.B2TxStatus = model.DepositB2TxStatusContextDeadlineExceeded
-				bis.log.Errorw("invoke deposit wait mined context deadline exceeded",
-					"error", err.Error(),
-					"btcTxHash", deposit.BtcTxHash,
-					"data", deposit)
-			default:
-				deposit.B2TxStatus = model.DepositB2TxStatusWaitMinedFailed
-				bis.log.Errorw("invoke deposit wait mined unknown err",
+			bis.log.Errorw("failed find in-flight deposit from db", "error", err)
+		}
+		for _, deposit := range deposits {
+			if err := bis.WatchDeposit(deposit); err != nil {
+				bis.log.Errorw("watch in-flight deposit failed", "error", err, "deposit", deposit)
+			}
+			bis.releaseLeases(bis.watcherID, deposit.ID)
+		}
+		select {
+		case <-ticker.C:
+		case <-bis.Quit():
+			return
+		}
+	}
+}
+
+// WatchDeposit checks the receipt of the in-flight deposit tx, the deposit is updated when mined.
+// The persisted tx is rebroadcast while not mined, so that the deposits in-flight before restart are
+// recovered instead of resent. The deposit is handled again only when the tx is dropped, its nonce is used by another tx.
+func (bis *BridgeDepositService) WatchDeposit(deposit model.Deposit) (err error) {
+	ctx, span := bis.startWaitMined(&deposit, "bridge.wait_mined", deposit.B2TxHash)
+	pending := false
+	defer func() {
+		if pending {
+			return
+		}
+		span.SetAttributes(attribute.Int(tracing.AttrB2TxStatus, deposit.B2TxStatus))
+		tracing.End(span, err)
+		bis.endWaitMined(deposit.ID)
+	}()
+	b2Tx, err := decodeRawTx(deposit.B2RawTx)
+	if err != nil {
+		errText := fmt.Sprintf("decode in-flight raw tx err: %s", err.Error())
+		bis.scheduleRetry(&deposit, RetryClassFailed, errText)
+		return bis.saveDeposit(deposit, errText)
+	}
+
+	var errText string
+	receipt, err := bis.transactionReceipt(ctx, deposit.B2TxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		err = bis.broadcast(ctx, b2Tx)
+		if errors.Is(err, ErrBridgeNonceTooLow) {
+			// the tx may be mined just now, check again before handle the deposit again
+			receipt, err = bis.transactionReceipt(ctx, deposit.B2TxHash)
+			if errors.Is(err, ethereum.NotFound) {
+				err = fmt.Errorf("%w: %s, %s", ErrDepositTxDropped, deposit.B2TxHash, ErrBridgeNonceTooLow)
+				deposit.B2TxStatus = model.DepositB2TxStatusDropped
+				deposit.B2TxHash = ""
+				deposit.B2RawTx = ""
+				bis.log.Warnw("in-flight deposit tx dropped, handle again",
 					"error", err.Error(),
-					"btcTxHash", deposit.BtcTxHash,
-					"data", deposit)
+					"btcTxHash", deposit.BtcTxHash)
 			}
 		}
 	}
+	switch {
+	case err != nil:
+		errText = err.Error()
+	case receipt != nil:
+		observeGasSpent(receipt, b2Tx)
+		bis.depositMined(ctx, &deposit, receipt)
+		if deposit.B2TxStatus != model.DepositB2TxStatusSuccess {
+			errText = ErrBridgeWaitMinedStatus.Error()
+		}
+	case time.Since(depositSubmittedAt(deposit)) > WaitMinedTimeout:
+		// Indicates that the chain is unavailable at this time
+		// This particular error needs to be recorded and handled manually
+		deposit.B2TxStatus = model.DepositB2TxStatusContextDeadlineExceeded
+		errText = fmt.Sprintf("deposit tx not mined in %s", WaitMinedTimeout)
+		bis.log.Errorw("invoke deposit wait mined context deadline exceeded",
+			"b2TxHash", deposit.B2TxHash,
+			"btcTxHash", deposit.BtcTxHash)
+	default:
+		// not mined yet, check again next round, the wait mined span stays open
+		pending = true
+		return nil
+	}
 
-	updateFields := map[string]interface{}{
-		model.Deposit{}.Column().B2TxHash:         deposit.B2TxHash,
-		model.Deposit{}.Column().BtcFromAAAddress: deposit.BtcFromAAAddress,
-		model.Deposit{}.Column().B2TxStatus:       deposit.B2TxStatus,
-		model.Deposit{}.Column().B2TxRetry:        deposit.B2TxRetry,
-		model.Deposit{}.Column().B2EoaTxHash:      deposit.B2EoaTxHash,
-		model.Deposit{}.Column().B2EoaTxStatus:    deposit.B2EoaTxStatus,
+	bis.scheduleRetry(&deposit, RetryClass(deposit.B2TxStatus, err), errText)
+	return bis.saveDeposit(deposit, errText)
+}
+
+// waitMinedSpan the wait mined span of the watched deposit and its context
+type waitMinedSpan struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// startWaitMined returns the open wait mined span of the deposit tx, started on its first watch,
+// the receipt checks and rebroadcasts of every round are recorded as its events.
+// The span is not ended if the watch stops before the tx is final, the next watcher starts another one.
+// The eoa transfer is watched only after the deposit tx is final, a deposit has one open span at most
+func (bis *BridgeDepositService) startWaitMined(deposit *model.Deposit, name string, txHash string) (context.Context, trace.Span) {
+	bis.waitMinedSpansMu.Lock()
+	defer bis.waitMinedSpansMu.Unlock()
+	if open, ok := bis.waitMinedSpans[deposit.ID]; ok {
+		if deposit.TraceParent == "" {
+			deposit.TraceParent = tracing.Inject(open.ctx)
+		}
+		return open.ctx, open.span
 	}
-	err = bis.db.Model(&model.Deposit{}).Where("id = ?", deposit.ID).Updates(updateFields).Error
+	ctx, span := StartDepositSpan(deposit, name)
+	span.SetAttributes(attribute.String(tracing.AttrB2TxHash, txHash))
+	bis.waitMinedSpans[deposit.ID] = waitMinedSpan{ctx: ctx, span: span}
+	return ctx, span
+}
+
+// endWaitMined forgets the wait mined span of the deposit, ended by the caller
+func (bis *BridgeDepositService) endWaitMined(depositID int64) {
+	bis.waitMinedSpansMu.Lock()
+	defer bis.waitMinedSpansMu.Unlock()
+	delete(bis.waitMinedSpans, depositID)
+}
+
+// depositSubmittedAt returns the tx submitted time of the in-flight deposit,
+// the last update time if submitted before it was recorded and not backfilled yet
+func depositSubmittedAt(deposit model.Deposit) time.Time {
+	if deposit.B2TxSubmittedAt.IsZero() {
+		return deposit.UpdatedAt
+	}
+	return deposit.B2TxSubmittedAt
+}
+
+// depositMined updates the deposit by the receipt of the mined in-flight tx
+func (bis *BridgeDepositService) depositMined(ctx context.Context, deposit *model.Deposit, receipt *ethtypes.Receipt) {
+	if receipt.Status == ethtypes.ReceiptStatusSuccessful {
+		deposit.B2TxStatus = model.DepositB2TxStatusSuccess
+		bis.log.Infow("in-flight deposit tx mined", "b2TxHash", deposit.B2TxHash, "btcTxHash", deposit.BtcTxHash,
+			"traceID", tracing.TraceID(ctx))
+		return
+	}
+	deposit.B2TxStatus = model.DepositB2TxStatusWaitMinedStatusFailed
+	bis.log.Errorw("in-flight deposit tx mined status err try eoa fallback",
+		"error", ErrBridgeWaitMinedStatus,
+		"btcTxHash", deposit.BtcTxHash,
+		"b2txReceipt", receipt)
+	bis.eoaFallback(ctx, deposit)
+}
+
+// decodeRawTx decodes the hex encoded signed raw tx
+func decodeRawTx(rawTx string) (*ethtypes.Transaction, error) {
+	data, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(ethtypes.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// scheduleRetry schedules the next handle time by the backoff of the error class,
+// the attempts are reset when the deposit needs no retry
+func (bis *BridgeDepositService) scheduleRetry(deposit *model.Deposit, class string, errText string) {
+	deposit.LastError = errText
+	if class == "" {
+		deposit.RetryAttempt = 0
+		deposit.NextRetryAt = time.Time{}
+		return
+	}
+	deposit.RetryAttempt++
+	delay := bis.retryBackoffs[class].Delay(deposit.RetryAttempt)
+	deposit.NextRetryAt = time.Now().Add(delay)
+	bis.log.Warnw("deposit retry scheduled",
+		"class", class,
+		"attempt", deposit.RetryAttempt,
+		"delay", delay.String(),
+		"btcTxHash", deposit.BtcTxHash)
+}
+
+// paused returns whether minting is paused by an open circuit breaker
+func (bis *BridgeDepositService) paused() bool {
+	breaker, err := OpenedCircuitBreaker(bis.db)
+	if err != nil {
+		// pause when the circuit breaker state is unknown
+		bis.log.Errorw("get circuit breaker err", "error", err)
+		return true
+	}
+	if breaker != nil {
+		bis.log.Warnw("minting paused by circuit breaker, wait operator reset",
+			"name", breaker.Name,
+			"reason", breaker.Reason,
+			"openedAt", breaker.OpenedAt)
+		return true
+	}
+	return false
+}
+
+// openCircuitBreaker pause all minting and alert
+func (bis *BridgeDepositService) openCircuitBreaker(name string, reason string) {
+	opened, err := OpenCircuitBreaker(bis.db, name, reason)
+	if err != nil {
+		bis.log.Errorw("open circuit breaker err", "error", err, "name", name)
+		return
+	}
+	if opened {
+		bis.alerter.Alert(fmt.Sprintf("circuit breaker %s open, minting paused", name), reason)
+	}
+}
+
+// requireApproval returns whether the deposit reaches the approval threshold and not approved yet
+func (bis *BridgeDepositService) requireApproval(deposit model.Deposit) bool {
+	if bis.approvalThreshold <= 0 || deposit.ApprovalOperator != "" {
+		return false
+	}
+	return deposit.BtcValue.BigInt().Cmp(big.NewInt(bis.approvalThreshold)) >= 0
+}
+
+// oversized returns the rate limit the deposit alone exceeds if not approved yet, the approved deposit bypasses it
+func (bis *BridgeDepositService) oversized(deposit model.Deposit) *RateLimit {
+	if deposit.ApprovalOperator != "" {
+		return nil
+	}
+	return bis.rateLimiter.Oversized(&deposit)
+}
+
+// prepareDeposit resolve l2 recipient, screen addresses, deduct bridge fee, convert net amount to l2 units
+func (bis *BridgeDepositService) prepareDeposit(ctx context.Context, deposit *model.Deposit) (string, *big.Int, error) {
+	toAddress, err := bis.resolveRecipient(ctx, deposit)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := bis.screen(deposit); err != nil {
+		return "", nil, err
+	}
+	if err := bis.deductBridgeFee(deposit); err != nil {
+		return "", nil, err
+	}
+	l2Value, err := bis.amountConverter.ToL2(AmountMethodDeposit, deposit.NetValue.BigInt())
+	if err != nil {
+		return "", nil, err
+	}
+	deposit.L2Value = model.NewBigInt(l2Value)
+	return toAddress, l2Value, nil
+}
+
+// submitDeposit prepares the deposit tx, then checks the rate limit, signs the tx with the next nonce and
+// persists it in-flight before broadcast, so that it is recovered instead of resent after restart.
+// Returns true if the deposit is throttled and saved. The workers submit in turn, so that the limits are exact
+// and nonces not reused, the gas estimation rpcs are done before the submit lock
+func (bis *BridgeDepositService) submitDeposit(
+	ctx context.Context,
+	deposit *model.Deposit,
+	toAddress string,
+	l2Value *big.Int,
+) (*ethtypes.Transaction, bool, error) {
+	start := time.Now()
+	prepareCtx, span := tracing.Start(ctx, "b2.prepare_deposit")
+	utx, err := bis.bridge.PrepareDeposit(prepareCtx, deposit.BtcTxHash, toAddress, l2Value)
+	tracing.End(span, err)
+	metrics.ObserveRPC(metrics.RPCB2, "prepare_deposit", start, err)
+	if err != nil {
+		return nil, false, err
+	}
+
+	bis.submitMu.Lock()
+	defer bis.submitMu.Unlock()
+	throttled, err := bis.throttle(deposit)
+	if throttled {
+		return nil, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	b2Tx, err := bis.signTx(ctx, utx)
+	if err != nil {
+		return nil, false, err
+	}
+	metrics.B2GasPrice.Set(metrics.Float(b2Tx.GasPrice()))
+	rawTx, err := b2Tx.MarshalBinary()
+	if err != nil {
+		return nil, false, err
+	}
+	status := deposit.B2TxStatus
+	deposit.B2TxStatus = model.DepositB2TxStatusInFlight
+	deposit.B2TxHash = b2Tx.Hash().String()
+	deposit.B2RawTx = hex.EncodeToString(rawTx)
+	deposit.B2TxSubmittedAt = time.Now()
+	if err := bis.saveDeposit(*deposit, ""); err != nil {
+		// not persisted, the tx is never broadcast
+		deposit.B2TxStatus = status
+		deposit.B2TxHash = ""
+		deposit.B2RawTx = ""
+		return nil, false, err
+	}
+	if err := bis.broadcast(ctx, b2Tx); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", ErrDepositBroadcast, err.Error())
+	}
+	return b2Tx, false, nil
+}
+
+// deductBridgeFee calculates the bridge fee of the deposit, records the fee and net amount
+func (bis *BridgeDepositService) deductBridgeFee(deposit *model.Deposit) error {
+	fee, net, err := bis.feeSchedule.Calculate(deposit.BtcValue.BigInt())
+	if err != nil {
+		return err
+	}
+	deposit.BridgeFee = model.NewBigInt(fee)
+	deposit.NetValue = model.NewBigInt(net)
+	return nil
+}
+
+// resolveRecipient resolves the l2 recipient of the deposit and records the strategy.
+// A recipient resolved by a previous attempt is reused, so that retries always mint to the same address.
+func (bis *BridgeDepositService) resolveRecipient(ctx context.Context, deposit *model.Deposit) (string, error) {
+	if deposit.BtcFromAAAddress != "" && deposit.RecipientStrategy != "" {
+		return deposit.BtcFromAAAddress, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "bridge.resolve_recipient")
+	toAddress, strategy, err := bis.bridge.ResolveRecipient(ctx, &types.RecipientRequest{
+		BtcTxHash: deposit.BtcTxHash,
+		BtcFrom:   deposit.BtcFrom,
+		BtcFroms:  depositFroms(deposit),
+		Memo:      deposit.BtcMemo,
+	})
+	span.SetAttributes(attribute.String("recipient.strategy", strategy))
+	tracing.End(span, err)
+	if err != nil {
+		return "", err
+	}
+	deposit.BtcFromAAAddress = toAddress
+	deposit.RecipientStrategy = strategy
+	bis.log.Infow("resolve deposit recipient",
+		"btcTxHash", deposit.BtcTxHash,
+		"toAddress", toAddress,
+		"strategy", strategy)
+	return toAddress, nil
+}
+
+// screen checks all bitcoin from addresses and the l2 recipient,
+// a deposit released by operator is not screened again
+func (bis *BridgeDepositService) screen(deposit *model.Deposit) error {
+	if bis.screener == nil || deposit.ComplianceReleased {
+		return nil
+	}
+	addresses := append(depositFroms(deposit), deposit.BtcFromAAAddress)
+	reason, err := bis.screener.Screen(addresses)
 	if err != nil {
 		return err
 	}
-	bis.log.Infow("handle deposit success", "btcTxHash", deposit.BtcTxHash, "deposit", deposit)
+	if reason != "" {
+		deposit.ComplianceReason = reason
+		return fmt.Errorf("%w: %s", ErrComplianceHold, reason)
+	}
 	return nil
 }
+
+// depositFroms returns all bitcoin from addresses of the deposit
+func depositFroms(deposit *model.Deposit) []string {
+	var froms []string
+	if err := json.Unmarshal([]byte(deposit.BtcFroms), &froms); err != nil || len(froms) == 0 {
+		froms = []string{deposit.BtcFrom}
+	}
+	return froms
+}
+
+// saveDeposit update deposit handle result, the status transitions are recorded with the error.
+// The deposit lease must be held by the worker, a deposit claimed by another worker is not overwritten
+func (bis *BridgeDepositService) saveDeposit(deposit model.Deposit, errText string) error {
+	updateFields := map[string]interface{}{
+		model.Deposit{}.Column().B2TxHash:           deposit.B2TxHash,
+		model.Deposit{}.Column().BtcFromAAAddress:   deposit.BtcFromAAAddress,
+		model.Deposit{}.Column().B2TxStatus:         deposit.B2TxStatus,
+		model.Deposit{}.Column().B2RawTx:            deposit.B2RawTx,
+		model.Deposit{}.Column().B2TxSubmittedAt:    deposit.B2TxSubmittedAt,
+		model.Deposit{}.Column().B2TxRetry:          deposit.B2TxRetry,
+		model.Deposit{}.Column().B2EoaTxHash:        deposit.B2EoaTxHash,
+		model.Deposit{}.Column().B2EoaTxStatus:      deposit.B2EoaTxStatus,
+		model.Deposit{}.Column().B2EoaRawTx:         deposit.B2EoaRawTx,
+		model.Deposit{}.Column().B2EoaTxSubmittedAt: deposit.B2EoaTxSubmittedAt,
+		model.Deposit{}.Column().RecipientStrategy:  deposit.RecipientStrategy,
+		model.Deposit{}.Column().L2Value:            deposit.L2Value,
+		model.Deposit{}.Column().BridgeFee:          deposit.BridgeFee,
+		model.Deposit{}.Column().NetValue:           deposit.NetValue,
+		model.Deposit{}.Column().ComplianceReason:   deposit.ComplianceReason,
+		model.Deposit{}.Column().ThrottleReason:     deposit.ThrottleReason,
+		model.Deposit{}.Column().RetryAttempt:       deposit.RetryAttempt,
+		model.Deposit{}.Column().NextRetryAt:        deposit.NextRetryAt,
+		model.Deposit{}.Column().LastError:          deposit.LastError,
+		model.Deposit{}.Column().TraceParent:        deposit.TraceParent,
+	}
+	return TransitDeposit(bis.db, deposit.ID, CheckDepositLease(deposit.LeaseOwner), updateFields, DepositActorBridgeDeposit, errText)
+}
+
+// eoaFallback applies the eoa fallback policy to the deposit which receipt status failed
+// NOTE: eoa tx is temp handle, It will be removed in the future
+func (bis *BridgeDepositService) eoaFallback(ctx context.Context, deposit *model.Deposit) {
+	action, reason := bis.eoaFallbackPolicy.Decide(deposit.BtcValue.Int64())
+	// the eoa worker is rate limited, the transfer alone exceeding a limit waits operator approval instead
+	if limit := bis.rateLimiter.Oversized(deposit); action == model.EoaFallbackActionQueued && limit != nil {
+		action, reason = model.EoaFallbackActionPendingApproval, fmt.Sprintf("amount exceeds rate limit %s", limit)
+	}
+	switch action {
+	case model.EoaFallbackActionQueued:
+		// transferred by the eoa worker within the daily limit, the receipt watcher does not wait mined
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusQueued
+		bis.log.Warnw("eoa fallback queued",
+			"btcTxHash", deposit.BtcTxHash,
+			"amount", deposit.BtcValue)
+	case model.EoaFallbackActionPendingApproval:
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusPendingApproval
+		bis.auditEoaFallback(deposit, action, reason)
+		bis.log.Warnw("eoa fallback wait operator approval",
+			"btcTxHash", deposit.BtcTxHash,
+			"amount", deposit.BtcValue)
+	default:
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusPolicySkipped
+		bis.auditEoaFallback(deposit, action, reason)
+		bis.log.Errorw("eoa fallback skipped by policy, need handle manually",
+			"btcTxHash", deposit.BtcTxHash,
+			"policy", bis.eoaFallbackPolicy.Policy,
+			"reason", reason)
+	}
+}
+
+// watchEoaFallbacks eoa transfer the queued and approved deposits and watches the in-flight transfers
+// until the service stops, the in-flight transfers are watched while minting paused
+func (bis *BridgeDepositService) watchEoaFallbacks() {
+	ticker := time.NewTicker(BatchDepositWaitTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-bis.Quit():
+			return
+		}
+		bis.WatchEoaTransfers()
+		if bis.paused() {
+			continue
+		}
+		bis.HandleEoaFallbacks()
+	}
+}
+
+// HandleEoaFallbacks eoa transfer the deposits queued by the fallback policy, approved by operator or dropped
+func (bis *BridgeDepositService) HandleEoaFallbacks() {
+	deposits, err := bis.claimEoaDeposits([]int{
+		model.DepositB2EoaTxStatusQueued,
+		model.DepositB2EoaTxStatusApproved,
+		model.DepositB2EoaTxStatusDropped,
+	})
+	if err != nil {
+		bis.log.Errorw("failed find eoa fallback from db", "error", err)
+		return
+	}
+	defer bis.releaseLeases(bis.eoaWorkerID)
+
+	for _, deposit := range deposits {
+		dailyUsed, err := EoaFallbackDailyUsed(bis.db)
+		if err != nil {
+			bis.log.Errorw("eoa fallback get daily used err", "error", err.Error())
+			return
+		}
+		// the net amount is transferred, the fee is deducted before the daily limit check
+		if err := bis.deductBridgeFee(&deposit); err != nil {
+			bis.log.Errorw("eoa fallback deduct bridge fee err", "error", err.Error(), "btcTxHash", deposit.BtcTxHash)
+			continue
+		}
+		// keep waiting, transfer when the daily limit is available, the smaller deposits may fit the limit
+		if reason, ok := bis.eoaFallbackPolicy.CheckDailyLimit(deposit.NetValue.BigInt(), dailyUsed); !ok {
+			bis.log.Warnw("eoa fallback wait daily limit",
+				"btcTxHash", deposit.BtcTxHash,
+				"reason", reason)
+			continue
+		}
+		ctx, span := StartDepositSpan(&deposit, "bridge.eoa_fallback")
+		bis.eoaTransfer(ctx, &deposit)
+		err = bis.saveDeposit(deposit, "")
+		tracing.End(span, err)
+		if err != nil {
+			bis.log.Errorw("save eoa fallback err", "error", err, "btcTxHash", deposit.BtcTxHash)
+		}
+	}
+}
+
+// WatchEoaTransfers watches the in-flight eoa transfers, including the transfers in-flight before restart
+func (bis *BridgeDepositService) WatchEoaTransfers() {
+	deposits, err := bis.claimEoaDeposits([]int{model.DepositB2EoaTxStatusInFlight})
+	if err != nil {
+		bis.log.Errorw("failed find in-flight eoa transfer from db", "error", err)
+		return
+	}
+	defer bis.releaseLeases(bis.eoaWorkerID)
+
+	for _, deposit := range deposits {
+		if err := bis.WatchEoaTransfer(deposit); err != nil {
+			bis.log.Errorw("watch in-flight eoa transfer failed", "error", err, "btcTxHash", deposit.BtcTxHash)
+		}
+	}
+}
+
+// claimEoaDeposits claims the deposits in the eoa transfer statuses
+func (bis *BridgeDepositService) claimEoaDeposits(statuses []int) ([]model.Deposit, error) {
+	return ClaimDeposits(bis.db, bis.eoaWorkerID, DepositLeaseTimeout, BatchDepositLimit, func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			fmt.Sprintf("%s.%s IN (?)", model.Deposit{}.TableName(), model.Deposit{}.Column().B2EoaTxStatus),
+			statuses,
+		).Order("id")
+	})
+}
+
+// eoaTransfer sends the eoa transfer, the receipt is checked by the next rounds of the eoa worker
+func (bis *BridgeDepositService) eoaTransfer(ctx context.Context, deposit *model.Deposit) {
+	ctx, span := tracing.Start(ctx, "bridge.eoa_transfer")
+	var err error
+	defer func() {
+		span.SetAttributes(attribute.Int("b2.eoa_tx_status", deposit.B2EoaTxStatus))
+		tracing.End(span, err)
+	}()
+	b2EoaTx, err := bis.sendEoaTransfer(ctx, deposit)
+	switch {
+	case errors.Is(err, ErrEoaTransferThrottled):
+		// keep waiting, transfer when the rate limit window allows
+		bis.log.Warnw("eoa transfer throttled by rate limit",
+			"error", err.Error(),
+			"btcTxHash", deposit.BtcTxHash)
+	case errors.Is(err, ErrDepositBroadcast):
+		// keep in-flight, the persisted tx is rebroadcast by the watch
+		bis.log.Errorw("invoke eoa transfer broadcast tx err, wait recovery",
+			"error", err.Error(),
+			"b2EoaTxHash", deposit.B2EoaTxHash,
+			"btcTxHash", deposit.BtcTxHash)
+	case err != nil:
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusFailed
+		bis.auditEoaFallback(deposit, model.EoaFallbackActionFailed, err.Error())
+		bis.log.Errorw("invoke eoa transfer tx unknown err",
+			"error", err.Error(),
+			"btcTxHash", deposit.BtcTxHash,
+			"data", deposit)
+	default:
+		span.SetAttributes(attribute.String("b2.eoa_tx_hash", b2EoaTx.Hash().String()))
+		bis.log.Infow("invoke eoa transfer send tx success, wait receipt",
+			"b2EoaTxHash", deposit.B2EoaTxHash,
+			"btcTxHash", deposit.BtcTxHash)
+	}
+}
+
+// sendEoaTransfer deduct bridge fee, convert net amount to l2 native units, then sign the eoa transfer tx and
+// persist it in-flight before broadcast. A plain transfer has no duplicate check as the deposit contract,
+// the persisted tx is recovered by the receipt instead of transferred again after restart
+func (bis *BridgeDepositService) sendEoaTransfer(ctx context.Context, deposit *model.Deposit) (*ethtypes.Transaction, error) {
+	if err := bis.deductBridgeFee(deposit); err != nil {
+		return nil, err
+	}
+	amount, err := bis.amountConverter.ToL2(AmountMethodTransfer, deposit.NetValue.BigInt())
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	utx, err := bis.bridge.PrepareTransfer(ctx, deposit.BtcFromAAAddress, amount)
+	metrics.ObserveRPC(metrics.RPCB2, "prepare_transfer", start, err)
+	if err != nil {
+		return nil, err
+	}
+	// the eoa transfer is signed by the same account as the deposit tx, and counted by the same rate limits
+	bis.submitMu.Lock()
+	defer bis.submitMu.Unlock()
+	limit, err := bis.rateLimiter.Check(deposit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEoaTransferThrottled, err.Error())
+	}
+	if limit != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEoaTransferThrottled, limit)
+	}
+	b2EoaTx, err := bis.signTx(ctx, utx)
+	if err != nil {
+		return nil, err
+	}
+	rawTx, err := b2EoaTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	status := deposit.B2EoaTxStatus
+	deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusInFlight
+	deposit.B2EoaTxHash = b2EoaTx.Hash().String()
+	deposit.B2EoaRawTx = hex.EncodeToString(rawTx)
+	deposit.B2EoaTxSubmittedAt = time.Now()
+	if err := bis.saveDeposit(*deposit, ""); err != nil {
+		// not persisted, the tx is never broadcast
+		deposit.B2EoaTxStatus = status
+		deposit.B2EoaTxHash = ""
+		deposit.B2EoaRawTx = ""
+		deposit.B2EoaTxSubmittedAt = time.Time{}
+		return nil, err
+	}
+	bis.auditEoaFallback(deposit, model.EoaFallbackActionExecuted, "")
+	if err := bis.broadcast(ctx, b2EoaTx); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDepositBroadcast, err.Error())
+	}
+	return b2EoaTx, nil
+}
+
+// WatchEoaTransfer checks the receipt of the in-flight eoa transfer, the deposit is updated when mined.
+// The persisted tx is rebroadcast while not mined, it is transferred again only when the tx is dropped,
+// its nonce is used by another tx
+func (bis *BridgeDepositService) WatchEoaTransfer(deposit model.Deposit) (err error) {
+	ctx, span := bis.startWaitMined(&deposit, "bridge.eoa_wait_mined", deposit.B2EoaTxHash)
+	pending := false
+	defer func() {
+		if pending {
+			return
+		}
+		span.SetAttributes(attribute.Int("b2.eoa_tx_status", deposit.B2EoaTxStatus))
+		tracing.End(span, err)
+		bis.endWaitMined(deposit.ID)
+	}()
+	b2EoaTx, err := decodeRawTx(deposit.B2EoaRawTx)
+	if err != nil {
+		// the transfer may be sent, it needs handle manually
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusWaitMinedFailed
+		bis.auditEoaFallback(&deposit, model.EoaFallbackActionFailed, err.Error())
+		return bis.saveDeposit(deposit, "")
+	}
+
+	receipt, err := bis.transactionReceipt(ctx, deposit.B2EoaTxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		err = bis.broadcast(ctx, b2EoaTx)
+		if errors.Is(err, ErrBridgeNonceTooLow) {
+			// the tx may be mined just now, check again before transfer again
+			receipt, err = bis.transactionReceipt(ctx, deposit.B2EoaTxHash)
+			if errors.Is(err, ethereum.NotFound) {
+				err = fmt.Errorf("%w: %s, %s", ErrDepositTxDropped, deposit.B2EoaTxHash, ErrBridgeNonceTooLow)
+				bis.auditEoaFallback(&deposit, model.EoaFallbackActionFailed, err.Error())
+				deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusDropped
+				deposit.B2EoaTxHash = ""
+				deposit.B2EoaRawTx = ""
+				bis.log.Warnw("in-flight eoa transfer tx dropped, transfer again",
+					"error", err.Error(),
+					"btcTxHash", deposit.BtcTxHash)
+				return bis.saveDeposit(deposit, "")
+			}
+		}
+	}
+	switch {
+	case err != nil:
+		// check again next round
+		pending = true
+		return err
+	case receipt != nil:
+		observeGasSpent(receipt, b2EoaTx)
+		if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+			deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusWaitMinedFailed
+			bis.auditEoaFallback(&deposit, model.EoaFallbackActionFailed, ErrBridgeWaitMinedStatus.Error())
+			bis.log.Errorw("in-flight eoa transfer tx mined status err",
+				"b2EoaTxHash", deposit.B2EoaTxHash,
+				"btcTxHash", deposit.BtcTxHash)
+			break
+		}
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusSuccess
+		bis.auditEoaFallback(&deposit, model.EoaFallbackActionSucceeded, "")
+		bis.log.Infow("invoke eoa transfer success",
+			"b2EoaTxHash", deposit.B2EoaTxHash,
+			"btcTxHash", deposit.BtcTxHash)
+	case time.Since(deposit.B2EoaTxSubmittedAt) > WaitMinedTimeout:
+		// Indicates that the chain is unavailable at this time, need handle manually
+		deposit.B2EoaTxStatus = model.DepositB2EoaTxStatusContextDeadlineExceeded
+		errText := fmt.Sprintf("eoa transfer tx not mined in %s", WaitMinedTimeout)
+		bis.auditEoaFallback(&deposit, model.EoaFallbackActionFailed, errText)
+		bis.log.Errorw("invoke eoa transfer wait mined context deadline exceeded",
+			"b2EoaTxHash", deposit.B2EoaTxHash,
+			"btcTxHash", deposit.BtcTxHash)
+	default:
+		// not mined yet, check again next round, the wait mined span stays open
+		pending = true
+		return nil
+	}
+	return bis.saveDeposit(deposit, "")
+}
+
+// signTx signs the prepared tx with the pending nonce, records the rpc latency.
+// The caller holds the submit lock until the tx is broadcast, so that the nonce is not reused
+func (bis *BridgeDepositService) signTx(ctx context.Context, utx *types.UnsignedTx) (*ethtypes.Transaction, error) {
+	start := time.Now()
+	tx, err := bis.bridge.SignTx(ctx, utx)
+	addRPCEvent(ctx, "b2.sign_tx", start, err)
+	metrics.ObserveRPC(metrics.RPCB2, "sign_tx", start, err)
+	return tx, err
+}
+
+// broadcast sends the signed tx, records the rpc latency
+func (bis *BridgeDepositService) broadcast(ctx context.Context, tx *ethtypes.Transaction) error {
+	start := time.Now()
+	err := bis.bridge.Broadcast(ctx, tx)
+	addRPCEvent(ctx, "b2.broadcast", start, err, attribute.String(tracing.AttrB2TxHash, tx.Hash().String()))
+	metrics.ObserveRPC(metrics.RPCB2, "broadcast", start, err)
+	return err
+}
+
+// transactionReceipt returns the receipt of the tx hash, records the rpc latency, not mined is not an error
+func (bis *BridgeDepositService) transactionReceipt(ctx context.Context, txHash string) (*ethtypes.Receipt, error) {
+	start := time.Now()
+	receipt, err := bis.bridge.TransactionReceipt(ctx, txHash)
+	attrs := []attribute.KeyValue{attribute.String(tracing.AttrB2TxHash, txHash)}
+	if errors.Is(err, ethereum.NotFound) {
+		attrs = append(attrs, attribute.Bool("b2.tx_mined", false))
+		addRPCEvent(ctx, "b2.transaction_receipt", start, nil, attrs...)
+		metrics.ObserveRPC(metrics.RPCB2, "transaction_receipt", start, nil)
+	} else {
+		addRPCEvent(ctx, "b2.transaction_receipt", start, err, attrs...)
+		metrics.ObserveRPC(metrics.RPCB2, "transaction_receipt", start, err)
+	}
+	return receipt, err
+}
+
+// addRPCEvent records the rpc call as an event of the deposit span in the context,
+// the receipt is polled every round, a span per call floods the deposit trace
+func addRPCEvent(ctx context.Context, name string, start time.Time, err error, attrs ...attribute.KeyValue) {
+	attrs = append(attrs, attribute.Int64("rpc.duration_ms", time.Since(start).Milliseconds()))
+	if err != nil {
+		attrs = append(attrs, attribute.String("error", err.Error()))
+	}
+	trace.SpanFromContext(ctx).AddEvent(name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+}
+
+// observeBalances records the bridge contract and the signer balance until the service stops
+func (bis *BridgeDepositService) observeBalances() {
+	ticker := time.NewTicker(BalanceMetricsInterval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), BalanceMonitorTimeout)
+		start := time.Now()
+		contractBalance, signerBalance, err := bis.bridge.Balances(ctx)
+		cancel()
+		metrics.ObserveRPC(metrics.RPCB2, "balances", start, err)
+		if err != nil {
+			bis.log.Errorw("bridge deposit get balances err", "error", err)
+		} else {
+			metrics.B2Balance.WithLabelValues("contract").Set(metrics.Float(contractBalance))
+			metrics.B2Balance.WithLabelValues("signer").Set(metrics.Float(signerBalance))
+		}
+		select {
+		case <-bis.Quit():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// observeGasSpent records the gas used and the fee paid by the mined tx,
+// the tx gas price is used if the receipt has no effective gas price
+func observeGasSpent(receipt *ethtypes.Receipt, tx *ethtypes.Transaction) {
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil && tx != nil {
+		gasPrice = tx.GasPrice()
+	}
+	metrics.B2GasUsed.Add(float64(receipt.GasUsed))
+	if gasPrice != nil {
+		spent := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		metrics.B2GasSpent.Add(metrics.Float(spent))
+	}
+}
+
+// auditEoaFallback record the eoa fallback audit trail
+func (bis *BridgeDepositService) auditEoaFallback(deposit *model.Deposit, action string, reason string) {
+	// the transfer records the net amount sent, the decision before the fee deduction records the deposit value
+	amount := deposit.BtcValue
+	if deposit.NetValue.BigInt().Sign() > 0 {
+		amount = deposit.NetValue
+	}
+	audit := model.EoaFallback{
+		DepositID:   deposit.ID,
+		BtcTxHash:   deposit.BtcTxHash,
+		Policy:      bis.eoaFallbackPolicy.Policy,
+		Action:      action,
+		Amount:      amount,
+		ToAddress:   deposit.BtcFromAAAddress,
+		B2EoaTxHash: deposit.B2EoaTxHash,
+		Reason:      reason,
+	}
+	if err := bis.db.Create(&audit).Error; err != nil {
+		bis.log.Errorw("save eoa fallback audit err", "error", err, "audit", audit)
+	}
+}
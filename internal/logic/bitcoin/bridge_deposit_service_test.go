@@ -0,0 +1,467 @@
+package bitcoin_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/tracing"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestDepositShard(t *testing.T) {
+	senders := []string{
+		"tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n",
+		"tb1q9pa6zgmkcmnw7qrw6hhumjq6ccgvelgxqytsqh",
+		"bc1qf60zw2gec5qg2a2qzx2ct6c6nq0f3v5dwlnz0y",
+	}
+	for _, sender := range senders {
+		shard := bitcoin.DepositShard(sender, 4)
+		require.GreaterOrEqual(t, shard, 0)
+		require.Less(t, shard, 4)
+		require.Equal(t, shard, bitcoin.DepositShard(sender, 4))
+		require.Equal(t, 0, bitcoin.DepositShard(sender, 1))
+	}
+}
+
+// mockBridge signs the txs in memory, the hooks override the default results
+type mockBridge struct {
+	resolve   func(btcTxHash string) error
+	prepare   func(btcTxHash string) error
+	sign      func(btcTxHash string)
+	broadcast func(tx *ethtypes.Transaction) error
+	receipt   func(txHash string) (*ethtypes.Receipt, error)
+
+	nonce      atomic.Uint64
+	signCalls  atomic.Int64
+	broadcasts atomic.Int64
+}
+
+var _ types.BITCOINBridge = (*mockBridge)(nil)
+
+func (b *mockBridge) ResolveRecipient(_ context.Context, req *types.RecipientRequest) (string, string, error) {
+	if b.resolve != nil {
+		if err := b.resolve(req.BtcTxHash); err != nil {
+			return "", "", err
+		}
+	}
+	return "0x00000000000000000000000000000000000000aa", "test", nil
+}
+
+func (b *mockBridge) Deposit(string, string, *big.Int) (*ethtypes.Transaction, []byte, error) {
+	return nil, nil, errors.New("not supported")
+}
+
+func (b *mockBridge) PrepareDeposit(_ context.Context, btcTxHash string, _ string, amount *big.Int) (*types.UnsignedTx, error) {
+	if b.prepare != nil {
+		if err := b.prepare(btcTxHash); err != nil {
+			return nil, err
+		}
+	}
+	return &types.UnsignedTx{Value: amount, Data: []byte(btcTxHash)}, nil
+}
+
+func (b *mockBridge) PrepareTransfer(_ context.Context, _ string, amount *big.Int) (*types.UnsignedTx, error) {
+	return &types.UnsignedTx{Value: amount}, nil
+}
+
+func (b *mockBridge) SignTx(_ context.Context, utx *types.UnsignedTx) (*ethtypes.Transaction, error) {
+	b.signCalls.Add(1)
+	if b.sign != nil {
+		b.sign(string(utx.Data))
+	}
+	return b.newTx(utx.Value, utx.Data), nil
+}
+
+func (b *mockBridge) Broadcast(_ context.Context, tx *ethtypes.Transaction) error {
+	b.broadcasts.Add(1)
+	if b.broadcast != nil {
+		return b.broadcast(tx)
+	}
+	return nil
+}
+
+func (b *mockBridge) TransactionReceipt(_ context.Context, txHash string) (*ethtypes.Receipt, error) {
+	if b.receipt != nil {
+		return b.receipt(txHash)
+	}
+	return nil, ethereum.NotFound
+}
+
+func (b *mockBridge) Transfer(_ context.Context, _ string, amount *big.Int) (*ethtypes.Transaction, error) {
+	return b.newTx(amount, nil), nil
+}
+
+func (b *mockBridge) WaitMined(context.Context, *ethtypes.Transaction, []byte) (*ethtypes.Receipt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (b *mockBridge) Balances(context.Context) (*big.Int, *big.Int, error) {
+	return big.NewInt(0), big.NewInt(0), nil
+}
+
+func (b *mockBridge) newTx(amount *big.Int, data []byte) *ethtypes.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	return ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    b.nonce.Add(1),
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		To:       &to,
+		Value:    amount,
+		Data:     data,
+	})
+}
+
+func newTestBridgeDepositService(t *testing.T, db *gorm.DB, bridge *mockBridge) *bitcoin.BridgeDepositService {
+	bis, err := bitcoin.NewBridgeDepositService(bridge, config.BridgeConfig{
+		EoaFallbackPolicy:    bitcoin.EoaFallbackPolicyThreshold,
+		EoaFallbackMaxAmount: 100000000,
+		DepositWorkers:       2,
+	}, nil, nil, db, log.NewNopLogger())
+	require.NoError(t, err)
+	return bis
+}
+
+// claimDeposit claims the deposit as the bridge worker does
+func claimDeposit(t *testing.T, db *gorm.DB, id int64) model.Deposit {
+	deposits, err := bitcoin.ClaimDeposits(db, "test-worker", time.Minute, 1, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deposit_history.id = ?", id)
+	})
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	return deposits[0]
+}
+
+// createInFlightDeposit creates the deposit with the tx signed and submitted
+func createInFlightDeposit(t *testing.T, db *gorm.DB, bridge *mockBridge, submittedAt time.Time) (model.Deposit, *ethtypes.Transaction) {
+	deposit := createDeposit(t, db, model.DepositB2TxStatusInFlight)
+	tx := bridge.newTx(big.NewInt(1000), []byte(deposit.BtcTxHash))
+	rawTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&deposit).UpdateColumns(map[string]interface{}{
+		model.Deposit{}.Column().B2TxHash:         tx.Hash().String(),
+		model.Deposit{}.Column().B2RawTx:          hex.EncodeToString(rawTx),
+		model.Deposit{}.Column().B2TxSubmittedAt:  submittedAt,
+		model.Deposit{}.Column().B2EoaTxStatus:    model.DepositB2EoaTxStatusPending,
+		model.Deposit{}.Column().BtcFromAAAddress: "0x00000000000000000000000000000000000000aa",
+	}).Error)
+	return claimDeposit(t, db, deposit.ID), tx
+}
+
+func TestLocalBridgeDepositSubmitLock(t *testing.T) {
+	// the recipient resolution and the gas estimation of the first deposit finish after the second deposit signed,
+	// which never happens if the submit lock is held while calling them
+	for _, step := range []string{"resolve", "prepare"} {
+		t.Run(step, func(t *testing.T) {
+			testBridgeDepositSubmitLock(t, step)
+		})
+	}
+}
+
+func testBridgeDepositSubmitLock(t *testing.T, step string) {
+	db := localDB(t)
+	first := createDeposit(t, db, model.DepositB2TxStatusPending)
+	second := createDeposit(t, db, model.DepositB2TxStatusPending)
+
+	secondSigned := make(chan struct{})
+	waitSecondSigned := func(btcTxHash string) error {
+		if btcTxHash != first.BtcTxHash {
+			return nil
+		}
+		select {
+		case <-secondSigned:
+			return nil
+		case <-time.After(5 * time.Second):
+			return fmt.Errorf("second deposit not signed while %s", step)
+		}
+	}
+	bridge := &mockBridge{
+		sign: func(btcTxHash string) {
+			if btcTxHash == second.BtcTxHash {
+				close(secondSigned)
+			}
+		},
+	}
+	if step == "resolve" {
+		bridge.resolve = waitSecondSigned
+	} else {
+		bridge.prepare = waitSecondSigned
+	}
+	bis := newTestBridgeDepositService(t, db, bridge)
+
+	deposits := []model.Deposit{claimDeposit(t, db, first.ID), claimDeposit(t, db, second.ID)}
+	errs := make([]error, len(deposits))
+	var wg sync.WaitGroup
+	for i := range deposits {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = bis.HandleDeposit(deposits[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	for _, id := range []int64{first.ID, second.ID} {
+		var deposit model.Deposit
+		require.NoError(t, db.First(&deposit, id).Error)
+		require.Equal(t, model.DepositB2TxStatusInFlight, deposit.B2TxStatus, deposit.LastError)
+		require.NotEmpty(t, deposit.B2RawTx)
+		require.False(t, deposit.B2TxSubmittedAt.IsZero())
+	}
+}
+
+func TestLocalBridgeDepositOversized(t *testing.T) {
+	db := localDB(t)
+	bis, err := bitcoin.NewBridgeDepositService(&mockBridge{}, config.BridgeConfig{
+		EoaFallbackPolicy:    bitcoin.EoaFallbackPolicyThreshold,
+		EoaFallbackMaxAmount: 100000000,
+		DepositWorkers:       2,
+		RateLimits:           []string{"global:amount:1h:50000"},
+	}, nil, nil, db, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// the deposit alone exceeds the global limit, it waits approval instead of tripping the circuit breaker
+	deposit := createDeposit(t, db, model.DepositB2TxStatusPending)
+	require.NoError(t, bis.HandleDeposit(claimDeposit(t, db, deposit.ID)))
+	require.NoError(t, db.First(&deposit, deposit.ID).Error)
+	require.Equal(t, model.DepositB2TxStatusPendingApproval, deposit.B2TxStatus)
+	breaker, err := bitcoin.OpenedCircuitBreaker(db)
+	require.NoError(t, err)
+	require.Nil(t, breaker)
+
+	// the approved deposit bypasses the limit it exceeds alone
+	require.NoError(t, db.Model(&deposit).UpdateColumns(map[string]interface{}{
+		model.Deposit{}.Column().B2TxStatus:       model.DepositB2TxStatusPending,
+		model.Deposit{}.Column().ApprovalOperator: "alice",
+	}).Error)
+	require.NoError(t, bitcoin.ReleaseDepositLeases(db, "test-worker"))
+	require.NoError(t, bis.HandleDeposit(claimDeposit(t, db, deposit.ID)))
+	require.NoError(t, db.First(&deposit, deposit.ID).Error)
+	require.Equal(t, model.DepositB2TxStatusInFlight, deposit.B2TxStatus, deposit.LastError)
+}
+
+func TestLocalBridgeDepositWatch(t *testing.T) {
+	db := localDB(t)
+	bridge := &mockBridge{}
+	bis := newTestBridgeDepositService(t, db, bridge)
+
+	t.Run("mined", func(t *testing.T) {
+		deposit, tx := createInFlightDeposit(t, db, bridge, time.Now())
+		bridge.receipt = func(txHash string) (*ethtypes.Receipt, error) {
+			require.Equal(t, tx.Hash().String(), txHash)
+			return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful}, nil
+		}
+		require.NoError(t, bis.WatchDeposit(deposit))
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2TxStatusSuccess, deposit.B2TxStatus)
+	})
+
+	t.Run("not mined yet", func(t *testing.T) {
+		deposit, _ := createInFlightDeposit(t, db, bridge, time.Now())
+		bridge.receipt = nil
+		require.NoError(t, bis.WatchDeposit(deposit))
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2TxStatusInFlight, deposit.B2TxStatus)
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		deposit, _ := createInFlightDeposit(t, db, bridge, time.Now())
+		bridge.broadcast = func(*ethtypes.Transaction) error { return bitcoin.ErrBridgeNonceTooLow }
+		defer func() { bridge.broadcast = nil }()
+		require.NoError(t, bis.WatchDeposit(deposit))
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2TxStatusDropped, deposit.B2TxStatus)
+		require.Empty(t, deposit.B2TxHash)
+		require.Contains(t, deposit.LastError, bitcoin.ErrDepositTxDropped.Error())
+	})
+
+	t.Run("not mined in time", func(t *testing.T) {
+		deposit, _ := createInFlightDeposit(t, db, bridge, time.Now().Add(-bitcoin.WaitMinedTimeout-time.Minute))
+		require.NoError(t, bis.WatchDeposit(deposit))
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2TxStatusContextDeadlineExceeded, deposit.B2TxStatus)
+	})
+
+	t.Run("submitted before recorded", func(t *testing.T) {
+		deposit, _ := createInFlightDeposit(t, db, bridge, time.Time{})
+		require.NoError(t, db.Model(&deposit).UpdateColumn("updated_at", time.Now().Add(-bitcoin.WaitMinedTimeout-time.Minute)).Error)
+		deposit = claimDeposit(t, db, deposit.ID)
+		require.NoError(t, bis.WatchDeposit(deposit))
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2TxStatusContextDeadlineExceeded, deposit.B2TxStatus)
+	})
+
+	t.Run("receipt failed eoa fallback", func(t *testing.T) {
+		deposit, _ := createInFlightDeposit(t, db, bridge, time.Now())
+		bridge.receipt = func(string) (*ethtypes.Receipt, error) {
+			return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed}, nil
+		}
+		defer func() { bridge.receipt = nil }()
+		// the watcher queues the eoa transfer for the eoa worker
+		require.NoError(t, bis.WatchDeposit(deposit))
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2TxStatusWaitMinedStatusFailed, deposit.B2TxStatus)
+		require.Equal(t, model.DepositB2EoaTxStatusQueued, deposit.B2EoaTxStatus)
+
+		// the eoa worker persists the transfer in-flight, its broadcast failed as the process crashed
+		require.NoError(t, bitcoin.ReleaseDepositLeases(db, "test-worker"))
+		bridge.broadcast = func(*ethtypes.Transaction) error { return errors.New("connection refused") }
+		signCalls := bridge.signCalls.Load()
+		bis.HandleEoaFallbacks()
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2EoaTxStatusInFlight, deposit.B2EoaTxStatus)
+		require.NotEmpty(t, deposit.B2EoaTxHash)
+		require.NotEmpty(t, deposit.B2EoaRawTx)
+		require.False(t, deposit.B2EoaTxSubmittedAt.IsZero())
+		eoaTxHash := deposit.B2EoaTxHash
+
+		// the in-flight transfer is rebroadcast instead of signed again
+		bridge.broadcast = nil
+		bis.HandleEoaFallbacks()
+		bridge.receipt = func(string) (*ethtypes.Receipt, error) { return nil, ethereum.NotFound }
+		broadcasts := bridge.broadcasts.Load()
+		bis.WatchEoaTransfers()
+		require.Equal(t, broadcasts+1, bridge.broadcasts.Load())
+		require.Equal(t, signCalls+1, bridge.signCalls.Load())
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2EoaTxStatusInFlight, deposit.B2EoaTxStatus)
+
+		// the eoa worker sees the transfer mined
+		bridge.receipt = func(txHash string) (*ethtypes.Receipt, error) {
+			require.Equal(t, eoaTxHash, txHash)
+			return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful}, nil
+		}
+		bis.WatchEoaTransfers()
+		require.Equal(t, signCalls+1, bridge.signCalls.Load())
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2EoaTxStatusSuccess, deposit.B2EoaTxStatus)
+		require.Equal(t, eoaTxHash, deposit.B2EoaTxHash)
+		require.Empty(t, deposit.LeaseOwner)
+
+		var actions []string
+		require.NoError(t, db.Model(&model.EoaFallback{}).Where("deposit_id = ?", deposit.ID).Order("id").
+			Pluck("action", &actions).Error)
+		require.Equal(t, []string{model.EoaFallbackActionExecuted, model.EoaFallbackActionSucceeded}, actions)
+	})
+
+	t.Run("eoa transfer dropped", func(t *testing.T) {
+		deposit, _ := createInFlightDeposit(t, db, bridge, time.Now())
+		require.NoError(t, db.Model(&deposit).UpdateColumns(map[string]interface{}{
+			model.Deposit{}.Column().B2TxStatus:    model.DepositB2TxStatusWaitMinedStatusFailed,
+			model.Deposit{}.Column().B2EoaTxStatus: model.DepositB2EoaTxStatusQueued,
+		}).Error)
+		require.NoError(t, bitcoin.ReleaseDepositLeases(db, "test-worker"))
+		bis.HandleEoaFallbacks()
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2EoaTxStatusInFlight, deposit.B2EoaTxStatus)
+
+		bridge.broadcast = func(*ethtypes.Transaction) error { return bitcoin.ErrBridgeNonceTooLow }
+		defer func() { bridge.broadcast = nil }()
+		bis.WatchEoaTransfers()
+		require.NoError(t, db.First(&deposit, deposit.ID).Error)
+		require.Equal(t, model.DepositB2EoaTxStatusDropped, deposit.B2EoaTxStatus)
+		require.Empty(t, deposit.B2EoaTxHash)
+		require.Empty(t, deposit.B2EoaRawTx)
+	})
+}
+
+func TestLocalBridgeDepositWatchSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(sdktrace.NewSimpleSpanProcessor(exporter), 1)
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	db := localDB(t)
+	bridge := &mockBridge{}
+	bis := newTestBridgeDepositService(t, db, bridge)
+	deposit, _ := createInFlightDeposit(t, db, bridge, time.Now())
+
+	// the rounds not mined are recorded on the open span, nothing exported
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bis.WatchDeposit(deposit))
+	}
+	require.Empty(t, exporter.GetSpans())
+
+	bridge.receipt = func(string) (*ethtypes.Receipt, error) {
+		return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful}, nil
+	}
+	defer func() { bridge.receipt = nil }()
+	require.NoError(t, bis.WatchDeposit(deposit))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "bridge.wait_mined", spans[0].Name)
+	names := make([]string, 0, len(spans[0].Events))
+	for _, event := range spans[0].Events {
+		names = append(names, event.Name)
+	}
+	require.Equal(t, []string{
+		"b2.transaction_receipt", "b2.broadcast",
+		"b2.transaction_receipt", "b2.broadcast",
+		"b2.transaction_receipt", "b2.broadcast",
+		"b2.transaction_receipt",
+	}, names)
+}
+
+// running returns whether any goroutine runs the function, eg. "(*BridgeDepositService).observeBalances"
+func running(function string) bool {
+	buf := make([]byte, 1<<20)
+	return strings.Contains(string(buf[:runtime.Stack(buf, true)]), function)
+}
+
+func TestLocalBridgeDepositServiceStop(t *testing.T) {
+	db := localDB(t)
+	bis := newTestBridgeDepositService(t, db, &mockBridge{})
+	done := make(chan error, 1)
+	go func() { done <- bis.Start() }()
+	loops := []string{
+		"(*BridgeDepositService).renewLeases",
+		"(*BridgeDepositService).watchReceipts",
+		"(*BridgeDepositService).watchEoaFallbacks",
+		"(*BridgeDepositService).observeBalances",
+	}
+	require.Eventually(t, func() bool {
+		for _, loop := range loops {
+			if !running(loop) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, bis.Stop())
+	require.NoError(t, <-done)
+	require.Eventually(t, func() bool {
+		for _, loop := range loops {
+			if running(loop) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/b2network/b2-indexer/internal/config"
 	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/types"
 	"github.com/b2network/b2-indexer/pkg/log"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/ethereum/go-ethereum/common"
@@ -70,8 +71,8 @@ func TestLocalTransfer(t *testing.T) {
 		{
 			name: "success",
 			args: []interface{}{
-				"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy",
-				int64(123456),
+				"0x7eE2b84B8e3F7e04d4e0F4c4fb53b1f5f3C6c4aA",
+				big.NewInt(1234560000000000),
 			},
 			err: nil,
 		},
@@ -79,15 +80,15 @@ func TestLocalTransfer(t *testing.T) {
 			name: "fail: address empty",
 			args: []interface{}{
 				"",
-				int64(1234),
+				big.NewInt(12340000000000),
 			},
-			err: errors.New("bitcoin address is empty"),
+			err: errors.New("to address is empty"),
 		},
 	}
 
 	for _, tc := range testCase {
 		t.Run(tc.name, func(t *testing.T) {
-			hex, err := bridge.Transfer(tc.args[0].(string), tc.args[1].(int64))
+			hex, err := bridge.Transfer(context.Background(), tc.args[0].(string), tc.args[1].(*big.Int))
 			if err != nil {
 				assert.Equal(t, tc.err, err)
 			}
@@ -187,22 +188,25 @@ func bridgeWithConfig(t *testing.T) *bitcoin.Bridge {
 func TestLocalDepositWaitMined(t *testing.T) {
 	bridge := bridgeWithConfig(t)
 	uuid := randHash(t)
-	address := "tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy1"
+	address, _, err := bridge.ResolveRecipient(context.Background(), &types.RecipientRequest{
+		BtcFrom: "tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy",
+	})
+	require.NoError(t, err)
 	value := 123
 	bigValue := 11111111111111111
 
 	// params check
-	_, _, _, err := bridge.Deposit("", address, int64(value))
+	_, _, err = bridge.Deposit("", address, big.NewInt(int64(value)))
 	if err != nil {
 		assert.EqualError(t, errors.New("tx id is empty"), err.Error())
 	}
-	_, _, _, err = bridge.Deposit(uuid, "", int64(value))
+	_, _, err = bridge.Deposit(uuid, "", big.NewInt(int64(value)))
 	if err != nil {
-		assert.EqualError(t, errors.New("bitcoin address is empty"), err.Error())
+		assert.EqualError(t, errors.New("to address is empty"), err.Error())
 	}
 
 	// normal
-	b2Tx, _, _, err := bridge.Deposit(uuid, address, int64(value))
+	b2Tx, _, err := bridge.Deposit(uuid, address, big.NewInt(int64(value)))
 	if err != nil {
 		assert.NoError(t, err)
 	}
@@ -212,13 +216,13 @@ func TestLocalDepositWaitMined(t *testing.T) {
 	}
 
 	// uuid check
-	_, _, _, err = bridge.Deposit(uuid, address, int64(value))
+	_, _, err = bridge.Deposit(uuid, address, big.NewInt(int64(value)))
 	if err != nil {
 		assert.EqualError(t, bitcoin.ErrBrdigeDepositTxHashExist, err.Error())
 	}
 
 	// insufficient balance
-	_, _, _, err = bridge.Deposit(randHash(t), address, int64(bigValue))
+	_, _, err = bridge.Deposit(randHash(t), address, big.NewInt(int64(bigValue)))
 	if err != nil {
 		assert.EqualError(t, bitcoin.ErrBrdigeDepositContractInsufficientBalance, err.Error())
 	} else {
@@ -226,7 +230,7 @@ func TestLocalDepositWaitMined(t *testing.T) {
 	}
 
 	// context timeout
-	b2Tx2, _, _, err := bridge.Deposit(randHash(t), address, int64(value))
+	b2Tx2, _, err := bridge.Deposit(randHash(t), address, big.NewInt(int64(value)))
 	if err != nil {
 		assert.NoError(t, err)
 	}
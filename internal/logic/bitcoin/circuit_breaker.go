@@ -0,0 +1,74 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// circuit breaker name
+const CircuitBreakerRateLimit = "rate_limit" // global rate limit hit
+
+var ErrCircuitBreakerNotOpen = errors.New("circuit breaker is not open")
+
+// OpenCircuitBreaker open the circuit breaker, returns true if it was closed
+func OpenCircuitBreaker(db *gorm.DB, name string, reason string) (bool, error) {
+	opened := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		breaker := model.CircuitBreaker{Name: name}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(fmt.Sprintf("%s = ?", model.CircuitBreaker{}.Column().Name), name).
+			FirstOrCreate(&breaker).Error
+		if err != nil {
+			return err
+		}
+		if breaker.Open {
+			return nil
+		}
+		opened = true
+		return tx.Model(&breaker).Updates(map[string]interface{}{
+			model.CircuitBreaker{}.Column().Open:     true,
+			model.CircuitBreaker{}.Column().Reason:   reason,
+			model.CircuitBreaker{}.Column().OpenedAt: time.Now(),
+		}).Error
+	})
+	return opened, err
+}
+
+// OpenedCircuitBreaker returns the first open circuit breaker, nil if all closed
+func OpenedCircuitBreaker(db *gorm.DB) (*model.CircuitBreaker, error) {
+	var breakers []model.CircuitBreaker
+	err := db.Where(fmt.Sprintf("%s = ?", model.CircuitBreaker{}.Column().Open), true).
+		Order("id").
+		Limit(1).
+		Find(&breakers).Error
+	if err != nil || len(breakers) == 0 {
+		return nil, err
+	}
+	return &breakers[0], nil
+}
+
+// ResetCircuitBreaker operator close the circuit breaker, minting resumes
+func ResetCircuitBreaker(db *gorm.DB, name string, operator string) error {
+	if operator == "" {
+		return fmt.Errorf("operator is empty")
+	}
+	result := db.Model(&model.CircuitBreaker{}).
+		Where(fmt.Sprintf("%s = ? AND %s = ?", model.CircuitBreaker{}.Column().Name, model.CircuitBreaker{}.Column().Open), name, true).
+		Updates(map[string]interface{}{
+			model.CircuitBreaker{}.Column().Open:     false,
+			model.CircuitBreaker{}.Column().Operator: operator,
+			model.CircuitBreaker{}.Column().ResetAt:  time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCircuitBreakerNotOpen
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package bitcoin_test
+
+import (
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalCircuitBreaker(t *testing.T) {
+	db := localDB(t)
+	breaker, err := bitcoin.OpenedCircuitBreaker(db)
+	require.NoError(t, err)
+	require.Nil(t, breaker)
+	require.ErrorIs(t, bitcoin.ResetCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, "alice"), bitcoin.ErrCircuitBreakerNotOpen)
+
+	opened, err := bitcoin.OpenCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, "global amount limit exceeded")
+	require.NoError(t, err)
+	require.True(t, opened)
+	// already open
+	opened, err = bitcoin.OpenCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, "again")
+	require.NoError(t, err)
+	require.False(t, opened)
+
+	breaker, err = bitcoin.OpenedCircuitBreaker(db)
+	require.NoError(t, err)
+	require.NotNil(t, breaker)
+	require.Equal(t, bitcoin.CircuitBreakerRateLimit, breaker.Name)
+	require.Equal(t, "global amount limit exceeded", breaker.Reason)
+	require.False(t, breaker.OpenedAt.IsZero())
+
+	require.Error(t, bitcoin.ResetCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, ""))
+	require.NoError(t, bitcoin.ResetCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, "alice"))
+	breaker, err = bitcoin.OpenedCircuitBreaker(db)
+	require.NoError(t, err)
+	require.Nil(t, breaker)
+	require.ErrorIs(t, bitcoin.ResetCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, "alice"), bitcoin.ErrCircuitBreakerNotOpen)
+
+	// reopened after reset
+	opened, err = bitcoin.OpenCircuitBreaker(db, bitcoin.CircuitBreakerRateLimit, "exceeded again")
+	require.NoError(t, err)
+	require.True(t, opened)
+}
@@ -0,0 +1,34 @@
+package bitcoin_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// localDB opens the local postgres in a new schema with the tables migrated
+func localDB(t *testing.T) *gorm.DB {
+	return testutil.LocalDB(t)
+}
+
+// createDeposit creates the deposit with the status
+func createDeposit(t *testing.T, db *gorm.DB, status int) model.Deposit {
+	deposit := model.Deposit{
+		BtcBlockNumber: 100,
+		BtcTxHash:      fmt.Sprintf("%064d", time.Now().UnixNano()),
+		BtcFrom:        "tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv",
+		BtcFroms:       "[]",
+		BtcValue:       model.NewBigIntFromInt64(100000),
+		B2TxStatus:     status,
+		BtcBlockTime:   time.Now(),
+	}
+	require.NoError(t, db.Create(&deposit).Error)
+	// the zero success status is replaced by the column default on create
+	require.NoError(t, db.Model(&deposit).UpdateColumn(model.Deposit{}.Column().B2TxStatus, status).Error)
+	return deposit
+}
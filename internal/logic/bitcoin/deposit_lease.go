@@ -0,0 +1,105 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DepositLeaseTimeout = 5 * time.Minute
+	// renew the leases several times before expiry
+	DepositLeaseRenewInterval = DepositLeaseTimeout / 3
+)
+
+var ErrDepositLeaseLost = errors.New("deposit lease lost")
+
+// NewWorkerID returns the worker id of the process, the hostname is unique per replica
+func NewWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// ClaimDeposits claims the deposits by the query scope which are not leased by other workers.
+// Rows locked by other workers are skipped, so that replicas never claim the same deposit.
+func ClaimDeposits(
+	db *gorm.DB,
+	owner string,
+	lease time.Duration,
+	limit int,
+	scope func(*gorm.DB) *gorm.DB,
+) ([]model.Deposit, error) {
+	var deposits []model.Deposit
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Scopes(scope).
+			Where(
+				fmt.Sprintf("%s.%s IS NULL OR %s.%s < ?",
+					model.Deposit{}.TableName(), model.Deposit{}.Column().LeaseExpiresAt,
+					model.Deposit{}.TableName(), model.Deposit{}.Column().LeaseExpiresAt),
+				now,
+			).
+			Limit(limit).
+			Find(&deposits).Error
+		if err != nil || len(deposits) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(deposits))
+		for i := range deposits {
+			ids = append(ids, deposits[i].ID)
+			deposits[i].LeaseOwner = owner
+			deposits[i].LeaseExpiresAt = now.Add(lease)
+		}
+		// the lease is not a deposit change, updated_at is kept
+		return tx.Model(&model.Deposit{}).
+			Where("id IN ?", ids).
+			UpdateColumns(map[string]interface{}{
+				model.Deposit{}.Column().LeaseOwner:     owner,
+				model.Deposit{}.Column().LeaseExpiresAt: now.Add(lease),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deposits, nil
+}
+
+// RenewDepositLeases extends the leases held by the worker
+func RenewDepositLeases(db *gorm.DB, owner string, lease time.Duration) error {
+	return db.Model(&model.Deposit{}).
+		Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().LeaseOwner), owner).
+		UpdateColumn(model.Deposit{}.Column().LeaseExpiresAt, time.Now().Add(lease)).Error
+}
+
+// ReleaseDepositLeases releases the leases held by the worker, all leases if no deposit id
+func ReleaseDepositLeases(db *gorm.DB, owner string, ids ...int64) error {
+	query := db.Model(&model.Deposit{}).
+		Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().LeaseOwner), owner)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.UpdateColumns(map[string]interface{}{
+		model.Deposit{}.Column().LeaseOwner:     "",
+		model.Deposit{}.Column().LeaseExpiresAt: nil,
+	}).Error
+}
+
+// CheckDepositLease returns the precondition of the deposit transition, which requires the lease held by the worker
+func CheckDepositLease(owner string) func(*model.Deposit) error {
+	return func(deposit *model.Deposit) error {
+		if deposit.LeaseOwner != owner {
+			return fmt.Errorf("%w: deposit %d leased by %q", ErrDepositLeaseLost, deposit.ID, deposit.LeaseOwner)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package bitcoin_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestCheckDepositLease(t *testing.T) {
+	check := bitcoin.CheckDepositLease("worker-1")
+	require.NoError(t, check(&model.Deposit{LeaseOwner: "worker-1"}))
+	require.ErrorIs(t, check(&model.Deposit{LeaseOwner: "worker-2"}), bitcoin.ErrDepositLeaseLost)
+	require.ErrorIs(t, check(&model.Deposit{}), bitcoin.ErrDepositLeaseLost)
+}
+
+func TestLeaderLockKey(t *testing.T) {
+	require.Equal(t, bitcoin.LeaderLockKey(bitcoin.ServiceName), bitcoin.LeaderLockKey(bitcoin.ServiceName))
+	require.NotEqual(t, bitcoin.LeaderLockKey(bitcoin.ServiceName), bitcoin.LeaderLockKey(bitcoin.BridgeDepositServiceName))
+}
+
+func TestLocalClaimDeposits(t *testing.T) {
+	db := localDB(t)
+	locked := createDeposit(t, db, model.DepositB2TxStatusPending)
+	free := createDeposit(t, db, model.DepositB2TxStatusPending)
+	createDeposit(t, db, model.DepositB2TxStatusSuccess)
+	// reload the db time precision
+	require.NoError(t, db.First(&free, free.ID).Error)
+	pending := func(db *gorm.DB) *gorm.DB {
+		return db.Where("b2_tx_status = ?", model.DepositB2TxStatusPending).Order("id")
+	}
+
+	// the row locked by another worker is skipped instead of waited
+	tx := db.Begin()
+	defer tx.Rollback()
+	require.NoError(t, tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Deposit{}, locked.ID).Error)
+	deposits, err := bitcoin.ClaimDeposits(db, "worker-1", time.Minute, 10, pending)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, free.ID, deposits[0].ID)
+	require.Equal(t, "worker-1", deposits[0].LeaseOwner)
+	require.NoError(t, tx.Rollback().Error)
+
+	// the leased deposit is not claimed by another worker
+	deposits, err = bitcoin.ClaimDeposits(db, "worker-2", time.Minute, 10, pending)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, locked.ID, deposits[0].ID)
+	deposits, err = bitcoin.ClaimDeposits(db, "worker-3", time.Minute, 10, pending)
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+
+	// the lease is not a deposit change
+	var claimed model.Deposit
+	require.NoError(t, db.First(&claimed, free.ID).Error)
+	require.True(t, claimed.UpdatedAt.Equal(free.UpdatedAt))
+	require.NoError(t, bitcoin.RenewDepositLeases(db, "worker-1", time.Hour))
+	require.NoError(t, db.First(&claimed, free.ID).Error)
+	require.True(t, claimed.UpdatedAt.Equal(free.UpdatedAt))
+	require.True(t, claimed.LeaseExpiresAt.After(time.Now().Add(30*time.Minute)))
+
+	// released by the owner only
+	require.NoError(t, bitcoin.ReleaseDepositLeases(db, "worker-2", free.ID))
+	require.NoError(t, db.First(&claimed, free.ID).Error)
+	require.Equal(t, "worker-1", claimed.LeaseOwner)
+	require.NoError(t, bitcoin.ReleaseDepositLeases(db, "worker-1"))
+	require.NoError(t, db.First(&claimed, free.ID).Error)
+	require.Empty(t, claimed.LeaseOwner)
+	require.True(t, claimed.UpdatedAt.Equal(free.UpdatedAt))
+	deposits, err = bitcoin.ClaimDeposits(db, "worker-3", time.Minute, 10, pending)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, free.ID, deposits[0].ID)
+
+	// the expired lease of a crashed worker is claimed
+	deposits, err = bitcoin.ClaimDeposits(db, "worker-4", time.Millisecond, 10, func(db *gorm.DB) *gorm.DB {
+		return db.Where("b2_tx_status = ?", model.DepositB2TxStatusSuccess)
+	})
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	time.Sleep(10 * time.Millisecond)
+	deposits, err = bitcoin.ClaimDeposits(db, "worker-5", time.Minute, 10, func(db *gorm.DB) *gorm.DB {
+		return db.Where("b2_tx_status = ?", model.DepositB2TxStatusSuccess)
+	})
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, "worker-5", deposits[0].LeaseOwner)
+}
+
+func TestLocalLeaderElector(t *testing.T) {
+	db := localDB(t)
+	ctx := context.Background()
+	name := fmt.Sprintf("test-leader-%d", time.Now().UnixNano())
+	leader := bitcoin.NewLeaderElector(db, name, log.NewNopLogger())
+	standby := bitcoin.NewLeaderElector(db, name, log.NewNopLogger())
+	other := bitcoin.NewLeaderElector(db, name+"-other", log.NewNopLogger())
+
+	acquired, err := leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.True(t, leader.IsLeader())
+	acquired, err = standby.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.False(t, standby.IsLeader())
+	// other names are elected independently
+	acquired, err = other.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	other.Release()
+
+	// the standby takes over after the leader released
+	leader.Release()
+	require.False(t, leader.IsLeader())
+	acquired, err = standby.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, acquired)
+
+	// the broken lock connection is seen by the timer check as lost leadership
+	require.NoError(t, db.Exec("SELECT pg_terminate_backend(pid) FROM pg_locks WHERE locktype = 'advisory' AND granted "+
+		"AND ((classid::bigint << 32) | objid::bigint) = ?", bitcoin.LeaderLockKey(name)).Error)
+	require.True(t, standby.IsLeader())
+	require.Eventually(t, func() bool { return !standby.IsLeader() }, 2*bitcoin.LeaderCheckInterval, 100*time.Millisecond)
+	acquired, err = leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	leader.Release()
+	standby.Release()
+}
@@ -0,0 +1,132 @@
+package bitcoin
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+const (
+	DepositNotifyChannel   = "b2_indexer_deposit"
+	ListenReconnectTimeout = 5 * time.Second
+)
+
+// NotifyDeposit publishes the deposit change in the db transaction, the listeners receive it after commit
+func NotifyDeposit(tx *gorm.DB, depositID int64) error {
+	return tx.Exec("SELECT pg_notify(?, ?)", DepositNotifyChannel, strconv.FormatInt(depositID, 10)).Error
+}
+
+// DepositListener listens the deposit notifications by postgres LISTEN and wakes up the subscribers.
+// The subscribers keep polling, a notification only shortens the wait.
+type DepositListener struct {
+	db  *gorm.DB
+	log log.Logger
+
+	mu          sync.Mutex
+	subscribers []chan struct{}
+}
+
+// NewDepositListener returns the deposit listener
+func NewDepositListener(db *gorm.DB, logger log.Logger) *DepositListener {
+	return &DepositListener{db: db, log: logger}
+}
+
+// Subscribe returns the wake-up channel, the notifications are coalesced while the subscriber is busy
+func (l *DepositListener) Subscribe() <-chan struct{} {
+	if l == nil {
+		return nil
+	}
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscribers = append(l.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe removes the subscriber, eg. the watch stream closed
+func (l *DepositListener) Unsubscribe(sub <-chan struct{}) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, ch := range l.subscribers {
+		if ch == sub {
+			l.subscribers = append(l.subscribers[:i], l.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Wake wakes up all subscribers
+func (l *DepositListener) Wake() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ch := range l.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Listen blocks listening the notifications until the context done, reconnects on connection err
+func (l *DepositListener) Listen(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		l.log.Errorw("deposit listener err, reconnect", "error", err)
+		time.Sleep(ListenReconnectTimeout)
+		// the notifications may be missed while reconnecting
+		l.Wake()
+	}
+}
+
+func (l *DepositListener) listen(ctx context.Context) error {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var listenErr error
+	_ = conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			listenErr = fmt.Errorf("deposit listener requires pgx driver, got %T", driverConn)
+			return nil
+		}
+		pgxConn := stdConn.Conn()
+		_, listenErr = pgxConn.Exec(ctx, "LISTEN "+pgx.Identifier{DepositNotifyChannel}.Sanitize())
+		if listenErr == nil {
+			l.log.Infow("deposit listener started", "channel", DepositNotifyChannel)
+			for {
+				var notification *pgconn.Notification
+				notification, listenErr = pgxConn.WaitForNotification(ctx)
+				if listenErr != nil {
+					break
+				}
+				l.log.Debugw("deposit notification", "depositID", notification.Payload)
+				l.Wake()
+			}
+		}
+		// the connection is still listening, discard it instead of returning to the pool
+		return driver.ErrBadConn
+	})
+	return listenErr
+}
@@ -0,0 +1,40 @@
+package bitcoin_test
+
+import (
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositListenerWake(t *testing.T) {
+	listener := bitcoin.NewDepositListener(nil, nil)
+	bridge := listener.Subscribe()
+	eps := listener.Subscribe()
+
+	// notifications are coalesced while the subscriber is busy
+	listener.Wake()
+	listener.Wake()
+	for _, ch := range []<-chan struct{}{bridge, eps} {
+		require.Len(t, ch, 1)
+		<-ch
+		require.Len(t, ch, 0)
+	}
+
+	var nilListener *bitcoin.DepositListener
+	require.Nil(t, nilListener.Subscribe())
+}
+
+func TestDepositListenerUnsubscribe(t *testing.T) {
+	listener := bitcoin.NewDepositListener(nil, nil)
+	watch := listener.Subscribe()
+	eps := listener.Subscribe()
+	listener.Unsubscribe(watch)
+
+	listener.Wake()
+	require.Len(t, watch, 0)
+	require.Len(t, eps, 1)
+
+	var nilListener *bitcoin.DepositListener
+	nilListener.Unsubscribe(eps)
+}
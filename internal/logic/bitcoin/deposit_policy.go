@@ -0,0 +1,110 @@
+package bitcoin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/b2network/b2-indexer/pkg/utils"
+)
+
+// DepositPolicyChain checks the deposit by each policy in order, the first reject reason wins
+type DepositPolicyChain struct {
+	policies []types.DepositPolicy
+}
+
+// NewDepositPolicyChain new deposit policy chain
+func NewDepositPolicyChain(policies ...types.DepositPolicy) *DepositPolicyChain {
+	return &DepositPolicyChain{policies: policies}
+}
+
+// NewDepositPolicyChainWithConfig new deposit policy chain by bitcoin config
+func NewDepositPolicyChainWithConfig(cfg *config.BitconConfig) *DepositPolicyChain {
+	var policies []types.DepositPolicy
+	if cfg.IndexerMinDeposit > 0 {
+		policies = append(policies, &MinDepositPolicy{MinAmount: cfg.IndexerMinDeposit})
+	}
+	if len(cfg.IndexerBlacklist) > 0 {
+		policies = append(policies, NewBlacklistPolicy(cfg.IndexerBlacklist))
+	}
+	if len(cfg.IndexerScriptTypes) > 0 {
+		policies = append(policies, NewScriptTypePolicy(cfg.IndexerScriptTypes))
+	}
+	return NewDepositPolicyChain(policies...)
+}
+
+// Check implements types.DepositPolicy
+func (c *DepositPolicyChain) Check(result *types.BitcoinTxParseResult) (string, error) {
+	for _, policy := range c.policies {
+		reason, err := policy.Check(result)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+	return "", nil
+}
+
+// MinDepositPolicy rejects dust deposits
+type MinDepositPolicy struct {
+	MinAmount int64
+}
+
+// Check implements types.DepositPolicy
+func (p *MinDepositPolicy) Check(result *types.BitcoinTxParseResult) (string, error) {
+	if result.Value < p.MinAmount {
+		return fmt.Sprintf("amount %d below min deposit %d", result.Value, p.MinAmount), nil
+	}
+	return "", nil
+}
+
+// BlacklistPolicy rejects deposits from the blacklisted bitcoin addresses
+type BlacklistPolicy struct {
+	addresses map[string]struct{}
+}
+
+// NewBlacklistPolicy new blacklist policy
+func NewBlacklistPolicy(addresses []string) *BlacklistPolicy {
+	p := &BlacklistPolicy{addresses: make(map[string]struct{}, len(addresses))}
+	for _, address := range addresses {
+		p.addresses[strings.TrimSpace(address)] = struct{}{}
+	}
+	return p
+}
+
+// Check implements types.DepositPolicy
+func (p *BlacklistPolicy) Check(result *types.BitcoinTxParseResult) (string, error) {
+	for _, from := range result.From {
+		if _, ok := p.addresses[from]; ok {
+			return fmt.Sprintf("from address %s is blacklisted", from), nil
+		}
+	}
+	return "", nil
+}
+
+// ScriptTypePolicy rejects deposits from the script types not allowed
+type ScriptTypePolicy struct {
+	scriptTypes []string
+}
+
+// NewScriptTypePolicy new script type policy
+func NewScriptTypePolicy(scriptTypes []string) *ScriptTypePolicy {
+	p := &ScriptTypePolicy{}
+	for _, scriptType := range scriptTypes {
+		p.scriptTypes = append(p.scriptTypes, strings.TrimSpace(scriptType))
+	}
+	return p
+}
+
+// Check implements types.DepositPolicy
+func (p *ScriptTypePolicy) Check(result *types.BitcoinTxParseResult) (string, error) {
+	if len(result.FromScriptTypes) == 0 {
+		return "from address script type unknown", nil
+	}
+	for _, scriptType := range result.FromScriptTypes {
+		if !utils.StrInArray(p.scriptTypes, scriptType) {
+			return fmt.Sprintf("from script type %s not allowed", scriptType), nil
+		}
+	}
+	return "", nil
+}
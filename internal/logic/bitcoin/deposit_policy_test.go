@@ -0,0 +1,92 @@
+package bitcoin_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+type errDepositPolicy struct{}
+
+func (errDepositPolicy) Check(*types.BitcoinTxParseResult) (string, error) {
+	return "", errors.New("policy unavailable")
+}
+
+func TestDepositPolicyChain(t *testing.T) {
+	chain := bitcoin.NewDepositPolicyChainWithConfig(&config.BitconConfig{
+		IndexerMinDeposit:  10000,
+		IndexerBlacklist:   []string{"tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n"},
+		IndexerScriptTypes: []string{"witness_v0_keyhash", "witness_v1_taproot"},
+	})
+
+	testCases := []struct {
+		name   string
+		result *types.BitcoinTxParseResult
+		reason string
+	}{
+		{
+			name: "accepted",
+			result: &types.BitcoinTxParseResult{
+				From:            []string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz"},
+				FromScriptTypes: []string{"witness_v0_keyhash"},
+				Value:           10000,
+			},
+		},
+		{
+			name: "dust",
+			result: &types.BitcoinTxParseResult{
+				From:            []string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz"},
+				FromScriptTypes: []string{"witness_v0_keyhash"},
+				Value:           9999,
+			},
+			reason: "amount 9999 below min deposit 10000",
+		},
+		{
+			name: "blacklisted",
+			result: &types.BitcoinTxParseResult{
+				From:            []string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz", "tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n"},
+				FromScriptTypes: []string{"witness_v0_keyhash", "witness_v0_keyhash"},
+				Value:           10000,
+			},
+			reason: "from address tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n is blacklisted",
+		},
+		{
+			name: "script type not allowed",
+			result: &types.BitcoinTxParseResult{
+				From:            []string{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn"},
+				FromScriptTypes: []string{"pubkeyhash"},
+				Value:           10000,
+			},
+			reason: "from script type pubkeyhash not allowed",
+		},
+		{
+			name: "script type unknown",
+			result: &types.BitcoinTxParseResult{
+				Value: 10000,
+			},
+			reason: "from address script type unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, err := chain.Check(tc.result)
+			require.NoError(t, err)
+			require.Equal(t, tc.reason, reason)
+		})
+	}
+
+	// empty chain accepts all deposits
+	reason, err := bitcoin.NewDepositPolicyChainWithConfig(&config.BitconConfig{}).Check(&types.BitcoinTxParseResult{Value: 1})
+	require.NoError(t, err)
+	require.Empty(t, reason)
+
+	// error stops the chain
+	_, err = bitcoin.NewDepositPolicyChain(errDepositPolicy{}, &bitcoin.MinDepositPolicy{MinAmount: 10}).
+		Check(&types.BitcoinTxParseResult{Value: 1})
+	require.Error(t, err)
+}
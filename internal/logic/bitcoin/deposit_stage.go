@@ -0,0 +1,66 @@
+package bitcoin
+
+import "github.com/b2network/b2-indexer/internal/model"
+
+const (
+	// deposit lifecycle stage shown to the wallet
+	DepositStageDetected     = "detected"      // deposit indexed from the bitcoin block
+	DepositStageConfirmed    = "confirmed"     // deposit passed the checks, waiting for the mint
+	DepositStageSubmitted    = "submitted"     // mint tx submitted to b2
+	DepositStageMined        = "mined"         // minted by the bridge contract or the eoa transfer
+	DepositStageEpsDelivered = "eps_delivered" // eps delivered to the eps endpoint
+	DepositStageHeld         = "held"          // held by compliance screening or wait operator approval
+	DepositStageFailed       = "failed"        // mint failed or deposit rejected
+)
+
+// depositConfirmedFrom the B2TxStatus a deposit is confirmed from when it becomes pending,
+// the retries of the handled deposits are not stages
+var depositConfirmedFrom = []int{
+	model.DepositStatusNone,
+	model.DepositB2TxStatusComplianceHold,
+	model.DepositB2TxStatusPendingApproval,
+}
+
+// DepositStages returns the lifecycle stages the deposit event moves the deposit to
+func DepositStages(event model.DepositEvent) []string {
+	var stages []string
+	switch event.StatusType {
+	case model.DepositEventStatusEps:
+		if event.ToStatus == model.EspStatusSuccess {
+			stages = append(stages, DepositStageEpsDelivered)
+		}
+		return stages
+	case model.DepositEventStatusB2EoaTx:
+		switch {
+		case event.FromStatus == event.ToStatus:
+		case event.ToStatus == model.DepositB2EoaTxStatusSuccess:
+			stages = append(stages, DepositStageMined)
+		case event.ToStatus == model.DepositB2EoaTxStatusPendingApproval:
+			stages = append(stages, DepositStageHeld)
+		case containsStatus(webhookEoaFailedStatuses, event.ToStatus):
+			stages = append(stages, DepositStageFailed)
+		}
+		return stages
+	}
+	if event.FromStatus == model.DepositStatusNone {
+		stages = append(stages, DepositStageDetected)
+	}
+	switch {
+	case event.FromStatus == event.ToStatus:
+		// retried with the error, not a transition
+	case event.ToStatus == model.DepositB2TxStatusPending:
+		if containsStatus(depositConfirmedFrom, event.FromStatus) {
+			stages = append(stages, DepositStageConfirmed)
+		}
+	case event.ToStatus == model.DepositB2TxStatusInFlight:
+		stages = append(stages, DepositStageSubmitted)
+	case event.ToStatus == model.DepositB2TxStatusSuccess:
+		stages = append(stages, DepositStageMined)
+	case event.ToStatus == model.DepositB2TxStatusComplianceHold,
+		event.ToStatus == model.DepositB2TxStatusPendingApproval:
+		stages = append(stages, DepositStageHeld)
+	case containsStatus(webhookFailedStatuses, event.ToStatus):
+		stages = append(stages, DepositStageFailed)
+	}
+	return stages
+}
@@ -0,0 +1,85 @@
+package bitcoin_test
+
+import (
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositStages(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    model.DepositEvent
+		expected []string
+	}{
+		{
+			name:     "detected and confirmed",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositStatusNone, ToStatus: model.DepositB2TxStatusPending},
+			expected: []string{bitcoin.DepositStageDetected, bitcoin.DepositStageConfirmed},
+		},
+		{
+			name:     "detected and rejected",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositStatusNone, ToStatus: model.DepositB2TxStatusRejected},
+			expected: []string{bitcoin.DepositStageDetected, bitcoin.DepositStageFailed},
+		},
+		{
+			name:     "released from hold",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusComplianceHold, ToStatus: model.DepositB2TxStatusPending},
+			expected: []string{bitcoin.DepositStageConfirmed},
+		},
+		{
+			name:     "retried to pending",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusInsufficientBalance, ToStatus: model.DepositB2TxStatusPending},
+			expected: nil,
+		},
+		{
+			name:     "submitted",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusPending, ToStatus: model.DepositB2TxStatusInFlight},
+			expected: []string{bitcoin.DepositStageSubmitted},
+		},
+		{
+			name:     "mined",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusInFlight, ToStatus: model.DepositB2TxStatusSuccess},
+			expected: []string{bitcoin.DepositStageMined},
+		},
+		{
+			name:     "held",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusPending, ToStatus: model.DepositB2TxStatusPendingApproval},
+			expected: []string{bitcoin.DepositStageHeld},
+		},
+		{
+			name:     "eoa created",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2EoaTx, FromStatus: model.DepositStatusNone, ToStatus: model.DepositB2EoaTxStatusPending},
+			expected: nil,
+		},
+		{
+			name:     "eoa mined",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2EoaTx, FromStatus: model.DepositB2EoaTxStatusPending, ToStatus: model.DepositB2EoaTxStatusSuccess},
+			expected: []string{bitcoin.DepositStageMined},
+		},
+		{
+			name:     "eps delivered",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusEps, FromStatus: model.EspStatus, ToStatus: model.EspStatusSuccess},
+			expected: []string{bitcoin.DepositStageEpsDelivered},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, bitcoin.DepositStages(tc.event))
+		})
+	}
+}
+
+func TestEpsDeliveredEvent(t *testing.T) {
+	eps := model.Eps{DepositID: 3, B2TxHash: "0xabc", Status: model.EspStatusDeadLetter}
+	event := bitcoin.EpsDeliveredEvent(&eps, "btc-hash")
+	require.Equal(t, int64(3), event.DepositID)
+	require.Equal(t, "btc-hash", event.BtcTxHash)
+	require.Equal(t, model.DepositEventStatusEps, event.StatusType)
+	require.Equal(t, "dead_letter", event.FromState)
+	require.Equal(t, "delivered", event.ToState)
+	require.Equal(t, "0xabc", event.TxHash)
+	require.Equal(t, bitcoin.DepositActorEps, event.Actor)
+}
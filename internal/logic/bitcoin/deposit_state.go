@@ -0,0 +1,316 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deposit event actor, operator actions use the operator name
+const (
+	DepositActorIndexer       = "indexer"
+	DepositActorBridgeDeposit = "bridge-deposit"
+	DepositActorEps           = "eps"
+)
+
+var (
+	ErrDepositInvalidTransition = errors.New("invalid deposit status transition")
+	ErrDepositUnknownStatus     = errors.New("unknown deposit status")
+	ErrDepositTxDropped         = errors.New("in-flight tx dropped")
+)
+
+var depositStatusNames = map[int]string{
+	model.DepositStatusNone:                           "none",
+	model.DepositB2TxStatusSuccess:                    "success",
+	model.DepositB2TxStatusPending:                    "pending",
+	model.DepositB2TxStatusFailed:                     "failed",
+	model.DepositB2TxStatusWaitMinedFailed:            "wait_mined_failed",
+	model.DepositB2TxStatusTxHashExist:                "tx_hash_exist",
+	model.DepositB2TxStatusWaitMinedStatusFailed:      "wait_mined_status_failed",
+	model.DepositB2TxStatusInsufficientBalance:        "insufficient_balance",
+	model.DepositB2TxStatusContextDeadlineExceeded:    "context_deadline_exceeded",
+	model.DepositB2TxStatusFromAccountGasInsufficient: "from_account_gas_insufficient",
+	model.DepositB2TxStatusFeeExceedsAmount:           "fee_exceeds_amount",
+	model.DepositB2TxStatusRejected:                   "rejected",
+	model.DepositB2TxStatusComplianceHold:             "compliance_hold",
+	model.DepositB2TxStatusPendingApproval:            "pending_approval",
+	model.DepositB2TxStatusApprovalRejected:           "approval_rejected",
+	model.DepositB2TxStatusThrottled:                  "throttled",
+	model.DepositB2TxStatusInFlight:                   "in_flight",
+	model.DepositB2TxStatusAmountInexact:              "amount_inexact",
+	model.DepositB2TxStatusDropped:                    "dropped",
+}
+
+var depositEoaStatusNames = map[int]string{
+	model.DepositStatusNone:                           "none",
+	model.DepositB2EoaTxStatusSuccess:                 "success",
+	model.DepositB2EoaTxStatusPending:                 "pending",
+	model.DepositB2EoaTxStatusFailed:                  "failed",
+	model.DepositB2EoaTxStatusWaitMinedFailed:         "wait_mined_failed",
+	model.DepositB2EoaTxStatusPendingApproval:         "pending_approval",
+	model.DepositB2EoaTxStatusApproved:                "approved",
+	model.DepositB2EoaTxStatusRejected:                "rejected",
+	model.DepositB2EoaTxStatusContextDeadlineExceeded: "context_deadline_exceeded",
+	model.DepositB2EoaTxStatusPolicySkipped:           "policy_skipped",
+	model.DepositB2EoaTxStatusQueued:                  "queued",
+	model.DepositB2EoaTxStatusInFlight:                "in_flight",
+	model.DepositB2EoaTxStatusDropped:                 "dropped",
+}
+
+var epsStatusNames = map[int]string{
+	model.EspStatus:             "pending",
+	model.EspStatusSuccess:      "delivered",
+	model.EspStatusDeadLetter:   "dead_letter",
+	model.EspStatusIngestFailed: "ingest_failed",
+}
+
+// depositSendResults the statuses a send attempt of the bridge deposit service can reach,
+// the sent tx is in-flight until the receipt watcher sees it mined
+var depositSendResults = []int{
+	model.DepositB2TxStatusPending,
+	model.DepositB2TxStatusFailed,
+	model.DepositB2TxStatusTxHashExist,
+	model.DepositB2TxStatusInsufficientBalance,
+	model.DepositB2TxStatusFromAccountGasInsufficient,
+	model.DepositB2TxStatusFeeExceedsAmount,
+	model.DepositB2TxStatusAmountInexact,
+	model.DepositB2TxStatusComplianceHold,
+	model.DepositB2TxStatusPendingApproval,
+	model.DepositB2TxStatusThrottled,
+	model.DepositB2TxStatusInFlight,
+}
+
+// depositTransitions allowed B2TxStatus transitions, statuses not listed are final
+var depositTransitions = map[int][]int{
+	model.DepositStatusNone: {model.DepositB2TxStatusPending, model.DepositB2TxStatusRejected},
+	// the statuses handled by the bridge deposit service
+	model.DepositB2TxStatusPending:                    depositSendResults,
+	model.DepositB2TxStatusInsufficientBalance:        depositSendResults,
+	model.DepositB2TxStatusFromAccountGasInsufficient: depositSendResults,
+	model.DepositB2TxStatusFailed:                     depositSendResults,
+	model.DepositB2TxStatusThrottled:                  depositSendResults,
+	model.DepositB2TxStatusDropped:                    depositSendResults,
+	// released or reviewed by operator
+	model.DepositB2TxStatusComplianceHold:  {model.DepositB2TxStatusPending},
+	model.DepositB2TxStatusPendingApproval: {model.DepositB2TxStatusPending, model.DepositB2TxStatusApprovalRejected},
+	// in-flight tx is recovered by the receipt, only the dropped tx is handled again
+	model.DepositB2TxStatusInFlight: {
+		model.DepositB2TxStatusSuccess,
+		model.DepositB2TxStatusDropped,
+		model.DepositB2TxStatusWaitMinedStatusFailed,
+		model.DepositB2TxStatusContextDeadlineExceeded,
+	},
+}
+
+// depositEoaTransitions allowed B2EoaTxStatus transitions, statuses not listed are final
+var depositEoaTransitions = map[int][]int{
+	model.DepositStatusNone: {model.DepositB2EoaTxStatusPending},
+	model.DepositB2EoaTxStatusPending: {
+		model.DepositB2EoaTxStatusSuccess,
+		model.DepositB2EoaTxStatusFailed,
+		model.DepositB2EoaTxStatusWaitMinedFailed,
+		model.DepositB2EoaTxStatusContextDeadlineExceeded,
+		model.DepositB2EoaTxStatusPendingApproval,
+		model.DepositB2EoaTxStatusPolicySkipped,
+		model.DepositB2EoaTxStatusQueued,
+	},
+	model.DepositB2EoaTxStatusPendingApproval: {
+		model.DepositB2EoaTxStatusApproved,
+		model.DepositB2EoaTxStatusRejected,
+	},
+	// the eoa worker persists the signed transfer in-flight before broadcast
+	model.DepositB2EoaTxStatusApproved: {
+		model.DepositB2EoaTxStatusInFlight,
+		model.DepositB2EoaTxStatusFailed,
+	},
+	model.DepositB2EoaTxStatusQueued: {
+		model.DepositB2EoaTxStatusInFlight,
+		model.DepositB2EoaTxStatusFailed,
+	},
+	model.DepositB2EoaTxStatusDropped: {
+		model.DepositB2EoaTxStatusInFlight,
+		model.DepositB2EoaTxStatusFailed,
+	},
+	// in-flight transfer is recovered by the receipt, only the dropped tx is transferred again
+	model.DepositB2EoaTxStatusInFlight: {
+		model.DepositB2EoaTxStatusSuccess,
+		model.DepositB2EoaTxStatusWaitMinedFailed,
+		model.DepositB2EoaTxStatusContextDeadlineExceeded,
+		model.DepositB2EoaTxStatusDropped,
+	},
+}
+
+// DepositStatusName returns the name of the B2TxStatus
+func DepositStatusName(status int) string {
+	return statusName(depositStatusNames, status)
+}
+
+// DepositEoaStatusName returns the name of the B2EoaTxStatus
+func DepositEoaStatusName(status int) string {
+	return statusName(depositEoaStatusNames, status)
+}
+
+// EpsStatusName returns the name of the eps delivery status
+func EpsStatusName(status int) string {
+	return statusName(epsStatusNames, status)
+}
+
+// ParseDepositStatus returns the B2TxStatus of the name or number
+func ParseDepositStatus(name string) (int, error) {
+	return parseStatus(depositStatusNames, model.DepositEventStatusB2Tx, name)
+}
+
+// ParseDepositEoaStatus returns the B2EoaTxStatus of the name or number
+func ParseDepositEoaStatus(name string) (int, error) {
+	return parseStatus(depositEoaStatusNames, model.DepositEventStatusB2EoaTx, name)
+}
+
+func parseStatus(names map[int]string, statusType string, name string) (int, error) {
+	for status, v := range names {
+		if v == name || strconv.Itoa(status) == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s %s", ErrDepositUnknownStatus, statusType, name)
+}
+
+func statusName(names map[int]string, status int) string {
+	if name, ok := names[status]; ok {
+		return name
+	}
+	return strconv.Itoa(status)
+}
+
+// CheckDepositTransition checks the B2TxStatus transition is allowed, the same status is always allowed
+func CheckDepositTransition(from int, to int) error {
+	return checkTransition(depositTransitions, model.DepositEventStatusB2Tx, DepositStatusName, from, to)
+}
+
+// CheckDepositEoaTransition checks the B2EoaTxStatus transition is allowed, the same status is always allowed
+func CheckDepositEoaTransition(from int, to int) error {
+	return checkTransition(depositEoaTransitions, model.DepositEventStatusB2EoaTx, DepositEoaStatusName, from, to)
+}
+
+func checkTransition(transitions map[int][]int, statusType string, name func(int) string, from int, to int) error {
+	if from == to {
+		return nil
+	}
+	for _, status := range transitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s %s -> %s", ErrDepositInvalidTransition, statusType, name(from), name(to))
+}
+
+// DepositCreatedEvents returns the events of the new deposit
+func DepositCreatedEvents(deposit *model.Deposit, actor string, reason string) []model.DepositEvent {
+	return []model.DepositEvent{
+		newDepositEvent(deposit, model.DepositEventStatusB2Tx, model.DepositStatusNone, deposit.B2TxStatus, reason, actor),
+		newDepositEvent(deposit, model.DepositEventStatusB2EoaTx, model.DepositStatusNone, deposit.B2EoaTxStatus, "", actor),
+	}
+}
+
+// TransitDeposit locks the deposit, checks the precondition, validates the status transitions,
+// then updates the fields, records the deposit events and notifies in one db transaction.
+// An event is recorded when the status changed or the error is not empty.
+func TransitDeposit(
+	db *gorm.DB,
+	depositID int64,
+	check func(*model.Deposit) error,
+	fields map[string]interface{},
+	actor string,
+	errText string,
+) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var deposit model.Deposit
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deposit, depositID).Error
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(&deposit); err != nil {
+				return err
+			}
+		}
+
+		var events []model.DepositEvent
+		if to, ok := fields[model.Deposit{}.Column().B2TxStatus].(int); ok {
+			if err := CheckDepositTransition(deposit.B2TxStatus, to); err != nil {
+				return err
+			}
+			if to != deposit.B2TxStatus || errText != "" {
+				updated := deposit
+				if txHash, ok := fields[model.Deposit{}.Column().B2TxHash].(string); ok {
+					updated.B2TxHash = txHash
+				}
+				events = append(events, newDepositEvent(&updated, model.DepositEventStatusB2Tx, deposit.B2TxStatus, to, errText, actor))
+			}
+		}
+		if to, ok := fields[model.Deposit{}.Column().B2EoaTxStatus].(int); ok {
+			if err := CheckDepositEoaTransition(deposit.B2EoaTxStatus, to); err != nil {
+				return err
+			}
+			if to != deposit.B2EoaTxStatus {
+				updated := deposit
+				if txHash, ok := fields[model.Deposit{}.Column().B2EoaTxHash].(string); ok {
+					updated.B2EoaTxHash = txHash
+				}
+				events = append(events, newDepositEvent(&updated, model.DepositEventStatusB2EoaTx, deposit.B2EoaTxStatus, to, "", actor))
+			}
+		}
+
+		if err := tx.Model(&model.Deposit{}).Where("id = ?", depositID).Updates(fields).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := tx.Create(&events).Error; err != nil {
+			return err
+		}
+		// the status changed, wake up the subscribers once committed, eg. eps
+		return NotifyDeposit(tx, depositID)
+	})
+}
+
+// EpsDeliveredEvent returns the event of the eps delivered to the eps endpoint
+func EpsDeliveredEvent(eps *model.Eps, btcTxHash string) model.DepositEvent {
+	return model.DepositEvent{
+		DepositID:  eps.DepositID,
+		BtcTxHash:  btcTxHash,
+		StatusType: model.DepositEventStatusEps,
+		FromStatus: eps.Status,
+		ToStatus:   model.EspStatusSuccess,
+		FromState:  EpsStatusName(eps.Status),
+		ToState:    EpsStatusName(model.EspStatusSuccess),
+		TxHash:     eps.B2TxHash,
+		Actor:      DepositActorEps,
+	}
+}
+
+func newDepositEvent(deposit *model.Deposit, statusType string, from int, to int, errText string, actor string) model.DepositEvent {
+	event := model.DepositEvent{
+		DepositID:  deposit.ID,
+		BtcTxHash:  deposit.BtcTxHash,
+		StatusType: statusType,
+		FromStatus: from,
+		ToStatus:   to,
+		Error:      errText,
+		Actor:      actor,
+	}
+	if statusType == model.DepositEventStatusB2EoaTx {
+		event.FromState = DepositEoaStatusName(from)
+		event.ToState = DepositEoaStatusName(to)
+		event.TxHash = deposit.B2EoaTxHash
+	} else {
+		event.FromState = DepositStatusName(from)
+		event.ToState = DepositStatusName(to)
+		event.TxHash = deposit.B2TxHash
+	}
+	return event
+}
@@ -0,0 +1,135 @@
+package bitcoin_test
+
+import (
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDepositTransition(t *testing.T) {
+	testCases := []struct {
+		name  string
+		from  int
+		to    int
+		valid bool
+	}{
+		{"created pending", model.DepositStatusNone, model.DepositB2TxStatusPending, true},
+		{"created rejected", model.DepositStatusNone, model.DepositB2TxStatusRejected, true},
+		{"created success", model.DepositStatusNone, model.DepositB2TxStatusSuccess, false},
+		{"pending retry", model.DepositB2TxStatusPending, model.DepositB2TxStatusPending, true},
+		{"pending in-flight", model.DepositB2TxStatusPending, model.DepositB2TxStatusInFlight, true},
+		{"pending throttled", model.DepositB2TxStatusPending, model.DepositB2TxStatusThrottled, true},
+		{"pending approval", model.DepositB2TxStatusPending, model.DepositB2TxStatusPendingApproval, true},
+		{"pending amount inexact", model.DepositB2TxStatusPending, model.DepositB2TxStatusAmountInexact, true},
+		{"pending success without tx", model.DepositB2TxStatusPending, model.DepositB2TxStatusSuccess, false},
+		{"pending wait mined status failed without tx", model.DepositB2TxStatusPending, model.DepositB2TxStatusWaitMinedStatusFailed, false},
+		{"pending deadline exceeded without tx", model.DepositB2TxStatusPending, model.DepositB2TxStatusContextDeadlineExceeded, false},
+		{"pending rejected by policy", model.DepositB2TxStatusPending, model.DepositB2TxStatusRejected, false},
+		{"pending approval rejected without review", model.DepositB2TxStatusPending, model.DepositB2TxStatusApprovalRejected, false},
+		{"insufficient balance pending", model.DepositB2TxStatusInsufficientBalance, model.DepositB2TxStatusPending, true},
+		{"failed in-flight", model.DepositB2TxStatusFailed, model.DepositB2TxStatusInFlight, true},
+		{"throttled in-flight", model.DepositB2TxStatusThrottled, model.DepositB2TxStatusInFlight, true},
+		{"throttled success", model.DepositB2TxStatusThrottled, model.DepositB2TxStatusSuccess, false},
+		{"hold released", model.DepositB2TxStatusComplianceHold, model.DepositB2TxStatusPending, true},
+		{"hold success", model.DepositB2TxStatusComplianceHold, model.DepositB2TxStatusSuccess, false},
+		{"hold in-flight", model.DepositB2TxStatusComplianceHold, model.DepositB2TxStatusInFlight, false},
+		{"approval approved", model.DepositB2TxStatusPendingApproval, model.DepositB2TxStatusPending, true},
+		{"approval rejected", model.DepositB2TxStatusPendingApproval, model.DepositB2TxStatusApprovalRejected, true},
+		{"approval in-flight", model.DepositB2TxStatusPendingApproval, model.DepositB2TxStatusInFlight, false},
+		{"in-flight mined", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusSuccess, true},
+		{"in-flight dropped", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusDropped, true},
+		{"in-flight pending", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusPending, false},
+		{"dropped in-flight", model.DepositB2TxStatusDropped, model.DepositB2TxStatusInFlight, true},
+		{"dropped throttled", model.DepositB2TxStatusDropped, model.DepositB2TxStatusThrottled, true},
+		{"dropped success without tx", model.DepositB2TxStatusDropped, model.DepositB2TxStatusSuccess, false},
+		{"in-flight receipt failed", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusWaitMinedStatusFailed, true},
+		{"in-flight not mined in time", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusContextDeadlineExceeded, true},
+		{"in-flight failed", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusFailed, false},
+		{"in-flight throttled", model.DepositB2TxStatusInFlight, model.DepositB2TxStatusThrottled, false},
+		{"success is final", model.DepositB2TxStatusSuccess, model.DepositB2TxStatusPending, false},
+		{"success not dropped", model.DepositB2TxStatusSuccess, model.DepositB2TxStatusDropped, false},
+		{"success in-flight", model.DepositB2TxStatusSuccess, model.DepositB2TxStatusInFlight, false},
+		{"rejected is final", model.DepositB2TxStatusRejected, model.DepositB2TxStatusPending, false},
+		{"approval rejected is final", model.DepositB2TxStatusApprovalRejected, model.DepositB2TxStatusPending, false},
+		{"amount inexact is final", model.DepositB2TxStatusAmountInexact, model.DepositB2TxStatusPending, false},
+		{"deadline exceeded is final", model.DepositB2TxStatusContextDeadlineExceeded, model.DepositB2TxStatusPending, false},
+		{"wait mined status failed is final", model.DepositB2TxStatusWaitMinedStatusFailed, model.DepositB2TxStatusPending, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := bitcoin.CheckDepositTransition(tc.from, tc.to)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, bitcoin.ErrDepositInvalidTransition)
+			}
+		})
+	}
+
+	err := bitcoin.CheckDepositTransition(model.DepositB2TxStatusSuccess, model.DepositB2TxStatusPending)
+	require.EqualError(t, err, "invalid deposit status transition: b2_tx success -> pending")
+	err = bitcoin.CheckDepositTransition(model.DepositB2TxStatusInFlight, model.DepositB2TxStatusPending)
+	require.EqualError(t, err, "invalid deposit status transition: b2_tx in_flight -> pending")
+}
+
+func TestCheckDepositEoaTransition(t *testing.T) {
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusPending, model.DepositB2EoaTxStatusPendingApproval))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusPendingApproval, model.DepositB2EoaTxStatusApproved))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusApproved, model.DepositB2EoaTxStatusInFlight))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusPending, model.DepositB2EoaTxStatusQueued))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusQueued, model.DepositB2EoaTxStatusInFlight))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusInFlight, model.DepositB2EoaTxStatusSuccess))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusInFlight, model.DepositB2EoaTxStatusWaitMinedFailed))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusInFlight, model.DepositB2EoaTxStatusDropped))
+	require.NoError(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusDropped, model.DepositB2EoaTxStatusInFlight))
+	// the transfer is never sent without persisted in-flight, the in-flight transfer is never sent again
+	require.ErrorIs(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusQueued, model.DepositB2EoaTxStatusSuccess),
+		bitcoin.ErrDepositInvalidTransition)
+	require.ErrorIs(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusApproved, model.DepositB2EoaTxStatusSuccess),
+		bitcoin.ErrDepositInvalidTransition)
+	require.ErrorIs(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusInFlight, model.DepositB2EoaTxStatusQueued),
+		bitcoin.ErrDepositInvalidTransition)
+	require.ErrorIs(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusQueued, model.DepositB2EoaTxStatusPendingApproval),
+		bitcoin.ErrDepositInvalidTransition)
+	require.ErrorIs(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusPending, model.DepositB2EoaTxStatusApproved),
+		bitcoin.ErrDepositInvalidTransition)
+	require.ErrorIs(t, bitcoin.CheckDepositEoaTransition(model.DepositB2EoaTxStatusRejected, model.DepositB2EoaTxStatusApproved),
+		bitcoin.ErrDepositInvalidTransition)
+}
+
+func TestDepositCreatedEvents(t *testing.T) {
+	deposit := model.Deposit{
+		ID:            1,
+		BtcTxHash:     "c0f4ba2a0f9fa8b3ad5fc0b0ad80aa4ab7e9e8b3e8f2fca26de0c1c8a5fbe9b8",
+		B2TxStatus:    model.DepositB2TxStatusRejected,
+		B2EoaTxStatus: model.DepositB2EoaTxStatusPending,
+	}
+	events := bitcoin.DepositCreatedEvents(&deposit, bitcoin.DepositActorIndexer, "amount 1 below min deposit 1000")
+	require.Len(t, events, 2)
+	require.Equal(t, model.DepositEventStatusB2Tx, events[0].StatusType)
+	require.Equal(t, "none", events[0].FromState)
+	require.Equal(t, "rejected", events[0].ToState)
+	require.Equal(t, "amount 1 below min deposit 1000", events[0].Error)
+	require.Equal(t, bitcoin.DepositActorIndexer, events[0].Actor)
+	require.Equal(t, model.DepositEventStatusB2EoaTx, events[1].StatusType)
+	require.Equal(t, "pending", events[1].ToState)
+}
+
+func TestParseDepositStatus(t *testing.T) {
+	status, err := bitcoin.ParseDepositStatus("in_flight")
+	require.NoError(t, err)
+	require.Equal(t, model.DepositB2TxStatusInFlight, status)
+
+	status, err = bitcoin.ParseDepositStatus("0")
+	require.NoError(t, err)
+	require.Equal(t, model.DepositB2TxStatusSuccess, status)
+
+	status, err = bitcoin.ParseDepositEoaStatus("policy_skipped")
+	require.NoError(t, err)
+	require.Equal(t, model.DepositB2EoaTxStatusPolicySkipped, status)
+
+	_, err = bitcoin.ParseDepositStatus("unknown")
+	require.ErrorIs(t, err, bitcoin.ErrDepositUnknownStatus)
+}
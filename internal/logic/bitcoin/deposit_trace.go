@@ -0,0 +1,43 @@
+package bitcoin
+
+import (
+	"context"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/tracing"
+	"github.com/b2network/b2-indexer/internal/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartDepositTrace starts the trace of the parsed deposit, the block parsing is recorded as its first step.
+// The trace context is persisted on the deposit, so that the later services continue the same trace.
+func StartDepositTrace(
+	parseResult *types.BitcoinTxParseResult,
+	btcBlockNumber int64,
+	parseStart time.Time,
+	parseEnd time.Time,
+) (context.Context, trace.Span) {
+	attrs := trace.WithAttributes(
+		attribute.String(tracing.AttrBtcTxHash, parseResult.TxID),
+		attribute.Int64(tracing.AttrBtcBlockNumber, btcBlockNumber),
+	)
+	ctx, span := tracing.Start(context.Background(), "deposit", trace.WithTimestamp(parseStart), attrs)
+	_, parseSpan := tracing.Start(ctx, "bitcoin.parse_block", trace.WithTimestamp(parseStart), attrs)
+	parseSpan.End(trace.WithTimestamp(parseEnd))
+	return ctx, span
+}
+
+// StartDepositSpan starts the span continues the deposit trace.
+// A deposit indexed without trace context starts its trace here, the context is persisted by the next save.
+func StartDepositSpan(deposit *model.Deposit, name string) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(tracing.Extract(deposit.TraceParent), name, trace.WithAttributes(
+		attribute.Int64(tracing.AttrDepositID, deposit.ID),
+		attribute.String(tracing.AttrBtcTxHash, deposit.BtcTxHash),
+	))
+	if deposit.TraceParent == "" {
+		deposit.TraceParent = tracing.Inject(ctx)
+	}
+	return ctx, span
+}
@@ -0,0 +1,63 @@
+package bitcoin_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/tracing"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestDepositTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(sdktrace.NewSimpleSpanProcessor(exporter), 1)
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	// the indexer starts the trace, the block parsing is the first step
+	parseStart := time.Now().Add(-time.Second)
+	parseEnd := parseStart.Add(500 * time.Millisecond)
+	ctx, span := bitcoin.StartDepositTrace(&types.BitcoinTxParseResult{TxID: "btc_tx_hash"}, 100, parseStart, parseEnd)
+	_, saveSpan := tracing.Start(ctx, "db.save_deposit")
+	saveSpan.End()
+	span.End()
+	deposit := model.Deposit{ID: 1, BtcTxHash: "btc_tx_hash", TraceParent: tracing.Inject(ctx)}
+
+	// the bridge continues the persisted trace
+	_, bridgeSpan := bitcoin.StartDepositSpan(&deposit, "bridge.handle_deposit")
+	bridgeSpan.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		require.Equal(t, span.SpanContext().TraceID(), s.SpanContext.TraceID())
+		byName[s.Name] = s
+	}
+	require.Equal(t, parseStart.UnixNano(), byName["deposit"].StartTime.UnixNano())
+	require.Equal(t, parseStart.UnixNano(), byName["bitcoin.parse_block"].StartTime.UnixNano())
+	require.Equal(t, parseEnd.UnixNano(), byName["bitcoin.parse_block"].EndTime.UnixNano())
+	for _, name := range []string{"bitcoin.parse_block", "db.save_deposit", "bridge.handle_deposit"} {
+		require.Equal(t, span.SpanContext().SpanID(), byName[name].Parent.SpanID(), name)
+	}
+
+	// the deposit indexed without trace context starts its trace, the context is persisted on the deposit
+	exporter.Reset()
+	deposit = model.Deposit{ID: 2, BtcTxHash: "btc_tx_hash_2"}
+	ctx, span = bitcoin.StartDepositSpan(&deposit, "bridge.handle_deposit")
+	span.End()
+	require.Equal(t, tracing.Inject(ctx), deposit.TraceParent)
+	require.Len(t, exporter.GetSpans(), 1)
+	require.False(t, exporter.GetSpans()[0].Parent.IsValid())
+}
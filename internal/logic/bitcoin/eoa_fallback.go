@@ -0,0 +1,139 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// eoa transfer fallback policy
+	EoaFallbackPolicyDisabled  = "disabled"  // never eoa transfer
+	EoaFallbackPolicyThreshold = "threshold" // eoa transfer only below the max amount
+	EoaFallbackPolicyManual    = "manual"    // eoa transfer after operator approval
+
+	EoaFallbackDailyWindow = 24 * time.Hour
+)
+
+var (
+	ErrEoaFallbackUnknownPolicy = errors.New("unknown eoa fallback policy")
+	ErrEoaFallbackNotPending    = errors.New("eoa fallback is not pending approval")
+)
+
+// EoaFallbackPolicy decides whether a failed deposit can be bridged by eoa transfer
+type EoaFallbackPolicy struct {
+	Policy string
+	// MaxAmount and DailyLimit are in satoshis
+	MaxAmount  int64
+	DailyLimit int64
+}
+
+// NewEoaFallbackPolicy new eoa fallback policy by bridge config
+func NewEoaFallbackPolicy(bridgeCfg config.BridgeConfig) (*EoaFallbackPolicy, error) {
+	policy := bridgeCfg.EoaFallbackPolicy
+	if policy == "" {
+		policy = EoaFallbackPolicyDisabled
+	}
+	switch policy {
+	case EoaFallbackPolicyDisabled, EoaFallbackPolicyThreshold, EoaFallbackPolicyManual:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrEoaFallbackUnknownPolicy, policy)
+	}
+	if policy == EoaFallbackPolicyThreshold && bridgeCfg.EoaFallbackMaxAmount <= 0 {
+		return nil, fmt.Errorf("eoa fallback threshold policy requires max amount")
+	}
+	return &EoaFallbackPolicy{
+		Policy:     policy,
+		MaxAmount:  bridgeCfg.EoaFallbackMaxAmount,
+		DailyLimit: bridgeCfg.EoaFallbackDailyLimit,
+	}, nil
+}
+
+// Decide returns the fallback action and reason for the deposit amount.
+// model.EoaFallbackActionQueued allows the eoa worker to transfer, it waits until the daily limit is available.
+func (p *EoaFallbackPolicy) Decide(amount int64) (string, string) {
+	switch p.Policy {
+	case EoaFallbackPolicyThreshold:
+		if amount >= p.MaxAmount {
+			return model.EoaFallbackActionSkipped, fmt.Sprintf("amount %d exceeds threshold %d", amount, p.MaxAmount)
+		}
+		return model.EoaFallbackActionQueued, ""
+	case EoaFallbackPolicyManual:
+		return model.EoaFallbackActionPendingApproval, "wait operator approval"
+	default:
+		return model.EoaFallbackActionSkipped, "eoa fallback disabled"
+	}
+}
+
+// CheckDailyLimit check the transfer amount does not exceed the daily limit
+func (p *EoaFallbackPolicy) CheckDailyLimit(amount *big.Int, dailyUsed *big.Int) (string, bool) {
+	if p.DailyLimit <= 0 {
+		return "", true
+	}
+	if new(big.Int).Add(dailyUsed, amount).Cmp(big.NewInt(p.DailyLimit)) > 0 {
+		return fmt.Sprintf("daily limit %d exceeded, used %s", p.DailyLimit, dailyUsed), false
+	}
+	return "", true
+}
+
+// EoaFallbackDailyUsed returns the net amount transferred by eoa in the last 24 hours,
+// the in-flight transfers are counted, the dropped and failed transfers moved no funds
+func EoaFallbackDailyUsed(db *gorm.DB) (*big.Int, error) {
+	var result struct {
+		Amount model.BigInt
+	}
+	err := db.Model(&model.Deposit{}).
+		Select(fmt.Sprintf("COALESCE(SUM(%s), 0) AS amount", model.Deposit{}.Column().NetValue)).
+		Where(fmt.Sprintf("%s IN (?) AND %s > ?", model.Deposit{}.Column().B2EoaTxStatus, model.Deposit{}.Column().B2EoaTxSubmittedAt),
+			[]int{model.DepositB2EoaTxStatusSuccess, model.DepositB2EoaTxStatusInFlight}, time.Now().Add(-EoaFallbackDailyWindow)).
+		Scan(&result).Error
+	return result.Amount.BigInt(), err
+}
+
+// ApproveEoaFallback operator approve the pending eoa transfer of the deposit
+func ApproveEoaFallback(db *gorm.DB, depositID int64, operator string, reason string) error {
+	return reviewEoaFallback(db, depositID, operator, reason,
+		model.DepositB2EoaTxStatusApproved, model.EoaFallbackActionApproved)
+}
+
+// RejectEoaFallback operator reject the pending eoa transfer of the deposit
+func RejectEoaFallback(db *gorm.DB, depositID int64, operator string, reason string) error {
+	return reviewEoaFallback(db, depositID, operator, reason,
+		model.DepositB2EoaTxStatusRejected, model.EoaFallbackActionRejected)
+}
+
+func reviewEoaFallback(db *gorm.DB, depositID int64, operator string, reason string, status int, action string) error {
+	if operator == "" {
+		return fmt.Errorf("operator is empty")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var deposit model.Deposit
+		err := TransitDeposit(tx, depositID, func(d *model.Deposit) error {
+			if d.B2EoaTxStatus != model.DepositB2EoaTxStatusPendingApproval {
+				return ErrEoaFallbackNotPending
+			}
+			deposit = *d
+			return nil
+		}, map[string]interface{}{
+			model.Deposit{}.Column().B2EoaTxStatus: status,
+		}, operator, reason)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.EoaFallback{
+			DepositID: deposit.ID,
+			BtcTxHash: deposit.BtcTxHash,
+			Policy:    EoaFallbackPolicyManual,
+			Action:    action,
+			Amount:    deposit.BtcValue,
+			ToAddress: deposit.BtcFromAAAddress,
+			Operator:  operator,
+			Reason:    reason,
+		}).Error
+	})
+}
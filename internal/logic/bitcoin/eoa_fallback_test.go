@@ -0,0 +1,116 @@
+package bitcoin_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEoaFallbackPolicy(t *testing.T) {
+	policy, err := bitcoin.NewEoaFallbackPolicy(config.BridgeConfig{})
+	require.NoError(t, err)
+	require.Equal(t, bitcoin.EoaFallbackPolicyDisabled, policy.Policy)
+
+	_, err = bitcoin.NewEoaFallbackPolicy(config.BridgeConfig{EoaFallbackPolicy: "always"})
+	require.ErrorIs(t, err, bitcoin.ErrEoaFallbackUnknownPolicy)
+
+	_, err = bitcoin.NewEoaFallbackPolicy(config.BridgeConfig{EoaFallbackPolicy: bitcoin.EoaFallbackPolicyThreshold})
+	require.Error(t, err)
+}
+
+func TestEoaFallbackPolicyDecide(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy bitcoin.EoaFallbackPolicy
+		amount int64
+		action string
+	}{
+		{
+			name:   "disabled",
+			policy: bitcoin.EoaFallbackPolicy{Policy: bitcoin.EoaFallbackPolicyDisabled},
+			amount: 1000,
+			action: model.EoaFallbackActionSkipped,
+		},
+		{
+			name:   "threshold: below max amount",
+			policy: bitcoin.EoaFallbackPolicy{Policy: bitcoin.EoaFallbackPolicyThreshold, MaxAmount: 10000},
+			amount: 9999,
+			action: model.EoaFallbackActionQueued,
+		},
+		{
+			name:   "threshold: reach max amount",
+			policy: bitcoin.EoaFallbackPolicy{Policy: bitcoin.EoaFallbackPolicyThreshold, MaxAmount: 10000},
+			amount: 10000,
+			action: model.EoaFallbackActionSkipped,
+		},
+		{
+			name:   "threshold: daily limit queued",
+			policy: bitcoin.EoaFallbackPolicy{Policy: bitcoin.EoaFallbackPolicyThreshold, MaxAmount: 10000, DailyLimit: 5000},
+			amount: 8000,
+			action: model.EoaFallbackActionQueued,
+		},
+		{
+			name:   "manual",
+			policy: bitcoin.EoaFallbackPolicy{Policy: bitcoin.EoaFallbackPolicyManual},
+			amount: 1000,
+			action: model.EoaFallbackActionPendingApproval,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			action, _ := tc.policy.Decide(tc.amount)
+			require.Equal(t, tc.action, action)
+		})
+	}
+}
+
+func TestEoaFallbackPolicyCheckDailyLimit(t *testing.T) {
+	testCases := []struct {
+		name      string
+		limit     int64
+		amount    *big.Int
+		dailyUsed *big.Int
+		ok        bool
+	}{
+		{
+			name:      "unlimited",
+			amount:    big.NewInt(5000),
+			dailyUsed: big.NewInt(16000),
+			ok:        true,
+		},
+		{
+			name:      "exceeded",
+			limit:     20000,
+			amount:    big.NewInt(5000),
+			dailyUsed: big.NewInt(16000),
+			ok:        false,
+		},
+		{
+			name:      "reach limit",
+			limit:     20000,
+			amount:    big.NewInt(4000),
+			dailyUsed: big.NewInt(16000),
+			ok:        true,
+		},
+		{
+			name:      "used beyond int64",
+			limit:     20000,
+			amount:    big.NewInt(1),
+			dailyUsed: new(big.Int).Lsh(big.NewInt(1), 64),
+			ok:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := bitcoin.EoaFallbackPolicy{Policy: bitcoin.EoaFallbackPolicyThreshold, DailyLimit: tc.limit}
+			_, ok := policy.CheckDailyLimit(tc.amount, tc.dailyUsed)
+			require.Equal(t, tc.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// EpsResponseMaxLen the max stored length of the eps response body
+	EpsResponseMaxLen = 4096
+	EpsDeliverLimit   = 100
+)
+
+// EpsRetryBackoff eps delivery backoff by attempts
+var EpsRetryBackoff = RetryBackoff{Base: 30 * time.Second, Max: time.Hour}
+
+var ErrEpsNotRedeliverable = errors.New("eps is not dead letter")
+
+// EpsIdempotencyKey the idempotency key of the deposit eps delivery, the same for every attempt
+func EpsIdempotencyKey(depositID int64) string {
+	return fmt.Sprintf("b2-indexer-eps-%d", depositID)
+}
+
+// EpsRetry returns the delay before the next attempt, false if the delivery is dead letter after the attempts
+func EpsRetry(attempts int, maxAttempts int64) (time.Duration, bool) {
+	return EpsRetryBackoff.Next(attempts, maxAttempts)
+}
+
+// SaveEpsDelivery records the delivery attempt to the delivery log and the eps outbox
+func SaveEpsDelivery(db *gorm.DB, eps *model.Eps, delivery *model.EpsDelivery, maxAttempts int64) error {
+	now := time.Now()
+	fields := map[string]interface{}{
+		model.Eps{}.Column().IdempotencyKey: delivery.IdempotencyKey,
+		model.Eps{}.Column().Attempts:       delivery.Attempt,
+		model.Eps{}.Column().LastStatusCode: delivery.StatusCode,
+		model.Eps{}.Column().LastResponse:   delivery.Response,
+		model.Eps{}.Column().LastError:      delivery.Error,
+	}
+	if delivery.Error == "" {
+		fields[model.Eps{}.Column().Status] = model.EspStatusSuccess
+		fields[model.Eps{}.Column().DeliveredAt] = now
+	} else if delay, ok := EpsRetry(delivery.Attempt, maxAttempts); ok {
+		fields[model.Eps{}.Column().NextAttemptAt] = now.Add(delay)
+	} else {
+		fields[model.Eps{}.Column().Status] = model.EspStatusDeadLetter
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&model.Eps{}).
+			Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().ID), eps.ID).
+			Updates(fields).Error
+		if err != nil || delivery.Error != "" {
+			return err
+		}
+		// the delivery is the last stage of the deposit lifecycle, recorded with the status transitions
+		var deposits []model.Deposit
+		err = tx.Select(model.Deposit{}.Column().BtcTxHash).
+			Where("id = ?", eps.DepositID).
+			Limit(1).
+			Find(&deposits).Error
+		if err != nil {
+			return err
+		}
+		event := EpsDeliveredEvent(eps, "")
+		if len(deposits) > 0 {
+			event.BtcTxHash = deposits[0].BtcTxHash
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		return NotifyDeposit(tx, eps.DepositID)
+	})
+}
+
+// RedeliverEps operator redeliver the dead letter eps, the attempts restart
+func RedeliverEps(db *gorm.DB, id int64) error {
+	result := db.Model(&model.Eps{}).
+		Where(fmt.Sprintf("%s = ? AND %s = ?", model.Eps{}.Column().ID, model.Eps{}.Column().Status), id, model.EspStatusDeadLetter).
+		Updates(map[string]interface{}{
+			model.Eps{}.Column().Status:        model.EspStatus,
+			model.Eps{}.Column().Attempts:      0,
+			model.Eps{}.Column().NextAttemptAt: time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEpsNotRedeliverable
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package bitcoin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpsRetry(t *testing.T) {
+	delay, ok := bitcoin.EpsRetry(1, 3)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, delay)
+
+	delay, ok = bitcoin.EpsRetry(2, 3)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, delay)
+
+	_, ok = bitcoin.EpsRetry(3, 3)
+	require.False(t, ok)
+
+	// unlimited attempts
+	delay, ok = bitcoin.EpsRetry(100, 0)
+	require.True(t, ok)
+	require.Equal(t, time.Hour, delay)
+}
+
+func TestEpsIdempotencyKey(t *testing.T) {
+	require.Equal(t, bitcoin.EpsIdempotencyKey(1), bitcoin.EpsIdempotencyKey(1))
+	require.NotEqual(t, bitcoin.EpsIdempotencyKey(1), bitcoin.EpsIdempotencyKey(2))
+}
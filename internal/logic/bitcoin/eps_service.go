@@ -6,21 +6,43 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/internal/metrics"
 	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/tracing"
 	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
+const (
+	EpsServiceName = "BitcoinEpsService"
+	// the heartbeat names of the ingest and deliver loops
+	EpsIngestHeartbeat  = EpsServiceName + "/ingest"
+	EpsDeliverHeartbeat = EpsServiceName + "/deliver"
+
+	EpsCursorName = "eps"
+	// re-scan the deposits committed late by concurrent transactions
+	EpsCursorLookback = time.Minute
+	EpsBatchLimit     = 100
+)
+
 // EpsService eps service
 type EpsService struct {
-	EthRPCURL string
-	config    config.EpsConfig
-	log       log.Logger
-	db        *gorm.DB
+	EthRPCURL       string
+	ContractAddress string
+	config          config.EpsConfig
+	log             log.Logger
+	db              *gorm.DB
+	listener        *DepositListener
 }
 
 type DepositData struct {
@@ -40,31 +62,62 @@ type EpsResponse struct {
 }
 
 type EthRequest struct {
-	Jsonrpc string   `json:"jsonrpc"`
-	Method  string   `json:"method"`
-	Params  []string `json:"params"`
-	ID      int      `json:"id"`
+	Jsonrpc string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
 }
 
 type EthResponse struct {
-	Jsonrpc string                 `json:"jsonrpc"`
-	ID      int                    `json:"id"`
-	Result  EthTransactionResponse `json:"result"`
+	Jsonrpc string            `json:"jsonrpc"`
+	ID      int               `json:"id"`
+	Result  json.RawMessage   `json:"result"`
+	Error   *EthResponseError `json:"error"`
+}
+
+type EthResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type EthReceiptResponse struct {
+	BlockHash        string           `json:"blockHash"`
+	BlockNumber      string           `json:"blockNumber"`
+	From             string           `json:"from"`
+	To               string           `json:"to"`
+	TransactionIndex string           `json:"transactionIndex"`
+	GasUsed          string           `json:"gasUsed"`
+	Status           string           `json:"status"`
+	Logs             []EthLogResponse `json:"logs"`
+}
+
+type EthLogResponse struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex string   `json:"logIndex"`
 }
 
-type EthTransactionResponse struct {
-	BlockNumber      string `json:"blockNumber"`
-	From             string `json:"from"`
-	To               string `json:"to"`
-	TransactionIndex string `json:"transactionIndex"`
-	Value            string `json:"value"`
+type EthBlockResponse struct {
+	Hash      string `json:"hash"`
+	Number    string `json:"number"`
+	Timestamp string `json:"timestamp"`
 }
 
+// DepositEventTopic the bridge contract DepositEvent(caller, to_address, amount) topic
+var DepositEventTopic = crypto.Keccak256Hash([]byte("DepositEvent(address,address,uint256)"))
+
+var (
+	ErrEpsNotFound             = errors.New("not found")
+	ErrEpsDepositEventNotFound = errors.New("deposit event log not found")
+)
+
 // NewEpsService new eps
 func NewEpsService(
 	bridgeCfg config.BridgeConfig,
 	config config.EpsConfig,
 	log log.Logger,
+	listener *DepositListener,
 	db *gorm.DB,
 ) (*EpsService, error) {
 	rpcURL, err := url.ParseRequestURI(bridgeCfg.EthRPCURL)
@@ -72,152 +125,419 @@ func NewEpsService(
 		return nil, err
 	}
 	return &EpsService{
-		EthRPCURL: rpcURL.String(),
-		config:    config,
-		log:       log,
-		db:        db,
+		EthRPCURL:       rpcURL.String(),
+		ContractAddress: bridgeCfg.ContractAddress,
+		config:          config,
+		log:             log,
+		db:              db,
+		listener:        listener,
 	}, nil
 }
 
 func (e *EpsService) OnStart() error {
-	if !e.db.Migrator().HasTable(&model.Eps{}) {
-		err := e.db.AutoMigrate(&model.Eps{})
+	// auto migrate also updates the existing table columns
+	err := e.db.AutoMigrate(&model.Eps{})
+	if err != nil {
+		e.log.Errorw("eps migrate table", "error", err.Error())
+		return err
+	}
+	if !e.db.Migrator().HasTable(&model.EpsDelivery{}) {
+		err = e.db.AutoMigrate(&model.EpsDelivery{})
+		if err != nil {
+			e.log.Errorw("eps create table", "error", err.Error())
+			return err
+		}
+	}
+	if !e.db.Migrator().HasTable(&model.SyncCursor{}) {
+		err = e.db.AutoMigrate(&model.SyncCursor{})
 		if err != nil {
 			e.log.Errorw("eps create table", "error", err.Error())
 			return err
 		}
 	}
+	// the ingestion scans the deposits in cursor order
+	if !e.db.Migrator().HasIndex(&model.Deposit{}, model.DepositCursorIndex) {
+		err = e.db.Migrator().CreateIndex(&model.Deposit{}, model.DepositCursorIndex)
+		if err != nil {
+			e.log.Errorw("eps create index", "error", err.Error())
+			return err
+		}
+	}
+	// the first heartbeats signal the loops started
+	health.Beat(EpsIngestHeartbeat)
+	health.Beat(EpsDeliverHeartbeat)
 	go func() {
+		wakeup := e.listener.Subscribe()
 		for {
-			time.Sleep(time.Minute)
-			var depositList []model.Deposit
-			err := e.db.Model(&model.Deposit{}).Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().B2EoaTxStatus), model.DepositB2EoaTxStatusSuccess).Find(&depositList).Error
-			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					continue
-				}
-				e.log.Errorw("eps get list err", "error", err)
-				continue
-			}
-			for _, v := range depositList {
-				var eps model.Eps
-				err := e.db.Model(&model.Eps{}).Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().DepositID), v.ID).First(&eps).Error
-				if err != nil {
-					if errors.Is(err, gorm.ErrRecordNotFound) {
-						// insert into eps table
-						txData, err := e.GetTransactionByHash(v.B2EoaTxHash)
-						if err != nil {
-							e.log.Errorw("eps GetTransactionByHash err", "error", err)
-							continue
-						}
-						blockNumber, err := strconv.ParseInt(txData.BlockNumber, 0, 0)
-						if err != nil {
-							e.log.Errorw("eps strconv blockNumber err", "error", err)
-							continue
-						}
-						transactionIndex, err := strconv.ParseInt(txData.TransactionIndex, 0, 0)
-						if err != nil {
-							e.log.Errorw("eps strconv blockNumber err", "error", err)
-							continue
-						}
-						esp := model.Eps{
-							DepositID:          v.ID,
-							B2From:             txData.From,
-							B2To:               txData.To,
-							BtcValue:           v.BtcValue,
-							B2TxHash:           v.B2TxHash,
-							B2TxTime:           v.UpdatedAt,
-							B2BlockNumber:      blockNumber,
-							B2TransactionIndex: transactionIndex,
-						}
-						err = e.db.Model(&model.Eps{}).Save(&esp).Error
-						if err != nil {
-							e.log.Errorw("insert into eps table err", "error", err)
-							continue
-						}
-					} else {
-						e.log.Errorw("eps get by deposit_id err", "error", err, "deposit_id", v.ID)
-					}
-				}
+			e.wait(wakeup)
+			health.Beat(EpsIngestHeartbeat)
+			if err := e.Ingest(); err != nil {
+				e.log.Errorw("eps ingest err", "error", err)
 			}
 		}
 	}()
 
+	wakeup := e.listener.Subscribe()
 	for {
-		time.Sleep(time.Minute)
-		var epsList []model.Eps
-		result := e.db.Model(&model.Eps{}).Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().Status), model.EspStatus).Find(&epsList)
-		if result.Error != nil {
-			e.log.Errorw("eps get list err", "error", result.Error)
-			continue
+		e.wait(wakeup)
+		health.Beat(EpsDeliverHeartbeat)
+		if err := e.deliverDue(); err != nil {
+			e.log.Errorw("eps deliver err", "error", err)
 		}
-		if result.RowsAffected <= 0 {
-			continue
+	}
+}
+
+// deliverDue delivers the pending eps due for the next attempt
+func (e *EpsService) deliverDue() error {
+	for {
+		var epsList []model.Eps
+		err := e.db.Model(&model.Eps{}).
+			Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().Status), model.EspStatus).
+			Where(fmt.Sprintf("%s IS NULL OR %s <= ?", model.Eps{}.Column().NextAttemptAt, model.Eps{}.Column().NextAttemptAt), time.Now()).
+			Order("id").
+			Limit(EpsDeliverLimit).
+			Find(&epsList).Error
+		if err != nil {
+			return err
 		}
 		for _, v := range epsList {
-			depositData := DepositData{
-				Caller:      v.B2From,
-				ToAddress:   v.B2To,
-				Amount:      strconv.FormatInt(v.BtcValue, 10),
-				Timestamp:   v.B2TxTime.Unix(),
-				BlockNumber: v.B2BlockNumber,
-				LogIndex:    0,
-				TxHash:      v.B2TxHash,
+			if err := e.deliver(v); err != nil {
+				e.log.Errorw("eps save delivery err", "error", err, "id", v.ID)
+				return err
 			}
-			err := e.Deposit(depositData)
+		}
+		if len(epsList) < EpsDeliverLimit {
+			return nil
+		}
+	}
+}
+
+// deliver posts the eps and records the attempt, failed deliveries back off until dead letter.
+// The delivery continues the deposit trace.
+func (e *EpsService) deliver(v model.Eps) error {
+	depositData := NewDepositData(v)
+	key := v.IdempotencyKey
+	if key == "" {
+		key = EpsIdempotencyKey(v.DepositID)
+	}
+	start := time.Now()
+	ctx, span := tracing.Start(tracing.Extract(v.TraceParent), "eps.deliver", trace.WithAttributes(
+		attribute.Int64(tracing.AttrEpsID, v.ID),
+		attribute.Int64(tracing.AttrDepositID, v.DepositID),
+		attribute.String(tracing.AttrB2TxHash, v.B2TxHash),
+		attribute.Int(tracing.AttrAttempt, v.Attempts+1),
+	))
+	statusCode, body, err := e.Deposit(depositData, key)
+	span.SetAttributes(attribute.Int("http.status_code", statusCode))
+	tracing.End(span, err)
+	metrics.ObserveRPC(metrics.RPCEps, "deposit", start, err)
+	metrics.ObserveEpsDelivery(err)
+	if len(body) > EpsResponseMaxLen {
+		body = body[:EpsResponseMaxLen]
+	}
+	delivery := model.EpsDelivery{
+		EpsID:          v.ID,
+		DepositID:      v.DepositID,
+		Attempt:        v.Attempts + 1,
+		IdempotencyKey: key,
+		StatusCode:     statusCode,
+		Response:       body,
+		Duration:       time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+		e.log.Errorw("eps deposit err", "error", err, "id", v.ID, "attempt", delivery.Attempt,
+			"traceID", tracing.TraceID(ctx))
+	} else {
+		e.log.Infow("post bridge deposit success", "depositData", depositData,
+			"traceID", tracing.TraceID(ctx))
+	}
+	return SaveEpsDelivery(e.db, &v, &delivery, e.config.MaxAttempts)
+}
+
+// NewDepositData returns the deposit data posted to eps, the amount is the net value minted after the bridge fee.
+// The eps added before the net value was recorded posts the btc value.
+func NewDepositData(v model.Eps) DepositData {
+	amount := v.NetValue
+	if amount.BigInt().Sign() == 0 {
+		amount = v.BtcValue
+	}
+	return DepositData{
+		Caller:      v.B2From,
+		ToAddress:   v.B2To,
+		Amount:      amount.String(),
+		Timestamp:   v.B2TxTime.Unix(),
+		BlockNumber: v.B2BlockNumber,
+		LogIndex:    v.LogIndex,
+		TxHash:      v.B2TxHash,
+	}
+}
+
+// Ingest adds the bridged deposits after the cursor to eps, both contract deposits and eoa transfers,
+// then re-ingests the failed deposits due for the next attempt.
+// The deposits already in eps are excluded by anti-join, the failed deposit is recorded as ingest failed
+// and the cursor moves past it.
+func (e *EpsService) Ingest() error {
+	cursor, err := LoadSyncCursor(e.db, EpsCursorName)
+	if err != nil {
+		return err
+	}
+	// the pages after the first start strictly after the last deposit
+	page, lookback := cursor, EpsCursorLookback
+	for {
+		var deposits []model.Deposit
+		err := e.db.Model(&model.Deposit{}).
+			Scopes(DepositsAfterCursor(page, lookback, EpsBatchLimit)).
+			Where(fmt.Sprintf("%s = ? OR %s = ?", model.Deposit{}.Column().B2TxStatus, model.Deposit{}.Column().B2EoaTxStatus),
+				model.DepositB2TxStatusSuccess, model.DepositB2EoaTxStatusSuccess).
+			Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s.id)",
+				model.Eps{}.TableName(), model.Eps{}.TableName(), model.Eps{}.Column().DepositID, model.Deposit{}.TableName())).
+			Find(&deposits).Error
+		if err != nil {
+			return err
+		}
+
+		for _, v := range deposits {
+			esp, err := e.newEps(v)
 			if err != nil {
-				e.log.Errorw("eps deposit err", "error", err)
-				continue
+				e.log.Errorw("eps add deposit err", "error", err, "deposit_id", v.ID)
+				esp = newIngestFailedEps(v, err)
+			}
+			if err := e.db.Create(&esp).Error; err != nil {
+				return err
+			}
+			AdvanceSyncCursor(&cursor, v.UpdatedAt, v.ID)
+		}
+		if err := SaveSyncCursor(e.db, &cursor); err != nil {
+			return err
+		}
+		if len(deposits) < EpsBatchLimit {
+			break
+		}
+		page.CursorTime, page.CursorID = deposits[len(deposits)-1].UpdatedAt, deposits[len(deposits)-1].ID
+		lookback = 0
+	}
+	return e.reingestDue()
+}
+
+// reingestDue re-ingests the failed deposits due for the next attempt, the eps is pending delivery once ingested
+func (e *EpsService) reingestDue() error {
+	for {
+		var epsList []model.Eps
+		err := e.db.Model(&model.Eps{}).
+			Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().Status), model.EspStatusIngestFailed).
+			Where(fmt.Sprintf("%s <= ?", model.Eps{}.Column().NextAttemptAt), time.Now()).
+			Order("id").
+			Limit(EpsBatchLimit).
+			Find(&epsList).Error
+		if err != nil {
+			return err
+		}
+		for _, v := range epsList {
+			var deposit model.Deposit
+			if err := e.db.First(&deposit, v.DepositID).Error; err != nil {
+				return err
 			}
-			e.log.Infow("post bridge deposit success", "depositData", depositData)
-			err = e.db.Model(&model.Eps{}).Where(fmt.Sprintf("%s = ?", model.Eps{}.Column().ID), v.ID).Update(model.Eps{}.Column().Status, model.EspStatusSuccess).Error
+			esp, err := e.newEps(deposit)
 			if err != nil {
-				e.log.Errorw("eps update eps table err", "error", err, "id", v.ID)
-				continue
+				e.log.Errorw("eps re-ingest deposit err", "error", err, "deposit_id", v.DepositID, "attempt", v.Attempts+1)
+				esp = newIngestFailedEps(deposit, err)
+				esp.Attempts = v.Attempts + 1
+				esp.NextAttemptAt = time.Now().Add(EpsRetryBackoff.Delay(esp.Attempts))
+			}
+			esp.ID = v.ID
+			esp.CreatedAt = v.CreatedAt
+			if err := e.db.Save(&esp).Error; err != nil {
+				return err
 			}
-			e.log.Infow("update deposit success", "id", v.ID, "tx_hash", v.B2TxHash)
 		}
+		if len(epsList) < EpsBatchLimit {
+			return nil
+		}
+	}
+}
+
+// newIngestFailedEps returns the ingest failed eps of the deposit, re-ingested after the backoff
+func newIngestFailedEps(v model.Deposit, err error) model.Eps {
+	return model.Eps{
+		DepositID:      v.ID,
+		BtcValue:       v.BtcValue,
+		NetValue:       DepositNetValue(v),
+		Status:         model.EspStatusIngestFailed,
+		IdempotencyKey: EpsIdempotencyKey(v.ID),
+		Attempts:       1,
+		NextAttemptAt:  time.Now().Add(EpsRetryBackoff.Delay(1)),
+		LastError:      err.Error(),
+		TraceParent:    v.TraceParent,
 	}
 }
 
-func (e *EpsService) Deposit(data DepositData) error {
+// newEps returns the eps of the bridged deposit by the b2 tx receipt, the eoa transfer tx if bridged by eoa fallback.
+// The contract deposit takes the caller, recipient and log index of the DepositEvent log,
+// the eoa transfer has no event log, its log index is 0. The eps continues the deposit trace.
+func (e *EpsService) newEps(v model.Deposit) (esp model.Eps, err error) {
+	_, span := tracing.Start(tracing.Extract(v.TraceParent), "eps.ingest", trace.WithAttributes(
+		attribute.Int64(tracing.AttrDepositID, v.ID),
+		attribute.String(tracing.AttrBtcTxHash, v.BtcTxHash),
+	))
+	defer func() { tracing.End(span, err) }()
+	txHash := v.B2TxHash
+	eoa := v.B2EoaTxStatus == model.DepositB2EoaTxStatusSuccess
+	if eoa {
+		txHash = v.B2EoaTxHash
+	}
+	receipt, err := e.GetTransactionReceipt(txHash)
+	if err != nil {
+		return esp, fmt.Errorf("eps GetTransactionReceipt err: %w", err)
+	}
+	if receipt.Status != "0x1" {
+		return esp, fmt.Errorf("eps tx %s receipt status %s", txHash, receipt.Status)
+	}
+	blockNumber, err := strconv.ParseInt(receipt.BlockNumber, 0, 64)
+	if err != nil {
+		return esp, fmt.Errorf("eps strconv blockNumber err: %w", err)
+	}
+	transactionIndex, err := strconv.ParseInt(receipt.TransactionIndex, 0, 64)
+	if err != nil {
+		return esp, fmt.Errorf("eps strconv transactionIndex err: %w", err)
+	}
+	gasUsed, err := strconv.ParseInt(receipt.GasUsed, 0, 64)
+	if err != nil {
+		return esp, fmt.Errorf("eps strconv gasUsed err: %w", err)
+	}
+	block, err := e.GetBlockByHash(receipt.BlockHash)
+	if err != nil {
+		return esp, fmt.Errorf("eps GetBlockByHash err: %w", err)
+	}
+	blockTime, err := strconv.ParseInt(block.Timestamp, 0, 64)
+	if err != nil {
+		return esp, fmt.Errorf("eps strconv block timestamp err: %w", err)
+	}
+	from, to := receipt.From, receipt.To
+	var logIndex int64
+	if !eoa {
+		event, err := FindDepositEvent(receipt, e.ContractAddress)
+		if err != nil {
+			return esp, fmt.Errorf("eps tx %s: %w", txHash, err)
+		}
+		logIndex, err = strconv.ParseInt(event.LogIndex, 0, 64)
+		if err != nil {
+			return esp, fmt.Errorf("eps strconv logIndex err: %w", err)
+		}
+		from = common.HexToAddress(event.Topics[1]).Hex()
+		to = common.HexToAddress(event.Topics[2]).Hex()
+	}
+	return model.Eps{
+		DepositID:          v.ID,
+		B2From:             from,
+		B2To:               to,
+		BtcValue:           v.BtcValue,
+		NetValue:           DepositNetValue(v),
+		B2TxHash:           txHash,
+		B2TxTime:           time.Unix(blockTime, 0),
+		B2BlockNumber:      blockNumber,
+		B2BlockHash:        receipt.BlockHash,
+		B2TransactionIndex: transactionIndex,
+		LogIndex:           int(logIndex),
+		B2GasUsed:          gasUsed,
+		Status:             model.EspStatus,
+		IdempotencyKey:     EpsIdempotencyKey(v.ID),
+		TraceParent:        v.TraceParent,
+	}, nil
+}
+
+// FindDepositEvent returns the DepositEvent log of the bridge contract in the receipt
+func FindDepositEvent(receipt *EthReceiptResponse, contractAddress string) (*EthLogResponse, error) {
+	for i, l := range receipt.Logs {
+		if !strings.EqualFold(l.Address, contractAddress) {
+			continue
+		}
+		if len(l.Topics) != 3 || !strings.EqualFold(l.Topics[0], DepositEventTopic.Hex()) {
+			continue
+		}
+		return &receipt.Logs[i], nil
+	}
+	return nil, ErrEpsDepositEventNotFound
+}
+
+// wait waits a minute, or until the deposits change
+func (e *EpsService) wait(wakeup <-chan struct{}) {
+	select {
+	case <-time.After(time.Minute):
+	case <-wakeup:
+	}
+}
+
+// Deposit puts the deposit to eps with the idempotency key, returns the response status code and body
+func (e *EpsService) Deposit(data DepositData, idempotencyKey string) (int, string, error) {
 	body, err := json.Marshal(data)
 	if err != nil {
 		e.log.Errorw("eps deposit marshal err", "error", err)
-		return err
+		return 0, "", err
 	}
 	client := resty.New()
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
 		SetHeader("Authorization", e.config.Authorization).
+		SetHeader("Idempotency-Key", idempotencyKey).
 		SetBody(string(body)).
 		Put(fmt.Sprintf("%s/bridge/deposit", e.config.URL))
 	if err != nil {
 		e.log.Errorw("eps deposit client url err", "error", err)
-		return err
+		return 0, "", err
 	}
 	if resp.StatusCode() != 200 {
 		e.log.Errorw("eps deposit client res err", "res", resp)
-		return errors.New(resp.Status())
+		return resp.StatusCode(), resp.String(), errors.New(resp.Status())
 	}
 	var respData EpsResponse
 	err = json.Unmarshal(resp.Body(), &respData)
 	if err != nil {
 		e.log.Errorw("eps deposit unmarshal err", "error", err)
-		return err
+		return resp.StatusCode(), resp.String(), err
 	}
 	if respData.Code != 0 {
 		e.log.Errorw("eps deposit failed", "resp", respData)
-		return errors.New(respData.Msg)
+		return resp.StatusCode(), resp.String(), errors.New(respData.Msg)
 	}
-	return nil
+	return resp.StatusCode(), resp.String(), nil
 }
 
-func (e *EpsService) GetTransactionByHash(txHash string) (*EthTransactionResponse, error) {
+func (e *EpsService) GetTransactionReceipt(txHash string) (*EthReceiptResponse, error) {
+	var receipt EthReceiptResponse
+	if err := e.call("eth_getTransactionReceipt", []interface{}{txHash}, &receipt); err != nil {
+		e.log.Errorw("eps GetTransactionReceipt err", "error", err)
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (e *EpsService) GetBlockByHash(blockHash string) (*EthBlockResponse, error) {
+	var block EthBlockResponse
+	if err := e.call("eth_getBlockByHash", []interface{}{blockHash, false}, &block); err != nil {
+		e.log.Errorw("eps GetBlockByHash err", "error", err)
+		return nil, err
+	}
+	return &block, nil
+}
+
+// call the eth json rpc method, records the latency, the null result is not found
+func (e *EpsService) call(method string, params []interface{}, result interface{}) error {
+	start := time.Now()
+	err := e.rawCall(method, params, result)
+	if errors.Is(err, ErrEpsNotFound) {
+		metrics.ObserveRPC(metrics.RPCB2, method, start, nil)
+	} else {
+		metrics.ObserveRPC(metrics.RPCB2, method, start, err)
+	}
+	return err
+}
+
+func (e *EpsService) rawCall(method string, params []interface{}, result interface{}) error {
 	reqData := EthRequest{
 		Jsonrpc: "2.0",
-		Method:  "eth_getTransactionByHash",
-		Params:  []string{txHash},
+		Method:  method,
+		Params:  params,
 		ID:      1,
 	}
 	client := resty.New()
@@ -226,17 +546,21 @@ func (e *EpsService) GetTransactionByHash(txHash string) (*EthTransactionRespons
 		SetBody(reqData).
 		Post(e.EthRPCURL)
 	if err != nil {
-		e.log.Errorw("eps post GetTransactionByHash  err", "error", err)
-		return nil, err
+		return err
 	}
 	if resp.StatusCode() != 200 {
-		return nil, errors.New(resp.Status())
+		return errors.New(resp.Status())
 	}
 	var respData EthResponse
 	err = json.Unmarshal(resp.Body(), &respData)
 	if err != nil {
-		e.log.Errorw("eps GetTransactionByHash unmarshal err", "error", err)
-		return nil, err
+		return err
+	}
+	if respData.Error != nil {
+		return fmt.Errorf("%s: %s", method, respData.Error.Message)
+	}
+	if len(respData.Result) == 0 || string(respData.Result) == "null" {
+		return fmt.Errorf("%s: %w", method, ErrEpsNotFound)
 	}
-	return &respData.Result, nil
+	return json.Unmarshal(respData.Result, result)
 }
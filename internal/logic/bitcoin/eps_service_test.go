@@ -0,0 +1,169 @@
+package bitcoin_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositEventTopic(t *testing.T) {
+	contractAbi, err := abi.JSON(strings.NewReader(config.DefaultDepositAbi))
+	require.NoError(t, err)
+	require.Equal(t, contractAbi.Events["DepositEvent"].ID, bitcoin.DepositEventTopic)
+}
+
+func TestFindDepositEvent(t *testing.T) {
+	contract := "0xB1d2A1c7a7A0b5E2a1c1d1e1f1a1b1c1d1e1f1a1"
+	caller := "0x000000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	to := "0x000000000000000000000000bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	receipt := &bitcoin.EthReceiptResponse{
+		Logs: []bitcoin.EthLogResponse{
+			// other contract
+			{Address: "0x0000000000000000000000000000000000000001", Topics: []string{bitcoin.DepositEventTopic.Hex(), caller, to}, LogIndex: "0x1"},
+			// other event
+			{Address: contract, Topics: []string{"0x01", caller}, LogIndex: "0x2"},
+			{Address: strings.ToLower(contract), Topics: []string{bitcoin.DepositEventTopic.Hex(), caller, to}, LogIndex: "0x3"},
+		},
+	}
+	event, err := bitcoin.FindDepositEvent(receipt, contract)
+	require.NoError(t, err)
+	require.Equal(t, "0x3", event.LogIndex)
+
+	_, err = bitcoin.FindDepositEvent(&bitcoin.EthReceiptResponse{}, contract)
+	require.ErrorIs(t, err, bitcoin.ErrEpsDepositEventNotFound)
+}
+
+func TestNewDepositData(t *testing.T) {
+	eps := model.Eps{
+		B2From:        "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		B2To:          "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		BtcValue:      model.NewBigIntFromInt64(100000),
+		NetValue:      model.NewBigIntFromInt64(99700),
+		B2TxHash:      "0x01",
+		B2TxTime:      time.Unix(1700000000, 0),
+		B2BlockNumber: 100,
+		LogIndex:      3,
+	}
+	// eps reports the minted amount after the bridge fee
+	require.Equal(t, bitcoin.DepositData{
+		Caller:      eps.B2From,
+		ToAddress:   eps.B2To,
+		Amount:      "99700",
+		Timestamp:   1700000000,
+		BlockNumber: 100,
+		LogIndex:    3,
+		TxHash:      "0x01",
+	}, bitcoin.NewDepositData(eps))
+
+	// added before the net value was recorded
+	eps.NetValue = model.BigInt{}
+	require.Equal(t, "100000", bitcoin.NewDepositData(eps).Amount)
+}
+
+func TestLocalEpsIngest(t *testing.T) {
+	db := localDB(t)
+	contract := "0xB457BF68D71a17Fa5030269Fb895e29e6cD2DFF2"
+	caller := "0x000000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	to := "0x000000000000000000000000bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	// the receipts of the bad txs are not found until recovered
+	var recovered atomic.Bool
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req bitcoin.EthRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_getTransactionReceipt":
+			txHash, _ := req.Params[0].(string)
+			if strings.HasPrefix(txHash, "0xbad") && !recovered.Load() {
+				break
+			}
+			result = bitcoin.EthReceiptResponse{
+				BlockHash:        "0x01",
+				BlockNumber:      "0x64",
+				TransactionIndex: "0x0",
+				GasUsed:          "0x5208",
+				Status:           "0x1",
+				Logs: []bitcoin.EthLogResponse{
+					{Address: contract, Topics: []string{bitcoin.DepositEventTopic.Hex(), caller, to}, LogIndex: "0x2"},
+				},
+			}
+		case "eth_getBlockByHash":
+			result = bitcoin.EthBlockResponse{Hash: "0x01", Number: "0x64", Timestamp: "0x6553f100"}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer rpc.Close()
+	service, err := bitcoin.NewEpsService(
+		config.BridgeConfig{EthRPCURL: rpc.URL, ContractAddress: contract},
+		config.EpsConfig{}, log.NewNopLogger(), nil, db)
+	require.NoError(t, err)
+
+	// more failing deposits than a batch before the good one
+	failed := bitcoin.EpsBatchLimit + 20
+	for i := 0; i < failed; i++ {
+		deposit := createDeposit(t, db, model.DepositB2TxStatusSuccess)
+		require.NoError(t, db.Model(&deposit).UpdateColumn(model.Deposit{}.Column().B2TxHash, fmt.Sprintf("0xbad%061x", i)).Error)
+	}
+	good := createDeposit(t, db, model.DepositB2TxStatusSuccess)
+	require.NoError(t, db.Model(&good).UpdateColumn(model.Deposit{}.Column().B2TxHash, fmt.Sprintf("0x%064x", 1)).Error)
+	// not bridged
+	createDeposit(t, db, model.DepositB2TxStatusPending)
+
+	countEps := func(status int) int64 {
+		var count int64
+		require.NoError(t, db.Model(&model.Eps{}).Where("status = ?", status).Count(&count).Error)
+		return count
+	}
+	require.NoError(t, service.Ingest())
+	require.Equal(t, int64(failed), countEps(model.EspStatusIngestFailed))
+	require.Equal(t, int64(1), countEps(model.EspStatus))
+	cursor, err := bitcoin.LoadSyncCursor(db, bitcoin.EpsCursorName)
+	require.NoError(t, err)
+	require.Equal(t, good.ID, cursor.CursorID)
+
+	// the failed deposits are recorded once, re-ingested when due
+	require.NoError(t, service.Ingest())
+	require.Equal(t, int64(failed), countEps(model.EspStatusIngestFailed))
+	var first model.Eps
+	require.NoError(t, db.Where("status = ?", model.EspStatusIngestFailed).Order("id").First(&first).Error)
+	require.Equal(t, 1, first.Attempts)
+	require.Contains(t, first.LastError, bitcoin.ErrEpsNotFound.Error())
+	require.True(t, first.NextAttemptAt.After(time.Now()))
+	require.NoError(t, db.Model(&first).UpdateColumn(model.Eps{}.Column().NextAttemptAt, time.Now().Add(-time.Second)).Error)
+	require.NoError(t, service.Ingest())
+	require.NoError(t, db.First(&first, first.ID).Error)
+	require.Equal(t, model.EspStatusIngestFailed, first.Status)
+	require.Equal(t, 2, first.Attempts)
+	require.True(t, first.NextAttemptAt.After(time.Now()))
+
+	recovered.Store(true)
+	require.NoError(t, db.Model(&model.Eps{}).
+		Where("status = ?", model.EspStatusIngestFailed).
+		UpdateColumn(model.Eps{}.Column().NextAttemptAt, time.Now().Add(-time.Second)).Error)
+	require.NoError(t, service.Ingest())
+	require.Zero(t, countEps(model.EspStatusIngestFailed))
+	require.Equal(t, int64(failed+1), countEps(model.EspStatus))
+	require.NoError(t, db.First(&first, first.ID).Error)
+	require.Equal(t, 2, first.LogIndex)
+	require.Zero(t, first.Attempts)
+	require.Empty(t, first.LastError)
+
+	var total int64
+	require.NoError(t, db.Model(&model.Eps{}).Count(&total).Error)
+	require.Equal(t, int64(failed+1), total)
+}
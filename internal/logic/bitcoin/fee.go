@@ -0,0 +1,188 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/model"
+)
+
+const (
+	// bridge fee type
+	BridgeFeeTypeNone       = "none"       // no bridge fee
+	BridgeFeeTypeFlat       = "flat"       // fixed fee per deposit
+	BridgeFeeTypePercentage = "percentage" // fee rate of the deposit amount
+	BridgeFeeTypeTiered     = "tiered"     // fee rate by the deposit amount tier
+
+	// BridgeFeeRateDenominator fee rate is in basis points, 1 bps = 0.01%
+	BridgeFeeRateDenominator = 10000
+)
+
+var (
+	ErrBridgeFeeUnknownType     = errors.New("unknown bridge fee type")
+	ErrBridgeFeeExceedsAmount   = errors.New("bridge fee exceeds deposit amount")
+	ErrBridgeFeeInvalidSchedule = errors.New("invalid bridge fee schedule")
+)
+
+// BridgeFeeTier fee rate applied when the deposit amount reaches MinAmount
+type BridgeFeeTier struct {
+	MinAmount int64
+	Rate      int64
+}
+
+// BridgeFeeSchedule calculates the bridge fee deducted from deposits, amounts are in satoshis
+type BridgeFeeSchedule struct {
+	Type  string
+	Flat  int64
+	Rate  int64
+	Min   int64
+	Max   int64
+	Tiers []BridgeFeeTier
+}
+
+// NewBridgeFeeSchedule new bridge fee schedule by bridge config
+func NewBridgeFeeSchedule(bridgeCfg config.BridgeConfig) (*BridgeFeeSchedule, error) {
+	feeType := bridgeCfg.FeeType
+	if feeType == "" {
+		feeType = BridgeFeeTypeNone
+	}
+	s := &BridgeFeeSchedule{
+		Type: feeType,
+		Flat: bridgeCfg.FeeFlat,
+		Rate: bridgeCfg.FeeRate,
+		Min:  bridgeCfg.FeeMin,
+		Max:  bridgeCfg.FeeMax,
+	}
+	if s.Flat < 0 || s.Min < 0 || s.Max < 0 {
+		return nil, fmt.Errorf("%w: fee amount is negative", ErrBridgeFeeInvalidSchedule)
+	}
+	if s.Max > 0 && s.Min > s.Max {
+		return nil, fmt.Errorf("%w: min fee %d greater than max fee %d", ErrBridgeFeeInvalidSchedule, s.Min, s.Max)
+	}
+	switch feeType {
+	case BridgeFeeTypeNone, BridgeFeeTypeFlat:
+	case BridgeFeeTypePercentage:
+		if err := checkBridgeFeeRate(s.Rate); err != nil {
+			return nil, err
+		}
+	case BridgeFeeTypeTiered:
+		tiers, err := ParseBridgeFeeTiers(bridgeCfg.FeeTiers)
+		if err != nil {
+			return nil, err
+		}
+		s.Tiers = tiers
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrBridgeFeeUnknownType, feeType)
+	}
+	return s, nil
+}
+
+// ParseBridgeFeeTiers parse fee tiers, item format: <min amount>:<rate bps>
+func ParseBridgeFeeTiers(items []string) ([]BridgeFeeTier, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: fee tiers is empty", ErrBridgeFeeInvalidSchedule)
+	}
+	tiers := make([]BridgeFeeTier, 0, len(items))
+	for _, item := range items {
+		minAmount, rate, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: fee tier %s", ErrBridgeFeeInvalidSchedule, item)
+		}
+		tier := BridgeFeeTier{}
+		var err error
+		tier.MinAmount, err = strconv.ParseInt(minAmount, 10, 64)
+		if err != nil || tier.MinAmount < 0 {
+			return nil, fmt.Errorf("%w: fee tier %s", ErrBridgeFeeInvalidSchedule, item)
+		}
+		tier.Rate, err = strconv.ParseInt(rate, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: fee tier %s", ErrBridgeFeeInvalidSchedule, item)
+		}
+		if err := checkBridgeFeeRate(tier.Rate); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].MinAmount < tiers[j].MinAmount
+	})
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].MinAmount == tiers[i-1].MinAmount {
+			return nil, fmt.Errorf("%w: duplicate fee tier %d", ErrBridgeFeeInvalidSchedule, tiers[i].MinAmount)
+		}
+	}
+	return tiers, nil
+}
+
+func checkBridgeFeeRate(rate int64) error {
+	if rate < 0 || rate > BridgeFeeRateDenominator {
+		return fmt.Errorf("%w: fee rate %d bps", ErrBridgeFeeInvalidSchedule, rate)
+	}
+	return nil
+}
+
+// Calculate returns the bridge fee and net amount of the deposit amount
+func (s *BridgeFeeSchedule) Calculate(amount *big.Int) (*big.Int, *big.Int, error) {
+	if amount == nil || amount.Sign() < 0 {
+		return nil, nil, fmt.Errorf("invalid deposit amount %v", amount)
+	}
+	var fee *big.Int
+	switch s.Type {
+	case BridgeFeeTypeFlat:
+		fee = big.NewInt(s.Flat)
+	case BridgeFeeTypePercentage:
+		fee = s.clamp(rateFee(amount, s.Rate))
+	case BridgeFeeTypeTiered:
+		fee = s.clamp(rateFee(amount, s.tierRate(amount)))
+	default:
+		fee = new(big.Int)
+	}
+	net := new(big.Int).Sub(amount, fee)
+	if net.Sign() <= 0 && fee.Sign() > 0 {
+		return nil, nil, fmt.Errorf("%w: amount %s fee %s", ErrBridgeFeeExceedsAmount, amount, fee)
+	}
+	return fee, net, nil
+}
+
+// tierRate returns the rate of the highest tier reached by the amount, 0 if no tier reached
+func (s *BridgeFeeSchedule) tierRate(amount *big.Int) int64 {
+	var rate int64
+	for _, tier := range s.Tiers {
+		if amount.Cmp(big.NewInt(tier.MinAmount)) < 0 {
+			break
+		}
+		rate = tier.Rate
+	}
+	return rate
+}
+
+// clamp limits the fee within min and max, max 0 means unlimited
+func (s *BridgeFeeSchedule) clamp(fee *big.Int) *big.Int {
+	if fee.Cmp(big.NewInt(s.Min)) < 0 {
+		return big.NewInt(s.Min)
+	}
+	if s.Max > 0 && fee.Cmp(big.NewInt(s.Max)) > 0 {
+		return big.NewInt(s.Max)
+	}
+	return fee
+}
+
+// rateFee amount * rate / 10000, round down
+func rateFee(amount *big.Int, rate int64) *big.Int {
+	fee := new(big.Int).Mul(amount, big.NewInt(rate))
+	return fee.Quo(fee, big.NewInt(BridgeFeeRateDenominator))
+}
+
+// DepositNetValue returns the bridged value of the deposit after the bridge fee,
+// the btc value if the deposit was bridged before the fee schedule recorded the net value
+func DepositNetValue(deposit model.Deposit) model.BigInt {
+	if deposit.NetValue.BigInt().Sign() == 0 && deposit.BridgeFee.BigInt().Sign() == 0 {
+		return deposit.BtcValue
+	}
+	return deposit.NetValue
+}
@@ -0,0 +1,154 @@
+package bitcoin_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBridgeFeeSchedule(t *testing.T) {
+	schedule, err := bitcoin.NewBridgeFeeSchedule(config.BridgeConfig{})
+	require.NoError(t, err)
+	require.Equal(t, bitcoin.BridgeFeeTypeNone, schedule.Type)
+
+	schedule, err = bitcoin.NewBridgeFeeSchedule(config.BridgeConfig{
+		FeeType:  bitcoin.BridgeFeeTypeTiered,
+		FeeTiers: []string{"1000000:10", "0:30", "100000:20"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []bitcoin.BridgeFeeTier{
+		{MinAmount: 0, Rate: 30},
+		{MinAmount: 100000, Rate: 20},
+		{MinAmount: 1000000, Rate: 10},
+	}, schedule.Tiers)
+
+	testCases := []struct {
+		name string
+		cfg  config.BridgeConfig
+		err  error
+	}{
+		{"unknown type", config.BridgeConfig{FeeType: "dynamic"}, bitcoin.ErrBridgeFeeUnknownType},
+		{"negative flat", config.BridgeConfig{FeeType: bitcoin.BridgeFeeTypeFlat, FeeFlat: -1}, bitcoin.ErrBridgeFeeInvalidSchedule},
+		{"rate too large", config.BridgeConfig{FeeType: bitcoin.BridgeFeeTypePercentage, FeeRate: 10001}, bitcoin.ErrBridgeFeeInvalidSchedule},
+		{"min greater than max", config.BridgeConfig{FeeType: bitcoin.BridgeFeeTypePercentage, FeeMin: 10, FeeMax: 5}, bitcoin.ErrBridgeFeeInvalidSchedule},
+		{"empty tiers", config.BridgeConfig{FeeType: bitcoin.BridgeFeeTypeTiered}, bitcoin.ErrBridgeFeeInvalidSchedule},
+		{"invalid tier", config.BridgeConfig{FeeType: bitcoin.BridgeFeeTypeTiered, FeeTiers: []string{"0"}}, bitcoin.ErrBridgeFeeInvalidSchedule},
+		{"duplicate tier", config.BridgeConfig{FeeType: bitcoin.BridgeFeeTypeTiered, FeeTiers: []string{"0:10", "0:20"}}, bitcoin.ErrBridgeFeeInvalidSchedule},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := bitcoin.NewBridgeFeeSchedule(tc.cfg)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestBridgeFeeScheduleCalculate(t *testing.T) {
+	tiered := bitcoin.BridgeFeeSchedule{
+		Type: bitcoin.BridgeFeeTypeTiered,
+		Tiers: []bitcoin.BridgeFeeTier{
+			{MinAmount: 10000, Rate: 30},
+			{MinAmount: 1000000, Rate: 10},
+		},
+	}
+	testCases := []struct {
+		name     string
+		schedule bitcoin.BridgeFeeSchedule
+		amount   int64
+		fee      int64
+		net      int64
+		err      error
+	}{
+		{
+			name:     "none",
+			schedule: bitcoin.BridgeFeeSchedule{Type: bitcoin.BridgeFeeTypeNone},
+			amount:   100000,
+			fee:      0,
+			net:      100000,
+		},
+		{
+			name:     "flat",
+			schedule: bitcoin.BridgeFeeSchedule{Type: bitcoin.BridgeFeeTypeFlat, Flat: 1000},
+			amount:   100000,
+			fee:      1000,
+			net:      99000,
+		},
+		{
+			name:     "flat: exceeds amount",
+			schedule: bitcoin.BridgeFeeSchedule{Type: bitcoin.BridgeFeeTypeFlat, Flat: 1000},
+			amount:   1000,
+			err:      bitcoin.ErrBridgeFeeExceedsAmount,
+		},
+		{
+			name:     "percentage: round down",
+			schedule: bitcoin.BridgeFeeSchedule{Type: bitcoin.BridgeFeeTypePercentage, Rate: 25},
+			amount:   123456,
+			fee:      308,
+			net:      123148,
+		},
+		{
+			name:     "percentage: min fee",
+			schedule: bitcoin.BridgeFeeSchedule{Type: bitcoin.BridgeFeeTypePercentage, Rate: 25, Min: 500},
+			amount:   123456,
+			fee:      500,
+			net:      122956,
+		},
+		{
+			name:     "percentage: max fee",
+			schedule: bitcoin.BridgeFeeSchedule{Type: bitcoin.BridgeFeeTypePercentage, Rate: 25, Max: 200},
+			amount:   123456,
+			fee:      200,
+			net:      123256,
+		},
+		{
+			name:     "tiered: below first tier",
+			schedule: tiered,
+			amount:   9999,
+			fee:      0,
+			net:      9999,
+		},
+		{
+			name:     "tiered: first tier",
+			schedule: tiered,
+			amount:   100000,
+			fee:      300,
+			net:      99700,
+		},
+		{
+			name:     "tiered: highest tier",
+			schedule: tiered,
+			amount:   2000000,
+			fee:      2000,
+			net:      1998000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fee, net, err := tc.schedule.Calculate(big.NewInt(tc.amount))
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee.Int64())
+			require.Equal(t, tc.net, net.Int64())
+		})
+	}
+}
+
+func TestDepositNetValue(t *testing.T) {
+	deposit := model.Deposit{
+		BtcValue:  model.NewBigIntFromInt64(100000),
+		BridgeFee: model.NewBigIntFromInt64(300),
+		NetValue:  model.NewBigIntFromInt64(99700),
+	}
+	require.Equal(t, "99700", bitcoin.DepositNetValue(deposit).String())
+
+	// bridged before the fee schedule recorded the net value
+	require.Equal(t, "100000", bitcoin.DepositNetValue(model.Deposit{BtcValue: model.NewBigIntFromInt64(100000)}).String())
+}
@@ -1,6 +1,8 @@
 package bitcoin
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 
@@ -22,6 +24,9 @@ var (
 const (
 	// tx type
 	TxTypeTransfer = "transfer" // btc transfer
+
+	// MaxMemoSize the max OP_RETURN data size of the memo, the hex encoded memo fits the deposit column
+	MaxMemoSize = txscript.MaxDataCarrierSize
 )
 
 // Indexer bitcoin indexer, parse and forward data
@@ -91,6 +96,7 @@ func (b *Indexer) getBlockByHeight(height int64) (*wire.MsgBlock, error) {
 
 // parseTx parse transaction data
 func (b *Indexer) parseTx(txResult *wire.MsgTx, index int) (parsedResult []*types.BitcoinTxParseResult, err error) {
+	memo := ParseMemo(txResult)
 	for _, v := range txResult.TxOut {
 		pkAddress, err := b.parseAddress(v.PkScript)
 		if err != nil {
@@ -102,17 +108,19 @@ func (b *Indexer) parseTx(txResult *wire.MsgTx, index int) (parsedResult []*type
 
 		// if pk address eq dest listened address, after parse from address by vin prev tx
 		if pkAddress == b.listenAddress.EncodeAddress() {
-			fromAddress, err := b.parseFromAddress(txResult)
+			fromAddress, fromScriptTypes, err := b.parseFromAddress(txResult)
 			if err != nil {
 				return nil, fmt.Errorf("vin parse err:%w", err)
 			}
 			parsedResult = append(parsedResult, &types.BitcoinTxParseResult{
-				TxID:   txResult.TxHash().String(),
-				TxType: TxTypeTransfer,
-				Index:  int64(index),
-				Value:  v.Value,
-				From:   fromAddress,
-				To:     pkAddress,
+				TxID:            txResult.TxHash().String(),
+				TxType:          TxTypeTransfer,
+				Index:           int64(index),
+				Value:           v.Value,
+				From:            fromAddress,
+				To:              pkAddress,
+				Memo:            memo,
+				FromScriptTypes: fromScriptTypes,
 			})
 		}
 	}
@@ -120,19 +128,40 @@ func (b *Indexer) parseTx(txResult *wire.MsgTx, index int) (parsedResult []*type
 	return
 }
 
+// ParseMemo returns the hex encoded data of the first OP_RETURN output.
+// The data larger than MaxMemoSize is valid by consensus, but it is ignored instead of truncated,
+// a truncated memo may resolve a wrong recipient.
+func ParseMemo(txResult *wire.MsgTx) string {
+	for _, v := range txResult.TxOut {
+		if len(v.PkScript) == 0 || v.PkScript[0] != txscript.OP_RETURN {
+			continue
+		}
+		pushes, err := txscript.PushedData(v.PkScript)
+		if err != nil {
+			return ""
+		}
+		data := bytes.Join(pushes, nil)
+		if len(data) > MaxMemoSize {
+			return ""
+		}
+		return hex.EncodeToString(data)
+	}
+	return ""
+}
+
 // parseFromAddress from vin parse from address
-// return all possible values parsed from address
+// return all possible values parsed from address and the script class of each address
 // TODO: at present, it is assumed that it is a single from, and multiple from needs to be tested later
-func (b *Indexer) parseFromAddress(txResult *wire.MsgTx) (fromAddress []string, err error) {
+func (b *Indexer) parseFromAddress(txResult *wire.MsgTx) (fromAddress []string, scriptTypes []string, err error) {
 	for _, vin := range txResult.TxIn {
 		// get prev tx hash
 		prevTxID := vin.PreviousOutPoint.Hash
 		vinResult, err := b.client.GetRawTransaction(&prevTxID)
 		if err != nil {
-			return nil, fmt.Errorf("vin get raw transaction err:%w", err)
+			return nil, nil, fmt.Errorf("vin get raw transaction err:%w", err)
 		}
 		if len(vinResult.MsgTx().TxOut) == 0 {
-			return nil, fmt.Errorf("vin txOut is null")
+			return nil, nil, fmt.Errorf("vin txOut is null")
 		}
 		vinPKScript := vinResult.MsgTx().TxOut[vin.PreviousOutPoint.Index].PkScript
 		//  script to address
@@ -142,10 +171,11 @@ func (b *Indexer) parseFromAddress(txResult *wire.MsgTx) (fromAddress []string,
 			if errors.Is(err, ErrParsePkScript) {
 				continue
 			}
-			return nil, err
+			return nil, nil, err
 		}
 
 		fromAddress = append(fromAddress, vinPkAddress)
+		scriptTypes = append(scriptTypes, txscript.GetScriptClass(vinPKScript).String())
 	}
 	return
 }
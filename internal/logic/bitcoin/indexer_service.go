@@ -1,11 +1,15 @@
 package bitcoin
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"time"
 
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/internal/metrics"
 	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/tracing"
 	"github.com/b2network/b2-indexer/internal/types"
 	"github.com/b2network/b2-indexer/pkg/log"
 	"github.com/b2network/b2-indexer/pkg/utils"
@@ -27,6 +31,9 @@ type IndexerService struct {
 	service.BaseService
 
 	txIdxr types.BITCOINTxIndexer
+	policy types.DepositPolicy
+	// elector keeps the single active indexer, nil means no election
+	elector *LeaderElector
 
 	db  *gorm.DB
 	log log.Logger
@@ -36,17 +43,22 @@ type IndexerService struct {
 func NewIndexerService(
 	txIdxr types.BITCOINTxIndexer,
 	// bridge types.BITCOINBridge,
+	policy types.DepositPolicy,
+	elector *LeaderElector,
 	db *gorm.DB,
 	logger log.Logger,
 ) *IndexerService {
-	is := &IndexerService{txIdxr: txIdxr, db: db, log: logger}
+	is := &IndexerService{txIdxr: txIdxr, policy: policy, elector: elector, db: db, log: logger}
 	is.BaseService = *service.NewBaseService(nil, ServiceName, is)
 	return is
 }
 
 // OnStart
 func (bis *IndexerService) OnStart() error {
-	latestBlock, err := bis.txIdxr.LatestBlock()
+	// only the leader indexes, the standby instances wait here
+	bis.acquireLeadership()
+
+	latestBlock, err := bis.latestBlock()
 	if err != nil {
 		bis.log.Errorw("bitcoin indexer latestBlock", "error", err.Error())
 		return err
@@ -56,41 +68,41 @@ func (bis *IndexerService) OnStart() error {
 		currentBlock   int64 // index current block number
 		currentTxIndex int64 // index current block tx index
 	)
-	if !bis.db.Migrator().HasTable(&model.Deposit{}) {
-		err = bis.db.AutoMigrate(&model.Deposit{})
+	// auto migrate also adds the new deposit columns to the existing table
+	err = bis.db.AutoMigrate(&model.Deposit{})
+	if err != nil {
+		bis.log.Errorw("bitcoin indexer migrate table", "error", err.Error())
+		return err
+	}
+
+	if !bis.db.Migrator().HasTable(&model.DepositEvent{}) {
+		err = bis.db.AutoMigrate(&model.DepositEvent{})
 		if err != nil {
 			bis.log.Errorw("bitcoin indexer create table", "error", err.Error())
 			return err
 		}
 	}
 
-	if !bis.db.Migrator().HasTable(&model.BtcIndex{}) {
-		err = bis.db.AutoMigrate(&model.BtcIndex{})
+	if !bis.db.Migrator().HasTable(&model.Refund{}) {
+		err = bis.db.AutoMigrate(&model.Refund{})
 		if err != nil {
 			bis.log.Errorw("bitcoin indexer create table", "error", err.Error())
 			return err
 		}
 	}
 
-	var btcIndex model.BtcIndex
-	if err := bis.db.First(&btcIndex, 1).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			btcIndex = model.BtcIndex{
-				Base: model.Base{
-					ID: 1,
-				},
-				BtcIndexBlock: latestBlock,
-				BtcIndexTx:    0,
-			}
-			if err := bis.db.Create(&btcIndex).Error; err != nil {
-				return err
-			}
-		} else {
+	if !bis.db.Migrator().HasTable(&model.BtcIndex{}) {
+		err = bis.db.AutoMigrate(&model.BtcIndex{})
+		if err != nil {
+			bis.log.Errorw("bitcoin indexer create table", "error", err.Error())
 			return err
 		}
 	}
 
-	bis.log.Infow("bitcoin indexer load db", "data", btcIndex)
+	btcIndex, err := bis.loadBtcIndex(latestBlock)
+	if err != nil {
+		return err
+	}
 
 	// set default value
 	currentBlock = btcIndex.BtcIndexBlock
@@ -98,15 +110,31 @@ func (bis *IndexerService) OnStart() error {
 
 	ticker := time.NewTicker(NewBlockWaitTimeout)
 	for {
+		health.Beat(ServiceName)
+		// another instance may take over while the leadership is lost, resume from its index
+		if !bis.leading() {
+			bis.acquireLeadership()
+			btcIndex, err = bis.loadBtcIndex(latestBlock)
+			if err != nil {
+				bis.log.Errorw("bitcoin indexer load db", "error", err.Error())
+				bis.releaseLeadership()
+				time.Sleep(IndexBlockTimeout)
+				continue
+			}
+			currentBlock = btcIndex.BtcIndexBlock
+			currentTxIndex = btcIndex.BtcIndexTx
+		}
+
 		bis.log.Infow("bitcoin indexer", "latestBlock",
 			latestBlock, "currentBlock", currentBlock, "currentTxIndex", currentTxIndex)
+		metrics.SetBtcIndex(currentBlock, latestBlock)
 
 		if latestBlock <= currentBlock {
 			<-ticker.C
 			ticker.Reset(NewBlockWaitTimeout)
 
 			// update latest block
-			latestBlock, err = bis.txIdxr.LatestBlock()
+			latestBlock, err = bis.latestBlock()
 			if err != nil {
 				bis.log.Errorw("bitcoin indexer latestBlock", "error", err.Error())
 			}
@@ -122,8 +150,15 @@ func (bis *IndexerService) OnStart() error {
 		}
 
 		for i := currentBlock; i <= latestBlock; i++ {
+			if !bis.leading() {
+				break
+			}
+			health.Beat(ServiceName)
 			bis.log.Infow("start parse block", "currentBlock", i, "currentTxIndex", currentTxIndex)
+			start := time.Now()
 			txResults, blockHeader, err := bis.txIdxr.ParseBlock(i, currentTxIndex)
+			parsed := time.Now()
+			metrics.ObserveRPC(metrics.RPCBitcoin, "parse_block", start, err)
 			if err != nil {
 				bis.log.Errorw("parse block unknown err", "error", err.Error(), "currentBlock", i, "currentTxIndex", currentTxIndex)
 				if currentTxIndex == 0 {
@@ -134,6 +169,8 @@ func (bis *IndexerService) OnStart() error {
 				}
 				break
 			}
+			metrics.BtcBlocksParsed.Inc()
+			metrics.BtcDepositsParsed.Add(float64(len(txResults)))
 			if len(txResults) > 0 {
 				for _, v := range txResults {
 					// if from is listen address, skip
@@ -144,19 +181,31 @@ func (bis *IndexerService) OnStart() error {
 
 					btcIndex.BtcIndexBlock = i
 					btcIndex.BtcIndexTx = v.Index
+					// deposits rejected by policy wait refund instead of bridge
+					b2TxStatus := model.DepositB2TxStatusPending
+					rejectReason := bis.checkPolicy(v)
+					if rejectReason != "" {
+						b2TxStatus = model.DepositB2TxStatusRejected
+						bis.log.Warnw("deposit rejected by policy", "reason", rejectReason, "data", v)
+					}
 					// write db
+					ctx, span := StartDepositTrace(v, i, start, parsed)
 					err = bis.SaveParsedResult(
+						ctx,
 						v,
 						i,
-						model.DepositB2TxStatusPending,
+						b2TxStatus,
+						rejectReason,
 						blockHeader.Timestamp,
 						btcIndex,
 					)
+					tracing.End(span, err)
 					if err != nil {
 						bis.log.Errorw("failed to save bitcoin index tx", "error", err,
-							"data", v)
+							"data", v, "traceID", tracing.TraceID(ctx))
 					} else {
-						bis.log.Infow("bitcoin indexer save bitcoin index tx success", "data", v)
+						bis.log.Infow("bitcoin indexer save bitcoin index tx success", "data", v,
+							"traceID", tracing.TraceID(ctx))
 					}
 
 					time.Sleep(IndexTxTimeout)
@@ -172,6 +221,7 @@ func (bis *IndexerService) OnStart() error {
 			} else {
 				bis.log.Infow("bitcoin indexer parsed", "currentBlock", i,
 					"currentTxIndex", currentTxIndex, "latestBlock", latestBlock)
+				metrics.SetBtcIndex(currentBlock, latestBlock)
 			}
 
 			time.Sleep(IndexBlockTimeout)
@@ -179,16 +229,86 @@ func (bis *IndexerService) OnStart() error {
 	}
 }
 
-// save index tx to db
+// latestBlock returns the bitcoin chain tip, records the rpc latency
+func (bis *IndexerService) latestBlock() (int64, error) {
+	start := time.Now()
+	latestBlock, err := bis.txIdxr.LatestBlock()
+	metrics.ObserveRPC(metrics.RPCBitcoin, "latest_block", start, err)
+	return latestBlock, err
+}
+
+// loadBtcIndex loads the index progress, starts from the latest block if not indexed before
+func (bis *IndexerService) loadBtcIndex(latestBlock int64) (model.BtcIndex, error) {
+	var btcIndex model.BtcIndex
+	if err := bis.db.First(&btcIndex, 1).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			btcIndex = model.BtcIndex{
+				Base: model.Base{
+					ID: 1,
+				},
+				BtcIndexBlock: latestBlock,
+				BtcIndexTx:    0,
+			}
+			if err := bis.db.Create(&btcIndex).Error; err != nil {
+				return btcIndex, err
+			}
+		} else {
+			return btcIndex, err
+		}
+	}
+
+	bis.log.Infow("bitcoin indexer load db", "data", btcIndex)
+	return btcIndex, nil
+}
+
+// acquireLeadership blocks until the instance becomes the leader
+func (bis *IndexerService) acquireLeadership() {
+	if bis.elector != nil {
+		bis.elector.Acquire()
+	}
+}
+
+// releaseLeadership gives up the leadership
+func (bis *IndexerService) releaseLeadership() {
+	if bis.elector != nil {
+		bis.elector.Release()
+	}
+}
+
+// leading returns whether the instance is the leader, the elector caches it between the lock connection checks
+func (bis *IndexerService) leading() bool {
+	return bis.elector == nil || bis.elector.IsLeader()
+}
+
+// checkPolicy returns the reject reason of the deposit, empty if accepted.
+// A policy check error does not reject the deposit.
+func (bis *IndexerService) checkPolicy(parseResult *types.BitcoinTxParseResult) string {
+	if bis.policy == nil {
+		return ""
+	}
+	reason, err := bis.policy.Check(parseResult)
+	if err != nil {
+		bis.log.Errorw("deposit policy check err", "error", err.Error(), "data", parseResult)
+		return ""
+	}
+	return reason
+}
+
+// save index tx to db, the rejected deposit is added to the refund queue.
+// The trace context of ctx is persisted on the deposit.
 func (bis *IndexerService) SaveParsedResult(
+	ctx context.Context,
 	parseResult *types.BitcoinTxParseResult,
 	btcBlockNumber int64,
 	b2TxStatus int,
+	rejectReason string,
 	btcBlockTime time.Time,
 	btcIndex model.BtcIndex,
 ) error {
+	traceParent := tracing.Inject(ctx)
+	ctx, span := tracing.Start(ctx, "db.save_deposit")
 	// write db
-	err := bis.db.Transaction(func(tx *gorm.DB) error {
+	err := bis.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		froms, err := json.Marshal(parseResult.From)
 		if err != nil {
 			return err
@@ -199,11 +319,15 @@ func (bis *IndexerService) SaveParsedResult(
 			BtcTxHash:      parseResult.TxID,
 			BtcFrom:        parseResult.From[0],
 			BtcTo:          parseResult.To,
-			BtcValue:       parseResult.Value,
+			BtcValue:       model.NewBigIntFromInt64(parseResult.Value),
 			BtcFroms:       string(froms),
 			B2TxStatus:     b2TxStatus,
+			B2EoaTxStatus:  model.DepositB2EoaTxStatusPending,
 			BtcBlockTime:   btcBlockTime,
 			B2TxRetry:      0,
+			BtcMemo:        parseResult.Memo,
+			RejectReason:   rejectReason,
+			TraceParent:    traceParent,
 		}
 		err = tx.Save(&deposit).Error
 		if err != nil {
@@ -211,12 +335,40 @@ func (bis *IndexerService) SaveParsedResult(
 			return err
 		}
 
+		events := DepositCreatedEvents(&deposit, DepositActorIndexer, rejectReason)
+		if err := tx.Create(&events).Error; err != nil {
+			bis.log.Errorw("failed to save deposit events", "error", err)
+			return err
+		}
+
+		if b2TxStatus == model.DepositB2TxStatusRejected {
+			refund := model.Refund{
+				DepositID:    deposit.ID,
+				BtcTxHash:    deposit.BtcTxHash,
+				ToAddress:    deposit.BtcFrom,
+				Amount:       deposit.BtcValue,
+				RejectReason: rejectReason,
+				Status:       model.RefundStatusPendingApproval,
+			}
+			if err := tx.Create(&refund).Error; err != nil {
+				bis.log.Errorw("failed to save refund", "error", err)
+				return err
+			}
+		}
+
 		if err := tx.Save(&btcIndex).Error; err != nil {
 			bis.log.Errorw("failed to save bitcoin tx index", "error", err)
 			return err
 		}
 
+		// wake up the bridge once committed
+		if err := NotifyDeposit(tx, deposit.ID); err != nil {
+			bis.log.Errorw("failed to notify deposit", "error", err)
+			return err
+		}
+
 		return nil
 	})
+	tracing.End(span, err)
 	return err
 }
@@ -1,6 +1,8 @@
 package bitcoin_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"testing"
 
 	"github.com/b2network/b2-indexer/internal/config"
@@ -8,6 +10,8 @@ import (
 	"github.com/b2network/b2-indexer/internal/types"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 
 	// tmlog "github.com/cometbft/cometbft/libs/log"
 	"github.com/b2network/b2-indexer/pkg/log"
@@ -135,6 +139,47 @@ func TestParseAddress(t *testing.T) {
 	}
 }
 
+func TestParseMemo(t *testing.T) {
+	nullData := func(data []byte) *wire.TxOut {
+		script, err := txscript.NullDataScript(data)
+		require.NoError(t, err)
+		return wire.NewTxOut(0, script)
+	}
+	oversize := func(data []byte) *wire.TxOut {
+		// valid by consensus, but not standard
+		script, err := txscript.NewScriptBuilder(txscript.WithScriptAllocSize(len(data) + 4)).
+			AddOp(txscript.OP_RETURN).
+			AddFullData(data).
+			Script()
+		require.NoError(t, err)
+		return wire.NewTxOut(0, script)
+	}
+	transfer := wire.NewTxOut(1000, []byte{txscript.OP_1, txscript.OP_DATA_32})
+	maxData := bytes.Repeat([]byte{0xab}, bitcoin.MaxMemoSize)
+
+	testCases := []struct {
+		name string
+		outs []*wire.TxOut
+		memo string
+	}{
+		{"no op_return", []*wire.TxOut{transfer}, ""},
+		{"memo", []*wire.TxOut{transfer, nullData([]byte("b2"))}, hex.EncodeToString([]byte("b2"))},
+		{"max size", []*wire.TxOut{nullData(maxData)}, hex.EncodeToString(maxData)},
+		{"oversize ignored", []*wire.TxOut{oversize(append(maxData, 0xab))}, ""},
+		{"oversize 100KB ignored", []*wire.TxOut{oversize(bytes.Repeat([]byte{0xab}, 100000))}, ""},
+	}
+	for _, tc := range testCases {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for _, out := range tc.outs {
+			tx.AddTxOut(out)
+		}
+		memo := bitcoin.ParseMemo(tx)
+		require.Equal(t, tc.memo, memo, tc.name)
+		// the hex encoded memo fits the deposit column
+		require.LessOrEqual(t, len(memo), 160, tc.name)
+	}
+}
+
 // TestLocalParseTx only test in local
 // data source: testnet network
 func TestLocalParseTx(t *testing.T) {
@@ -0,0 +1,149 @@
+package bitcoin
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"gorm.io/gorm"
+)
+
+const (
+	LeaderElectionTimeout = 10 * time.Second
+	// the lock connection is checked on a timer, the leadership is cached between the checks
+	LeaderCheckInterval = 10 * time.Second
+	LeaderCheckTimeout  = 5 * time.Second
+)
+
+// LeaderElector elects the single active instance by the postgres session advisory lock.
+// The lock is held by a dedicated db connection, it is released when the connection is closed,
+// so the standby instance takes over when the leader crashes.
+type LeaderElector struct {
+	name string
+	key  int64
+	db   *gorm.DB
+	log  log.Logger
+
+	mu     sync.Mutex
+	conn   *sql.Conn
+	stop   chan struct{}
+	leader atomic.Bool
+}
+
+// NewLeaderElector returns the leader elector, instances of the same name compete for the same lock
+func NewLeaderElector(db *gorm.DB, name string, logger log.Logger) *LeaderElector {
+	return &LeaderElector{name: name, key: LeaderLockKey(name), db: db, log: logger}
+}
+
+// LeaderLockKey returns the advisory lock key of the name
+func LeaderLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// TryAcquire tries to acquire the leadership once
+func (e *LeaderElector) TryAcquire(ctx context.Context) (bool, error) {
+	if e.IsLeader() {
+		return true, nil
+	}
+	sqlDB, err := e.db.DB()
+	if err != nil {
+		return false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&acquired)
+	if err != nil || !acquired {
+		_ = conn.Close()
+		return false, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.conn = conn
+	e.stop = make(chan struct{})
+	e.leader.Store(true)
+	go e.watch(conn, e.stop)
+	return true, nil
+}
+
+// Acquire blocks until the leadership is acquired
+func (e *LeaderElector) Acquire() {
+	for {
+		// the standby instance is alive while waiting
+		health.Beat(e.name)
+		acquired, err := e.TryAcquire(context.Background())
+		if err != nil {
+			e.log.Errorw("leader election err", "error", err, "name", e.name)
+		}
+		if acquired {
+			e.log.Infow("leader election acquired leadership", "name", e.name)
+			return
+		}
+		e.log.Infow("leader election standby, another instance is active", "name", e.name)
+		time.Sleep(LeaderElectionTimeout)
+	}
+}
+
+// IsLeader returns the cached leadership, the lock connection is checked by the watch
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// watch pings the lock connection until released, the leadership is lost on the first error
+func (e *LeaderElector) watch(conn *sql.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(LeaderCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), LeaderCheckTimeout)
+		err := conn.PingContext(ctx)
+		cancel()
+		if err != nil {
+			e.lose(conn, err)
+			return
+		}
+	}
+}
+
+// lose gives up the leadership of the broken lock connection, the lock is released by postgres with the session
+func (e *LeaderElector) lose(conn *sql.Conn, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn != conn {
+		return
+	}
+	e.log.Errorw("leader election lost leadership", "error", err, "name", e.name)
+	e.leader.Store(false)
+	_ = e.conn.Close()
+	e.conn = nil
+}
+
+// Release releases the leadership
+func (e *LeaderElector) Release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return
+	}
+	close(e.stop)
+	e.leader.Store(false)
+	if _, err := e.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", e.key); err != nil {
+		e.log.Errorw("leader election release err", "error", err, "name", e.name)
+	}
+	_ = e.conn.Close()
+	e.conn = nil
+}
@@ -0,0 +1,161 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// rate limit scope
+	RateLimitScopeGlobal = "global" // all deposits
+	RateLimitScopeSender = "sender" // deposits of the same bitcoin from address
+
+	// rate limit metric
+	RateLimitMetricAmount = "amount" // bridged amount(sats) in the window
+	RateLimitMetricCount  = "count"  // bridged deposit count in the window
+)
+
+var ErrRateLimitInvalid = errors.New("invalid rate limit")
+
+// RateLimit limits the bridged volume in the rolling window
+type RateLimit struct {
+	Scope  string
+	Metric string
+	Window time.Duration
+	Limit  int64
+}
+
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%s %s limit %d per %s", l.Scope, l.Metric, l.Limit, l.Window)
+}
+
+// RateUsage bridged volume in the window
+type RateUsage struct {
+	Amount *big.Int
+	Count  int64
+}
+
+// Exceeded returns whether bridging the amount exceeds the limit
+func (l RateLimit) Exceeded(usage RateUsage, amount *big.Int) bool {
+	if l.Metric == RateLimitMetricCount {
+		return usage.Count+1 > l.Limit
+	}
+	total := new(big.Int).Add(usage.Amount, amount)
+	return total.Cmp(big.NewInt(l.Limit)) > 0
+}
+
+// Oversized returns whether the amount alone exceeds the limit, it never fits the window
+func (l RateLimit) Oversized(amount *big.Int) bool {
+	return l.Metric == RateLimitMetricAmount && amount.Cmp(big.NewInt(l.Limit)) > 0
+}
+
+// ParseRateLimits parse rate limits, item format: <scope>:<metric>:<window>:<limit>
+func ParseRateLimits(items []string) ([]RateLimit, error) {
+	limits := make([]RateLimit, 0, len(items))
+	for _, item := range items {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("%w: %s", ErrRateLimitInvalid, item)
+		}
+		l := RateLimit{Scope: parts[0], Metric: parts[1]}
+		if l.Scope != RateLimitScopeGlobal && l.Scope != RateLimitScopeSender {
+			return nil, fmt.Errorf("%w: unknown scope %s", ErrRateLimitInvalid, item)
+		}
+		if l.Metric != RateLimitMetricAmount && l.Metric != RateLimitMetricCount {
+			return nil, fmt.Errorf("%w: unknown metric %s", ErrRateLimitInvalid, item)
+		}
+		window, err := time.ParseDuration(parts[2])
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("%w: window %s", ErrRateLimitInvalid, item)
+		}
+		l.Window = window
+		l.Limit, err = strconv.ParseInt(parts[3], 10, 64)
+		if err != nil || l.Limit <= 0 {
+			return nil, fmt.Errorf("%w: limit %s", ErrRateLimitInvalid, item)
+		}
+		limits = append(limits, l)
+	}
+	return limits, nil
+}
+
+// RateLimiter checks the deposit against the rate limits by the bridged deposits in db
+type RateLimiter struct {
+	limits []RateLimit
+	db     *gorm.DB
+}
+
+// NewRateLimiter new rate limiter
+func NewRateLimiter(limits []RateLimit, db *gorm.DB) *RateLimiter {
+	return &RateLimiter{limits: limits, db: db}
+}
+
+// Oversized returns the first limit the deposit value alone exceeds, nil if none.
+// Throttling it would wait forever, it needs operator approval instead
+func (r *RateLimiter) Oversized(deposit *model.Deposit) *RateLimit {
+	for i := range r.limits {
+		if r.limits[i].Oversized(deposit.BtcValue.BigInt()) {
+			return &r.limits[i]
+		}
+	}
+	return nil
+}
+
+// Check returns the first limit exceeded by the deposit, nil if allowed.
+// The oversized limits are skipped, the deposit passed them by operator approval
+func (r *RateLimiter) Check(deposit *model.Deposit) (*RateLimit, error) {
+	for i := range r.limits {
+		l := r.limits[i]
+		if l.Oversized(deposit.BtcValue.BigInt()) {
+			continue
+		}
+		usage, err := r.usage(l, deposit.BtcFrom)
+		if err != nil {
+			return nil, err
+		}
+		if l.Exceeded(usage, deposit.BtcValue.BigInt()) {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+// usage returns the bridged volume in the limit window, the in-flight deposits and the eoa transfers are counted
+// as bridged. The window is on the tx submitted time, updated_at changes on every later update of the deposit
+func (r *RateLimiter) usage(l RateLimit, btcFrom string) (RateUsage, error) {
+	var result struct {
+		Amount model.BigInt
+		Count  int64
+	}
+	query := r.db.Model(&model.Deposit{}).
+		Select(fmt.Sprintf("COALESCE(SUM(%s), 0) AS amount, COUNT(*) AS count", model.Deposit{}.Column().BtcValue)).
+		Where(fmt.Sprintf("(%s IN (?) AND %s > ?) OR (%s IN (?) AND %s > ?)",
+			model.Deposit{}.Column().B2TxStatus, model.Deposit{}.Column().B2TxSubmittedAt,
+			model.Deposit{}.Column().B2EoaTxStatus, model.Deposit{}.Column().B2EoaTxSubmittedAt),
+			[]int{model.DepositB2TxStatusSuccess, model.DepositB2TxStatusInFlight}, time.Now().Add(-l.Window),
+			[]int{model.DepositB2EoaTxStatusSuccess, model.DepositB2EoaTxStatusInFlight}, time.Now().Add(-l.Window))
+	if l.Scope == RateLimitScopeSender {
+		query = query.Where(fmt.Sprintf("%s = ?", model.Deposit{}.Column().BtcFrom), btcFrom)
+	}
+	if err := query.Scan(&result).Error; err != nil {
+		return RateUsage{}, err
+	}
+	return RateUsage{Amount: result.Amount.BigInt(), Count: result.Count}, nil
+}
+
+// BackfillDepositSubmittedAt sets the tx submitted time of the bridged deposits submitted before it was recorded,
+// the last update time is the closest known. Returns the backfilled deposit count
+func BackfillDepositSubmittedAt(db *gorm.DB) (int64, error) {
+	result := db.Model(&model.Deposit{}).
+		Where(fmt.Sprintf("%s IN (?) AND (%s IS NULL OR %s <= ?)", model.Deposit{}.Column().B2TxStatus,
+			model.Deposit{}.Column().B2TxSubmittedAt, model.Deposit{}.Column().B2TxSubmittedAt),
+			[]int{model.DepositB2TxStatusSuccess, model.DepositB2TxStatusInFlight}, time.Time{}).
+		UpdateColumn(model.Deposit{}.Column().B2TxSubmittedAt, gorm.Expr("updated_at"))
+	return result.RowsAffected, result.Error
+}
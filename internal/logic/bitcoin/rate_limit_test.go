@@ -0,0 +1,135 @@
+package bitcoin_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := bitcoin.ParseRateLimits([]string{"global:amount:24h:1000000000", "sender:count:1h:10"})
+	require.NoError(t, err)
+	require.Equal(t, []bitcoin.RateLimit{
+		{Scope: bitcoin.RateLimitScopeGlobal, Metric: bitcoin.RateLimitMetricAmount, Window: 24 * time.Hour, Limit: 1000000000},
+		{Scope: bitcoin.RateLimitScopeSender, Metric: bitcoin.RateLimitMetricCount, Window: time.Hour, Limit: 10},
+	}, limits)
+	require.Equal(t, "global amount limit 1000000000 per 24h0m0s", limits[0].String())
+
+	limits, err = bitcoin.ParseRateLimits(nil)
+	require.NoError(t, err)
+	require.Empty(t, limits)
+
+	for _, item := range []string{
+		"global:amount:24h",
+		"user:amount:24h:100",
+		"global:volume:24h:100",
+		"global:amount:1d:100",
+		"global:amount:-1h:100",
+		"global:amount:24h:0",
+	} {
+		_, err := bitcoin.ParseRateLimits([]string{item})
+		require.ErrorIs(t, err, bitcoin.ErrRateLimitInvalid, item)
+	}
+}
+
+func TestRateLimitExceeded(t *testing.T) {
+	amountLimit := bitcoin.RateLimit{Scope: bitcoin.RateLimitScopeGlobal, Metric: bitcoin.RateLimitMetricAmount, Window: time.Hour, Limit: 1000}
+	require.False(t, amountLimit.Exceeded(bitcoin.RateUsage{Amount: big.NewInt(600), Count: 3}, big.NewInt(400)))
+	require.True(t, amountLimit.Exceeded(bitcoin.RateUsage{Amount: big.NewInt(600), Count: 3}, big.NewInt(401)))
+
+	countLimit := bitcoin.RateLimit{Scope: bitcoin.RateLimitScopeSender, Metric: bitcoin.RateLimitMetricCount, Window: time.Hour, Limit: 3}
+	require.False(t, countLimit.Exceeded(bitcoin.RateUsage{Amount: big.NewInt(0), Count: 2}, big.NewInt(1000000)))
+	require.True(t, countLimit.Exceeded(bitcoin.RateUsage{Amount: big.NewInt(0), Count: 3}, big.NewInt(1)))
+}
+
+func TestRateLimitOversized(t *testing.T) {
+	amountLimit := bitcoin.RateLimit{Scope: bitcoin.RateLimitScopeGlobal, Metric: bitcoin.RateLimitMetricAmount, Window: time.Hour, Limit: 1000}
+	require.False(t, amountLimit.Oversized(big.NewInt(1000)))
+	require.True(t, amountLimit.Oversized(big.NewInt(1001)))
+
+	countLimit := bitcoin.RateLimit{Scope: bitcoin.RateLimitScopeSender, Metric: bitcoin.RateLimitMetricCount, Window: time.Hour, Limit: 1}
+	require.False(t, countLimit.Oversized(big.NewInt(1000000)))
+}
+
+func TestLocalRateLimiter(t *testing.T) {
+	db := localDB(t)
+	bridged := func(status int, btcFrom string, value int64, submittedAt time.Time) {
+		deposit := createDeposit(t, db, status)
+		require.NoError(t, db.Model(&deposit).UpdateColumns(map[string]interface{}{
+			model.Deposit{}.Column().BtcFrom:         btcFrom,
+			model.Deposit{}.Column().BtcValue:        model.NewBigIntFromInt64(value),
+			model.Deposit{}.Column().B2TxSubmittedAt: submittedAt,
+		}).Error)
+	}
+	bridged(model.DepositB2TxStatusSuccess, "tb1qa", 300, time.Now().Add(-10*time.Minute))
+	bridged(model.DepositB2TxStatusInFlight, "tb1qb", 300, time.Now().Add(-time.Minute))
+	// submitted before the window, updated in the window
+	bridged(model.DepositB2TxStatusSuccess, "tb1qa", 1000, time.Now().Add(-2*time.Hour))
+	// not bridged
+	bridged(model.DepositB2TxStatusFailed, "tb1qa", 1000, time.Now())
+	createDeposit(t, db, model.DepositB2TxStatusPending)
+
+	limits, err := bitcoin.ParseRateLimits([]string{"global:amount:1h:1000", "sender:count:1h:2"})
+	require.NoError(t, err)
+	limiter := bitcoin.NewRateLimiter(limits, db)
+
+	limit, err := limiter.Check(&model.Deposit{BtcFrom: "tb1qa", BtcValue: model.NewBigIntFromInt64(400)})
+	require.NoError(t, err)
+	require.Nil(t, limit)
+
+	limit, err = limiter.Check(&model.Deposit{BtcFrom: "tb1qc", BtcValue: model.NewBigIntFromInt64(401)})
+	require.NoError(t, err)
+	require.Equal(t, &limits[0], limit)
+
+	// the oversized deposit is not throttled, it waits approval
+	oversized := &model.Deposit{BtcFrom: "tb1qc", BtcValue: model.NewBigIntFromInt64(1001)}
+	require.Equal(t, &limits[0], limiter.Oversized(oversized))
+	limit, err = limiter.Check(oversized)
+	require.NoError(t, err)
+	require.Nil(t, limit)
+
+	// the eoa transfer is counted
+	eoa := createDeposit(t, db, model.DepositB2TxStatusWaitMinedStatusFailed)
+	require.NoError(t, db.Model(&eoa).UpdateColumns(map[string]interface{}{
+		model.Deposit{}.Column().BtcValue:           model.NewBigIntFromInt64(300),
+		model.Deposit{}.Column().B2EoaTxStatus:      model.DepositB2EoaTxStatusInFlight,
+		model.Deposit{}.Column().B2EoaTxSubmittedAt: time.Now(),
+	}).Error)
+	limit, err = limiter.Check(&model.Deposit{BtcFrom: "tb1qc", BtcValue: model.NewBigIntFromInt64(101)})
+	require.NoError(t, err)
+	require.Equal(t, &limits[0], limit)
+
+	bridged(model.DepositB2TxStatusSuccess, "tb1qa", 1, time.Now())
+	limit, err = limiter.Check(&model.Deposit{BtcFrom: "tb1qa", BtcValue: model.NewBigIntFromInt64(1)})
+	require.NoError(t, err)
+	require.Equal(t, &limits[1], limit)
+}
+
+func TestLocalBackfillDepositSubmittedAt(t *testing.T) {
+	db := localDB(t)
+	success := createDeposit(t, db, model.DepositB2TxStatusSuccess)
+	inFlight := createDeposit(t, db, model.DepositB2TxStatusInFlight)
+	pending := createDeposit(t, db, model.DepositB2TxStatusPending)
+	submittedAt := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	submitted := createDeposit(t, db, model.DepositB2TxStatusSuccess)
+	require.NoError(t, db.Model(&submitted).UpdateColumn(model.Deposit{}.Column().B2TxSubmittedAt, submittedAt).Error)
+
+	backfilled, err := bitcoin.BackfillDepositSubmittedAt(db)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), backfilled)
+
+	for _, id := range []int64{success.ID, inFlight.ID} {
+		var deposit model.Deposit
+		require.NoError(t, db.First(&deposit, id).Error)
+		require.True(t, deposit.B2TxSubmittedAt.Equal(deposit.UpdatedAt))
+	}
+	var deposit model.Deposit
+	require.NoError(t, db.First(&deposit, pending.ID).Error)
+	require.True(t, deposit.B2TxSubmittedAt.IsZero())
+	require.NoError(t, db.First(&deposit, submitted.ID).Error)
+	require.True(t, deposit.B2TxSubmittedAt.Equal(submittedAt))
+}
@@ -0,0 +1,262 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	b2aa "github.com/b2network/b2-go-aa-utils"
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// recipient resolution strategy
+	RecipientStrategyAA       = "aa"       // aa registry
+	RecipientStrategyStatic   = "static"   // static mapping table
+	RecipientStrategyMemo     = "memo"     // OP_RETURN memo
+	RecipientStrategyContract = "contract" // recipient registry contract
+
+	// recipientRegistryABI defines the recipient registry contract lookup method
+	recipientRegistryABI = `[{"inputs":[{"internalType":"string","name":"btcAddress","type":"string"}],` +
+		`"name":"getRecipient","outputs":[{"internalType":"address","name":"","type":"address"}],` +
+		`"stateMutability":"view","type":"function"}]`
+)
+
+var (
+	ErrRecipientNotFound        = errors.New("recipient not found")
+	ErrRecipientUnknownStrategy = errors.New("unknown recipient strategy")
+)
+
+// RecipientResolverChain resolves the l2 recipient by strategies in priority order.
+// The next strategy is only tried when the current one returns ErrRecipientNotFound,
+// any other error is returned so that the deposit is retried instead of minting
+// to a lower priority recipient.
+type RecipientResolverChain struct {
+	resolvers []types.RecipientResolver
+}
+
+// NewRecipientResolverChain new recipient resolver chain
+func NewRecipientResolverChain(resolvers ...types.RecipientResolver) *RecipientResolverChain {
+	return &RecipientResolverChain{resolvers: resolvers}
+}
+
+// NewRecipientResolverChainWithConfig new recipient resolver chain by bridge config
+func NewRecipientResolverChainWithConfig(bridgeCfg config.BridgeConfig, ethRPCURL string) (*RecipientResolverChain, error) {
+	strategies := bridgeCfg.RecipientStrategies
+	if len(strategies) == 0 {
+		strategies = []string{RecipientStrategyAA}
+	}
+
+	resolvers := make([]types.RecipientResolver, 0, len(strategies))
+	for _, strategy := range strategies {
+		switch strings.TrimSpace(strategy) {
+		case RecipientStrategyAA:
+			resolvers = append(resolvers, NewAARecipientResolver(
+				ethRPCURL,
+				common.HexToAddress(bridgeCfg.AASCARegistry),
+				common.HexToAddress(bridgeCfg.AAKernelFactory),
+			))
+		case RecipientStrategyStatic:
+			recipients, err := ParseStaticRecipients(bridgeCfg.StaticRecipients)
+			if err != nil {
+				return nil, err
+			}
+			resolvers = append(resolvers, NewStaticRecipientResolver(recipients))
+		case RecipientStrategyMemo:
+			resolvers = append(resolvers, NewMemoRecipientResolver())
+		case RecipientStrategyContract:
+			if !common.IsHexAddress(bridgeCfg.RecipientRegistry) {
+				return nil, fmt.Errorf("invalid recipient registry address: %s", bridgeCfg.RecipientRegistry)
+			}
+			resolvers = append(resolvers, NewContractRecipientResolver(ethRPCURL, common.HexToAddress(bridgeCfg.RecipientRegistry)))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrRecipientUnknownStrategy, strategy)
+		}
+	}
+	return NewRecipientResolverChain(resolvers...), nil
+}
+
+// Resolve returns the l2 recipient address and the strategy name
+func (c *RecipientResolverChain) Resolve(ctx context.Context, req *types.RecipientRequest) (string, string, error) {
+	for _, resolver := range c.resolvers {
+		address, err := resolver.Resolve(ctx, req)
+		if err != nil {
+			if errors.Is(err, ErrRecipientNotFound) {
+				continue
+			}
+			return "", "", fmt.Errorf("%s recipient resolve err:%w", resolver.Name(), err)
+		}
+		return address, resolver.Name(), nil
+	}
+	return "", "", ErrRecipientNotFound
+}
+
+// AARecipientResolver resolves the recipient by aa registry
+type AARecipientResolver struct {
+	ethRPCURL       string
+	aaSCARegistry   common.Address
+	aaKernelFactory common.Address
+}
+
+// NewAARecipientResolver new aa registry recipient resolver
+func NewAARecipientResolver(ethRPCURL string, aaSCARegistry common.Address, aaKernelFactory common.Address) *AARecipientResolver {
+	return &AARecipientResolver{
+		ethRPCURL:       ethRPCURL,
+		aaSCARegistry:   aaSCARegistry,
+		aaKernelFactory: aaKernelFactory,
+	}
+}
+
+func (r *AARecipientResolver) Name() string {
+	return RecipientStrategyAA
+}
+
+func (r *AARecipientResolver) Resolve(_ context.Context, req *types.RecipientRequest) (string, error) {
+	if req.BtcFrom == "" {
+		return "", ErrRecipientNotFound
+	}
+	client, err := ethclient.Dial(r.ethRPCURL)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	targetEthAddress, err := b2aa.GetSCAAddress(client, r.aaSCARegistry, r.aaKernelFactory, req.BtcFrom)
+	if err != nil {
+		return "", err
+	}
+	return targetEthAddress.String(), nil
+}
+
+// StaticRecipientResolver resolves the recipient by static mapping table
+type StaticRecipientResolver struct {
+	recipients map[string]common.Address
+}
+
+// ParseStaticRecipients parse the static mapping items, format: <bitcoin address>:<l2 address>
+func ParseStaticRecipients(items []string) (map[string]string, error) {
+	recipients := make(map[string]string, len(items))
+	for _, item := range items {
+		btcAddress, ethAddress, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || btcAddress == "" || !common.IsHexAddress(ethAddress) {
+			return nil, fmt.Errorf("invalid static recipient: %s", item)
+		}
+		recipients[btcAddress] = ethAddress
+	}
+	return recipients, nil
+}
+
+// NewStaticRecipientResolver new static mapping recipient resolver
+func NewStaticRecipientResolver(recipients map[string]string) *StaticRecipientResolver {
+	r := &StaticRecipientResolver{recipients: make(map[string]common.Address, len(recipients))}
+	for btcAddress, ethAddress := range recipients {
+		r.recipients[btcAddress] = common.HexToAddress(ethAddress)
+	}
+	return r
+}
+
+func (r *StaticRecipientResolver) Name() string {
+	return RecipientStrategyStatic
+}
+
+func (r *StaticRecipientResolver) Resolve(_ context.Context, req *types.RecipientRequest) (string, error) {
+	address, ok := r.recipients[req.BtcFrom]
+	if !ok {
+		return "", ErrRecipientNotFound
+	}
+	return address.String(), nil
+}
+
+// MemoRecipientResolver resolves the recipient by OP_RETURN memo,
+// the memo is the raw 20 bytes address or the "0x" prefixed hex address string.
+type MemoRecipientResolver struct{}
+
+// NewMemoRecipientResolver new memo recipient resolver
+func NewMemoRecipientResolver() *MemoRecipientResolver {
+	return &MemoRecipientResolver{}
+}
+
+func (r *MemoRecipientResolver) Name() string {
+	return RecipientStrategyMemo
+}
+
+func (r *MemoRecipientResolver) Resolve(_ context.Context, req *types.RecipientRequest) (string, error) {
+	memo, err := hex.DecodeString(req.Memo)
+	if err != nil || len(memo) == 0 {
+		return "", ErrRecipientNotFound
+	}
+
+	var address common.Address
+	switch {
+	case len(memo) == common.AddressLength:
+		address = common.BytesToAddress(memo)
+	case common.IsHexAddress(string(memo)) && strings.HasPrefix(string(memo), "0x"):
+		address = common.HexToAddress(string(memo))
+	default:
+		return "", ErrRecipientNotFound
+	}
+
+	if address == (common.Address{}) {
+		return "", ErrRecipientNotFound
+	}
+	return address.String(), nil
+}
+
+// ContractRecipientResolver resolves the recipient by recipient registry contract
+type ContractRecipientResolver struct {
+	ethRPCURL string
+	registry  common.Address
+}
+
+// NewContractRecipientResolver new contract registry recipient resolver
+func NewContractRecipientResolver(ethRPCURL string, registry common.Address) *ContractRecipientResolver {
+	return &ContractRecipientResolver{
+		ethRPCURL: ethRPCURL,
+		registry:  registry,
+	}
+}
+
+func (r *ContractRecipientResolver) Name() string {
+	return RecipientStrategyContract
+}
+
+func (r *ContractRecipientResolver) Resolve(ctx context.Context, req *types.RecipientRequest) (string, error) {
+	if req.BtcFrom == "" {
+		return "", ErrRecipientNotFound
+	}
+	parsedABI, err := abi.JSON(strings.NewReader(recipientRegistryABI))
+	if err != nil {
+		return "", err
+	}
+	data, err := parsedABI.Pack("getRecipient", req.BtcFrom)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := ethclient.Dial(r.ethRPCURL)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &r.registry, Data: data}, nil)
+	if err != nil {
+		return "", err
+	}
+	outputs, err := parsedABI.Unpack("getRecipient", out)
+	if err != nil {
+		return "", err
+	}
+	address, ok := outputs[0].(common.Address)
+	if !ok || address == (common.Address{}) {
+		return "", ErrRecipientNotFound
+	}
+	return address.String(), nil
+}
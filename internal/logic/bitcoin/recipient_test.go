@@ -0,0 +1,180 @@
+package bitcoin_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRecipientResolver struct {
+	name    string
+	address string
+	err     error
+}
+
+func (m *mockRecipientResolver) Name() string {
+	return m.name
+}
+
+func (m *mockRecipientResolver) Resolve(context.Context, *types.RecipientRequest) (string, error) {
+	return m.address, m.err
+}
+
+func TestRecipientResolverChain(t *testing.T) {
+	testCases := []struct {
+		name      string
+		resolvers []types.RecipientResolver
+		address   string
+		strategy  string
+		errMsg    string
+	}{
+		{
+			name: "success: first strategy",
+			resolvers: []types.RecipientResolver{
+				&mockRecipientResolver{name: "first", address: "0x01"},
+				&mockRecipientResolver{name: "second", address: "0x02"},
+			},
+			address:  "0x01",
+			strategy: "first",
+		},
+		{
+			name: "success: fallback when not found",
+			resolvers: []types.RecipientResolver{
+				&mockRecipientResolver{name: "first", err: bitcoin.ErrRecipientNotFound},
+				&mockRecipientResolver{name: "second", address: "0x02"},
+			},
+			address:  "0x02",
+			strategy: "second",
+		},
+		{
+			name: "fail: stop on unknown err",
+			resolvers: []types.RecipientResolver{
+				&mockRecipientResolver{name: "first", err: errors.New("rpc err")},
+				&mockRecipientResolver{name: "second", address: "0x02"},
+			},
+			errMsg: "first recipient resolve err:rpc err",
+		},
+		{
+			name: "fail: all not found",
+			resolvers: []types.RecipientResolver{
+				&mockRecipientResolver{name: "first", err: bitcoin.ErrRecipientNotFound},
+			},
+			errMsg: bitcoin.ErrRecipientNotFound.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := bitcoin.NewRecipientResolverChain(tc.resolvers...)
+			address, strategy, err := chain.Resolve(context.Background(), &types.RecipientRequest{})
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.address, address)
+			require.Equal(t, tc.strategy, strategy)
+		})
+	}
+}
+
+func TestStaticRecipientResolver(t *testing.T) {
+	resolver := bitcoin.NewStaticRecipientResolver(map[string]string{
+		"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy": "0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa",
+	})
+
+	address, err := resolver.Resolve(context.Background(), &types.RecipientRequest{
+		BtcFrom: "tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0x7eE2b84B8e3F7e04d4e0F4c4fb53b1f5f3C6c4aA", address)
+
+	_, err = resolver.Resolve(context.Background(), &types.RecipientRequest{
+		BtcFrom: "tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n",
+	})
+	require.ErrorIs(t, err, bitcoin.ErrRecipientNotFound)
+}
+
+func TestMemoRecipientResolver(t *testing.T) {
+	testCases := []struct {
+		name    string
+		memo    string
+		address string
+	}{
+		{
+			name:    "success: raw address",
+			memo:    "7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa",
+			address: "0x7eE2b84B8e3F7e04d4e0F4c4fb53b1f5f3C6c4aA",
+		},
+		{
+			name:    "success: hex string address",
+			memo:    hex.EncodeToString([]byte("0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa")),
+			address: "0x7eE2b84B8e3F7e04d4e0F4c4fb53b1f5f3C6c4aA",
+		},
+		{
+			name: "fail: empty memo",
+			memo: "",
+		},
+		{
+			name: "fail: invalid memo",
+			memo: hex.EncodeToString([]byte("hello b2")),
+		},
+		{
+			name: "fail: zero address",
+			memo: "0000000000000000000000000000000000000000",
+		},
+	}
+
+	resolver := bitcoin.NewMemoRecipientResolver()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, err := resolver.Resolve(context.Background(), &types.RecipientRequest{Memo: tc.memo})
+			if tc.address == "" {
+				require.ErrorIs(t, err, bitcoin.ErrRecipientNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.address, address)
+		})
+	}
+}
+
+func TestParseStaticRecipients(t *testing.T) {
+	recipients, err := bitcoin.ParseStaticRecipients([]string{
+		"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy:0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy": "0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa",
+	}, recipients)
+
+	_, err = bitcoin.ParseStaticRecipients([]string{"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy"})
+	require.Error(t, err)
+
+	_, err = bitcoin.ParseStaticRecipients([]string{"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy:0x01"})
+	require.Error(t, err)
+}
+
+func TestNewRecipientResolverChainWithConfig(t *testing.T) {
+	_, err := bitcoin.NewRecipientResolverChainWithConfig(config.BridgeConfig{
+		RecipientStrategies: []string{"memo", "static", "aa"},
+		StaticRecipients:    []string{"tb1qjda2l5spwyv4ekwe9keddymzuxynea2m2kj0qy:0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa"},
+	}, "http://localhost:8545")
+	require.NoError(t, err)
+
+	_, err = bitcoin.NewRecipientResolverChainWithConfig(config.BridgeConfig{
+		RecipientStrategies: []string{"contract"},
+	}, "http://localhost:8545")
+	require.Error(t, err)
+
+	_, err = bitcoin.NewRecipientResolverChainWithConfig(config.BridgeConfig{
+		RecipientStrategies: []string{"unknown"},
+	}, "http://localhost:8545")
+	require.ErrorIs(t, err, bitcoin.ErrRecipientUnknownStrategy)
+}
@@ -0,0 +1,194 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/internal/types"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/cometbft/cometbft/libs/service"
+	"gorm.io/gorm"
+)
+
+const (
+	RefundServiceName   = "BitcoinRefundService"
+	BatchRefundWaitTime = 30 * time.Second
+	BatchRefundLimit    = 100
+)
+
+var ErrRefundNotReviewable = errors.New("refund is not pending approval or failed")
+
+// Refunder send refund by the bitcoin core wallet
+type Refunder struct {
+	client      *rpcclient.Client // bitcoin rpc client of the refund wallet
+	chainParams *chaincfg.Params
+}
+
+// NewRefunder new refunder
+func NewRefunder(client *rpcclient.Client, chainParams *chaincfg.Params) *Refunder {
+	return &Refunder{client: client, chainParams: chainParams}
+}
+
+// Refund implements types.BITCOINRefunder
+func (r *Refunder) Refund(address string, amount int64) (string, error) {
+	addr, err := btcutil.DecodeAddress(address, r.chainParams)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := r.client.SendToAddress(addr, btcutil.Amount(amount))
+	if err != nil {
+		return "", err
+	}
+	return txHash.String(), nil
+}
+
+// RefundService send the refunds approved by operator
+type RefundService struct {
+	service.BaseService
+
+	refunder types.BITCOINRefunder
+	// fee is the bitcoin tx fee(sats) deducted from the refund
+	fee int64
+
+	db  *gorm.DB
+	log log.Logger
+}
+
+// NewRefundService returns a new service instance.
+func NewRefundService(
+	refunder types.BITCOINRefunder,
+	fee int64,
+	db *gorm.DB,
+	logger log.Logger,
+) *RefundService {
+	rs := &RefundService{refunder: refunder, fee: fee, db: db, log: logger}
+	rs.BaseService = *service.NewBaseService(nil, RefundServiceName, rs)
+	return rs
+}
+
+// OnStart
+func (rs *RefundService) OnStart() error {
+	if !rs.db.Migrator().HasTable(&model.Refund{}) {
+		err := rs.db.AutoMigrate(&model.Refund{})
+		if err != nil {
+			rs.log.Errorw("refund create table", "error", err.Error())
+			return err
+		}
+	}
+
+	// the first heartbeat signals the loop started
+	health.Beat(RefundServiceName)
+	ticker := time.NewTicker(BatchRefundWaitTime)
+	for {
+		<-ticker.C
+		ticker.Reset(BatchRefundWaitTime)
+		health.Beat(RefundServiceName)
+
+		var refunds []model.Refund
+		err := rs.db.
+			Where(fmt.Sprintf("%s = ?", model.Refund{}.Column().Status), model.RefundStatusApproved).
+			Limit(BatchRefundLimit).
+			Find(&refunds).Error
+		if err != nil {
+			rs.log.Errorw("failed find approved refund from db", "error", err)
+			continue
+		}
+		for _, refund := range refunds {
+			if err := rs.HandleRefund(refund); err != nil {
+				rs.log.Errorw("handle refund failed", "error", err, "refund", refund)
+			}
+		}
+	}
+}
+
+// HandleRefund send the approved refund
+func (rs *RefundService) HandleRefund(refund model.Refund) error {
+	refundValue := new(big.Int).Sub(refund.Amount.BigInt(), big.NewInt(rs.fee))
+	if refundValue.Sign() <= 0 || !refundValue.IsInt64() {
+		return rs.updateRefund(refund.ID, model.RefundStatusApproved, map[string]interface{}{
+			model.Refund{}.Column().Status: model.RefundStatusFailed,
+			model.Refund{}.Column().Remark: fmt.Sprintf("amount %s not enough to pay refund fee %d", refund.Amount, rs.fee),
+		})
+	}
+
+	// mark sending first, a refund stays sending after a crash is never sent twice
+	err := rs.updateRefund(refund.ID, model.RefundStatusApproved, map[string]interface{}{
+		model.Refund{}.Column().Status:      model.RefundStatusSending,
+		model.Refund{}.Column().RefundValue: model.NewBigInt(refundValue),
+	})
+	if err != nil {
+		return err
+	}
+
+	txHash, err := rs.refunder.Refund(refund.ToAddress, refundValue.Int64())
+	if err != nil {
+		rs.log.Errorw("send refund tx err",
+			"error", err.Error(),
+			"btcTxHash", refund.BtcTxHash,
+			"toAddress", refund.ToAddress)
+		return rs.updateRefund(refund.ID, model.RefundStatusSending, map[string]interface{}{
+			model.Refund{}.Column().Status: model.RefundStatusFailed,
+			model.Refund{}.Column().Remark: err.Error(),
+		})
+	}
+	rs.log.Infow("send refund tx success",
+		"btcTxHash", refund.BtcTxHash,
+		"toAddress", refund.ToAddress,
+		"refundTxHash", txHash)
+	return rs.updateRefund(refund.ID, model.RefundStatusSending, map[string]interface{}{
+		model.Refund{}.Column().Status:       model.RefundStatusSent,
+		model.Refund{}.Column().RefundTxHash: txHash,
+	})
+}
+
+// updateRefund update the refund only if it is still in the status
+func (rs *RefundService) updateRefund(id int64, status int, updateFields map[string]interface{}) error {
+	result := rs.db.Model(&model.Refund{}).
+		Where(fmt.Sprintf("id = ? AND %s = ?", model.Refund{}.Column().Status), id, status).
+		Updates(updateFields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("refund %d status changed", id)
+	}
+	return nil
+}
+
+// ApproveRefund operator approve the refund of the rejected deposit, a failed refund can be approved again
+func ApproveRefund(db *gorm.DB, depositID int64, operator string, remark string) error {
+	return reviewRefund(db, depositID, operator, remark, model.RefundStatusApproved)
+}
+
+// RejectRefund operator reject the refund of the rejected deposit
+func RejectRefund(db *gorm.DB, depositID int64, operator string, remark string) error {
+	return reviewRefund(db, depositID, operator, remark, model.RefundStatusRejected)
+}
+
+func reviewRefund(db *gorm.DB, depositID int64, operator string, remark string, status int) error {
+	if operator == "" {
+		return fmt.Errorf("operator is empty")
+	}
+	result := db.Model(&model.Refund{}).
+		Where(fmt.Sprintf("%s = ? AND %s IN (?)", model.Refund{}.Column().DepositID, model.Refund{}.Column().Status),
+			depositID, []int{model.RefundStatusPendingApproval, model.RefundStatusFailed}).
+		Updates(map[string]interface{}{
+			model.Refund{}.Column().Status:   status,
+			model.Refund{}.Column().Operator: operator,
+			model.Refund{}.Column().Remark:   remark,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRefundNotReviewable
+	}
+	return nil
+}
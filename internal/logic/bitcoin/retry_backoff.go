@@ -0,0 +1,112 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+)
+
+const (
+	// retry error class
+	RetryClassNetwork             = "network"              // send tx failed, eg. rpc unavailable
+	RetryClassScreening           = "screening"            // compliance screening unavailable
+	RetryClassInsufficientBalance = "insufficient_balance" // contract insufficient balance
+	RetryClassGasInsufficient     = "gas_insufficient"     // from account gas insufficient
+	RetryClassThrottled           = "throttled"            // deposit exceeds the rate limit
+	RetryClassFailed              = "failed"               // deposit failed, eg. retry exceed max
+)
+
+var ErrRetryBackoffInvalid = errors.New("invalid retry backoff")
+
+// RetryBackoff exponential backoff of the error class, the delay doubles every attempt up to max
+type RetryBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns the delay before the attempt, attempt starts from 1
+func (b RetryBackoff) Delay(attempt int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// Next returns the delay before the next attempt, false if no attempt left after the attempts, max attempts 0 means unlimited
+func (b RetryBackoff) Next(attempts int, maxAttempts int64) (time.Duration, bool) {
+	if maxAttempts > 0 && int64(attempts) >= maxAttempts {
+		return 0, false
+	}
+	return b.Delay(attempts), true
+}
+
+// DefaultRetryBackoffs the default backoff of every error class
+func DefaultRetryBackoffs() map[string]RetryBackoff {
+	return map[string]RetryBackoff{
+		RetryClassNetwork:             {Base: 10 * time.Second, Max: 10 * time.Minute},
+		RetryClassScreening:           {Base: 30 * time.Second, Max: 10 * time.Minute},
+		RetryClassInsufficientBalance: {Base: time.Minute, Max: time.Hour},
+		RetryClassGasInsufficient:     {Base: time.Minute, Max: time.Hour},
+		RetryClassThrottled:           {Base: time.Minute, Max: 15 * time.Minute},
+		RetryClassFailed:              {Base: time.Hour, Max: 24 * time.Hour},
+	}
+}
+
+// ParseRetryBackoffs parse retry backoffs over the defaults, item format: <class>:<base>:<max>
+func ParseRetryBackoffs(items []string) (map[string]RetryBackoff, error) {
+	backoffs := DefaultRetryBackoffs()
+	for _, item := range items {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%w: %s", ErrRetryBackoffInvalid, item)
+		}
+		if _, ok := backoffs[parts[0]]; !ok {
+			return nil, fmt.Errorf("%w: unknown class %s", ErrRetryBackoffInvalid, item)
+		}
+		base, err := time.ParseDuration(parts[1])
+		if err != nil || base <= 0 {
+			return nil, fmt.Errorf("%w: base %s", ErrRetryBackoffInvalid, item)
+		}
+		maxDelay, err := time.ParseDuration(parts[2])
+		if err != nil || maxDelay < base {
+			return nil, fmt.Errorf("%w: max %s", ErrRetryBackoffInvalid, item)
+		}
+		backoffs[parts[0]] = RetryBackoff{Base: base, Max: maxDelay}
+	}
+	return backoffs, nil
+}
+
+// RetryClass returns the retry error class of the deposit handle result, empty if no retry
+func RetryClass(b2TxStatus int, err error) string {
+	switch b2TxStatus {
+	case model.DepositB2TxStatusPending:
+		if errors.Is(err, ErrScreeningUnavailable) {
+			return RetryClassScreening
+		}
+		if err != nil {
+			return RetryClassNetwork
+		}
+	case model.DepositB2TxStatusInFlight:
+		if err != nil {
+			return RetryClassNetwork
+		}
+	case model.DepositB2TxStatusDropped:
+		return RetryClassNetwork
+	case model.DepositB2TxStatusInsufficientBalance:
+		return RetryClassInsufficientBalance
+	case model.DepositB2TxStatusFromAccountGasInsufficient:
+		return RetryClassGasInsufficient
+	case model.DepositB2TxStatusThrottled:
+		return RetryClassThrottled
+	case model.DepositB2TxStatusFailed:
+		return RetryClassFailed
+	}
+	return ""
+}
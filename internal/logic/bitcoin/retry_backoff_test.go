@@ -0,0 +1,58 @@
+package bitcoin_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBackoffDelay(t *testing.T) {
+	backoff := bitcoin.RetryBackoff{Base: 10 * time.Second, Max: time.Minute}
+	require.Equal(t, 10*time.Second, backoff.Delay(1))
+	require.Equal(t, 20*time.Second, backoff.Delay(2))
+	require.Equal(t, 40*time.Second, backoff.Delay(3))
+	require.Equal(t, time.Minute, backoff.Delay(4))
+	require.Equal(t, time.Minute, backoff.Delay(1000))
+}
+
+func TestParseRetryBackoffs(t *testing.T) {
+	backoffs, err := bitcoin.ParseRetryBackoffs([]string{"network:5s:5m", "failed:30m:12h"})
+	require.NoError(t, err)
+	require.Equal(t, bitcoin.RetryBackoff{Base: 5 * time.Second, Max: 5 * time.Minute}, backoffs[bitcoin.RetryClassNetwork])
+	require.Equal(t, bitcoin.RetryBackoff{Base: 30 * time.Minute, Max: 12 * time.Hour}, backoffs[bitcoin.RetryClassFailed])
+	require.Equal(t, bitcoin.DefaultRetryBackoffs()[bitcoin.RetryClassThrottled], backoffs[bitcoin.RetryClassThrottled])
+
+	for _, item := range []string{
+		"network:5s",
+		"timeout:5s:5m",
+		"network:5:5m",
+		"network:0s:5m",
+		"network:5m:5s",
+	} {
+		_, err := bitcoin.ParseRetryBackoffs([]string{item})
+		require.ErrorIs(t, err, bitcoin.ErrRetryBackoffInvalid, item)
+	}
+}
+
+func TestRetryClass(t *testing.T) {
+	require.Equal(t, bitcoin.RetryClassNetwork, bitcoin.RetryClass(model.DepositB2TxStatusPending, errors.New("connection refused")))
+	require.Equal(t, bitcoin.RetryClassScreening, bitcoin.RetryClass(model.DepositB2TxStatusPending, bitcoin.ErrScreeningUnavailable))
+	require.Equal(t, bitcoin.RetryClassInsufficientBalance,
+		bitcoin.RetryClass(model.DepositB2TxStatusInsufficientBalance, bitcoin.ErrBrdigeDepositContractInsufficientBalance))
+	require.Equal(t, bitcoin.RetryClassGasInsufficient,
+		bitcoin.RetryClass(model.DepositB2TxStatusFromAccountGasInsufficient, bitcoin.ErrBridgeFromGasInsufficient))
+	require.Equal(t, bitcoin.RetryClassThrottled, bitcoin.RetryClass(model.DepositB2TxStatusThrottled, nil))
+	require.Equal(t, bitcoin.RetryClassFailed, bitcoin.RetryClass(model.DepositB2TxStatusFailed, errors.New("retry exceed max")))
+	require.Equal(t, bitcoin.RetryClassNetwork, bitcoin.RetryClass(model.DepositB2TxStatusInFlight, bitcoin.ErrDepositBroadcast))
+	require.Equal(t, "", bitcoin.RetryClass(model.DepositB2TxStatusInFlight, nil))
+	require.Equal(t, "", bitcoin.RetryClass(model.DepositB2TxStatusPending, nil))
+	require.Equal(t, bitcoin.RetryClassNetwork, bitcoin.RetryClass(model.DepositB2TxStatusDropped, nil))
+	require.Equal(t, "", bitcoin.RetryClass(model.DepositB2TxStatusSuccess, nil))
+	require.Equal(t, "", bitcoin.RetryClass(model.DepositB2TxStatusTxHashExist, bitcoin.ErrBrdigeDepositTxHashExist))
+	// the inexact amount is never converted by retry
+	require.Equal(t, "", bitcoin.RetryClass(model.DepositB2TxStatusAmountInexact, bitcoin.ErrAmountInexact))
+}
@@ -0,0 +1,148 @@
+package bitcoin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+const DefaultScreeningReloadInterval = 60 * time.Second
+
+var (
+	ErrComplianceHold       = errors.New("deposit held by compliance screening")
+	ErrScreeningUnavailable = errors.New("compliance screening unavailable")
+	ErrComplianceNotHeld    = errors.New("deposit is not in compliance hold")
+)
+
+// BlocklistScreener screens addresses against the block list files,
+// each line of the file is an address, lines start with # are comments.
+// The files are reloaded when modified.
+type BlocklistScreener struct {
+	files          []string
+	reloadInterval time.Duration
+
+	mu         sync.Mutex
+	addresses  map[string]string // address -> block list name
+	modTimes   map[string]time.Time
+	lastReload time.Time
+}
+
+// NewBlocklistScreener new block list screener, the files are loaded immediately
+func NewBlocklistScreener(files []string, reloadInterval time.Duration) (*BlocklistScreener, error) {
+	if reloadInterval <= 0 {
+		reloadInterval = DefaultScreeningReloadInterval
+	}
+	s := &BlocklistScreener{
+		files:          files,
+		reloadInterval: reloadInterval,
+		addresses:      make(map[string]string),
+		modTimes:       make(map[string]time.Time),
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Screen implements types.AddressScreener
+func (s *BlocklistScreener) Screen(addresses []string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.lastReload) >= s.reloadInterval {
+		// keep screening with the old lists is not allowed, the deposit waits until reload succeed
+		if err := s.reload(); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrScreeningUnavailable, err.Error())
+		}
+	}
+	for _, address := range addresses {
+		if list, ok := s.addresses[normalizeScreeningAddress(address)]; ok {
+			return fmt.Sprintf("address %s hit block list %s", address, list), nil
+		}
+	}
+	return "", nil
+}
+
+// reload loads all files if any file modified
+func (s *BlocklistScreener) reload() error {
+	modified := false
+	modTimes := make(map[string]time.Time, len(s.files))
+	for _, file := range s.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+		if !info.ModTime().Equal(s.modTimes[file]) {
+			modified = true
+		}
+	}
+	if modified {
+		addresses := make(map[string]string)
+		for _, file := range s.files {
+			if err := loadBlocklist(file, addresses); err != nil {
+				return err
+			}
+		}
+		s.addresses = addresses
+		s.modTimes = modTimes
+	}
+	s.lastReload = time.Now()
+	return nil
+}
+
+func loadBlocklist(file string, addresses map[string]string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := filepath.Base(file)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addresses[normalizeScreeningAddress(line)] = name
+	}
+	return scanner.Err()
+}
+
+// normalizeScreeningAddress evm and bech32 addresses are case insensitive, base58 addresses are not
+func normalizeScreeningAddress(address string) string {
+	lower := strings.ToLower(address)
+	for _, prefix := range []string{"0x", "bc1", "tb1", "bcrt1"} {
+		if strings.HasPrefix(lower, prefix) {
+			return lower
+		}
+	}
+	return address
+}
+
+// ReleaseComplianceHold operator release the deposit held by compliance screening,
+// the released deposit is bridged without screening again
+func ReleaseComplianceHold(db *gorm.DB, depositID int64, operator string, remark string) error {
+	if operator == "" {
+		return fmt.Errorf("operator is empty")
+	}
+	return TransitDeposit(db, depositID, func(d *model.Deposit) error {
+		if d.B2TxStatus != model.DepositB2TxStatusComplianceHold {
+			return ErrComplianceNotHeld
+		}
+		return nil
+	}, map[string]interface{}{
+		model.Deposit{}.Column().B2TxStatus:         model.DepositB2TxStatusPending,
+		model.Deposit{}.Column().ComplianceReleased: true,
+		model.Deposit{}.Column().ComplianceOperator: operator,
+		model.Deposit{}.Column().ComplianceRemark:   remark,
+	}, operator, remark)
+}
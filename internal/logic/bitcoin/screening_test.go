@@ -0,0 +1,52 @@
+package bitcoin_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklistScreener(t *testing.T) {
+	dir := t.TempDir()
+	ofac := filepath.Join(dir, "ofac.txt")
+	local := filepath.Join(dir, "local.txt")
+	require.NoError(t, os.WriteFile(ofac, []byte("# ofac list\nTB1QUKXC3SY3S3K5N5Z9CXT3XYYWGCJMP2TZUDLZ2N\n\n"), 0o600))
+	require.NoError(t, os.WriteFile(local, []byte("0x7eE2b84B8e3F7e04d4e0F4c4fb53b1f5f3C6c4aA\n"), 0o600))
+
+	screener, err := bitcoin.NewBlocklistScreener([]string{ofac, local}, time.Nanosecond)
+	require.NoError(t, err)
+
+	reason, err := screener.Screen([]string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz"})
+	require.NoError(t, err)
+	require.Empty(t, reason)
+
+	reason, err = screener.Screen([]string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz", "tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n"})
+	require.NoError(t, err)
+	require.Equal(t, "address tb1qukxc3sy3s3k5n5z9cxt3xyywgcjmp2tzudlz2n hit block list ofac.txt", reason)
+
+	reason, err = screener.Screen([]string{"0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa"})
+	require.NoError(t, err)
+	require.Equal(t, "address 0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa hit block list local.txt", reason)
+
+	// hot reload the modified file
+	require.NoError(t, os.WriteFile(local, []byte("tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz\n"), 0o600))
+	require.NoError(t, os.Chtimes(local, time.Now(), time.Now().Add(time.Minute)))
+	reason, err = screener.Screen([]string{"0x7ee2b84b8e3f7e04d4e0f4c4fb53b1f5f3c6c4aa"})
+	require.NoError(t, err)
+	require.Empty(t, reason)
+	reason, err = screener.Screen([]string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz"})
+	require.NoError(t, err)
+	require.Equal(t, "address tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz hit block list local.txt", reason)
+
+	// screening is unavailable when reload failed
+	require.NoError(t, os.Remove(local))
+	_, err = screener.Screen([]string{"tb1qgm39cu009lyvq93afx47pp4h9wxq5x92lxxgnz"})
+	require.ErrorIs(t, err, bitcoin.ErrScreeningUnavailable)
+
+	_, err = bitcoin.NewBlocklistScreener([]string{local}, time.Minute)
+	require.Error(t, err)
+}
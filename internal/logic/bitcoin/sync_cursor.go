@@ -0,0 +1,62 @@
+package bitcoin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"gorm.io/gorm"
+)
+
+// LoadSyncCursor returns the cursor of the name, a new cursor starts from the first deposit
+func LoadSyncCursor(db *gorm.DB, name string) (model.SyncCursor, error) {
+	cursor := model.SyncCursor{Name: name}
+	err := db.Where(fmt.Sprintf("%s = ?", model.SyncCursor{}.Column().Name), name).
+		FirstOrCreate(&cursor).Error
+	return cursor, err
+}
+
+// SaveSyncCursor saves the cursor progress
+func SaveSyncCursor(db *gorm.DB, cursor *model.SyncCursor) error {
+	return db.Model(cursor).Updates(map[string]interface{}{
+		model.SyncCursor{}.Column().CursorTime: cursor.CursorTime,
+		model.SyncCursor{}.Column().CursorID:   cursor.CursorID,
+	}).Error
+}
+
+// DepositsAfterCursor scopes the deposits after the cursor in (updated_at, id) order.
+// The lookback re-scans the deposits updated just before the cursor,
+// which may be committed after the cursor moved by concurrent transactions.
+// Only the first page looks back, the next pages start strictly after the last row of the previous page.
+func DepositsAfterCursor(cursor model.SyncCursor, lookback time.Duration, limit int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where(
+				fmt.Sprintf("(%s.updated_at, %s.id) > (?, ?)", model.Deposit{}.TableName(), model.Deposit{}.TableName()),
+				cursor.CursorTime.Add(-lookback), cursor.CursorID,
+			).
+			Order(fmt.Sprintf("%s.updated_at, %s.id", model.Deposit{}.TableName(), model.Deposit{}.TableName())).
+			Limit(limit)
+	}
+}
+
+// DepositEventsAfterCursor scopes the deposit events after the cursor in (created_at, id) order, re-scans the lookback as deposits
+func DepositEventsAfterCursor(cursor model.SyncCursor, lookback time.Duration, limit int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where(
+				fmt.Sprintf("(%s.created_at, %s.id) > (?, ?)", model.DepositEvent{}.TableName(), model.DepositEvent{}.TableName()),
+				cursor.CursorTime.Add(-lookback), cursor.CursorID,
+			).
+			Order(fmt.Sprintf("%s.created_at, %s.id", model.DepositEvent{}.TableName(), model.DepositEvent{}.TableName())).
+			Limit(limit)
+	}
+}
+
+// AdvanceSyncCursor moves the cursor forward to the row, the rows re-scanned by the lookback never move it back
+func AdvanceSyncCursor(cursor *model.SyncCursor, rowTime time.Time, rowID int64) {
+	if rowTime.After(cursor.CursorTime) || (rowTime.Equal(cursor.CursorTime) && rowID > cursor.CursorID) {
+		cursor.CursorTime = rowTime
+		cursor.CursorID = rowID
+	}
+}
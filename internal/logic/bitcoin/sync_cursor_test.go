@@ -0,0 +1,25 @@
+package bitcoin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvanceSyncCursor(t *testing.T) {
+	now := time.Now()
+	cursor := model.SyncCursor{CursorTime: now, CursorID: 10}
+
+	// the lookback rows never move the cursor back
+	bitcoin.AdvanceSyncCursor(&cursor, now.Add(-time.Second), 20)
+	bitcoin.AdvanceSyncCursor(&cursor, now, 9)
+	require.Equal(t, model.SyncCursor{CursorTime: now, CursorID: 10}, cursor)
+
+	bitcoin.AdvanceSyncCursor(&cursor, now, 11)
+	require.Equal(t, model.SyncCursor{CursorTime: now, CursorID: 11}, cursor)
+	bitcoin.AdvanceSyncCursor(&cursor, now.Add(time.Second), 1)
+	require.Equal(t, model.SyncCursor{CursorTime: now.Add(time.Second), CursorID: 1}, cursor)
+}
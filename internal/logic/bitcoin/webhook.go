@@ -0,0 +1,266 @@
+package bitcoin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// webhook event type
+	WebhookEventDetected  = "deposit.detected"  // deposit indexed
+	WebhookEventConfirmed = "deposit.confirmed" // deposit passed the checks, mint tx submitted to b2
+	WebhookEventMinted    = "deposit.minted"    // minted by the bridge contract or the eoa transfer
+	WebhookEventFailed    = "deposit.failed"    // mint failed or deposit rejected
+	WebhookEventHeld      = "deposit.held"      // held by compliance screening or wait operator approval
+)
+
+const (
+	// webhook request headers, the signature is hex HMAC-SHA256 of "<timestamp>.<body>"
+	WebhookHeaderEvent     = "X-B2-Indexer-Event"
+	WebhookHeaderDelivery  = "X-B2-Indexer-Delivery"
+	WebhookHeaderTimestamp = "X-B2-Indexer-Timestamp"
+	WebhookHeaderSignature = "X-B2-Indexer-Signature"
+
+	WebhookTimeout = 10 * time.Second
+)
+
+// WebhookRetryBackoff webhook delivery backoff by attempts
+var WebhookRetryBackoff = RetryBackoff{Base: 30 * time.Second, Max: time.Hour}
+
+var (
+	ErrWebhookEndpointInvalid  = errors.New("invalid webhook endpoint")
+	ErrWebhookNotRedeliverable = errors.New("webhook delivery is not dead letter")
+	ErrWebhookLeaseLost        = errors.New("webhook delivery lease lost")
+)
+
+var webhookEventTypes = []string{
+	WebhookEventDetected,
+	WebhookEventConfirmed,
+	WebhookEventMinted,
+	WebhookEventFailed,
+	WebhookEventHeld,
+}
+
+// webhookFailedStatuses the B2TxStatus notified as failed
+var webhookFailedStatuses = []int{
+	model.DepositB2TxStatusFailed,
+	model.DepositB2TxStatusWaitMinedFailed,
+	model.DepositB2TxStatusWaitMinedStatusFailed,
+	model.DepositB2TxStatusContextDeadlineExceeded,
+	model.DepositB2TxStatusFeeExceedsAmount,
+	model.DepositB2TxStatusAmountInexact,
+	model.DepositB2TxStatusRejected,
+	model.DepositB2TxStatusApprovalRejected,
+}
+
+// webhookEoaFailedStatuses the B2EoaTxStatus notified as failed
+var webhookEoaFailedStatuses = []int{
+	model.DepositB2EoaTxStatusFailed,
+	model.DepositB2EoaTxStatusWaitMinedFailed,
+	model.DepositB2EoaTxStatusRejected,
+	model.DepositB2EoaTxStatusContextDeadlineExceeded,
+}
+
+// WebhookEndpoint webhook endpoint and its event filter, empty events means all events
+type WebhookEndpoint struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+// Accepts returns whether the endpoint subscribes the event type
+func (e WebhookEndpoint) Accepts(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, event := range e.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWebhookEndpoints parse webhook endpoints, item format: <url>|<secret>|<events>, events separated by ';'
+func ParseWebhookEndpoints(items []string) ([]WebhookEndpoint, error) {
+	endpoints := make([]WebhookEndpoint, 0, len(items))
+	for _, item := range items {
+		parts := strings.Split(strings.TrimSpace(item), "|")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookEndpointInvalid, item)
+		}
+		if !strings.HasPrefix(parts[0], "http://") && !strings.HasPrefix(parts[0], "https://") {
+			return nil, fmt.Errorf("%w: url %s", ErrWebhookEndpointInvalid, parts[0])
+		}
+		if parts[1] == "" {
+			return nil, fmt.Errorf("%w: empty secret %s", ErrWebhookEndpointInvalid, parts[0])
+		}
+		endpoint := WebhookEndpoint{URL: parts[0], Secret: parts[1]}
+		for _, event := range strings.Split(parts[2], ";") {
+			event = strings.TrimSpace(event)
+			if event == "" {
+				continue
+			}
+			if !isWebhookEventType(event) {
+				return nil, fmt.Errorf("%w: unknown event %s", ErrWebhookEndpointInvalid, event)
+			}
+			endpoint.Events = append(endpoint.Events, event)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+func isWebhookEventType(eventType string) bool {
+	for _, v := range webhookEventTypes {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEventTypes returns the webhook event types of the deposit status transition
+func WebhookEventTypes(event model.DepositEvent) []string {
+	var types []string
+	if event.StatusType == model.DepositEventStatusEps {
+		// eps delivery is not a webhook event
+		return types
+	}
+	if event.StatusType == model.DepositEventStatusB2EoaTx {
+		switch {
+		case event.ToStatus == model.DepositB2EoaTxStatusSuccess:
+			types = append(types, WebhookEventMinted)
+		case event.ToStatus == model.DepositB2EoaTxStatusPendingApproval:
+			types = append(types, WebhookEventHeld)
+		case containsStatus(webhookEoaFailedStatuses, event.ToStatus):
+			types = append(types, WebhookEventFailed)
+		}
+		return types
+	}
+	if event.FromStatus == model.DepositStatusNone {
+		types = append(types, WebhookEventDetected)
+	}
+	switch {
+	case event.FromStatus == event.ToStatus:
+		// retried with the error, not a transition
+	case event.ToStatus == model.DepositB2TxStatusInFlight:
+		types = append(types, WebhookEventConfirmed)
+	case event.ToStatus == model.DepositB2TxStatusSuccess:
+		types = append(types, WebhookEventMinted)
+	case event.ToStatus == model.DepositB2TxStatusComplianceHold,
+		event.ToStatus == model.DepositB2TxStatusPendingApproval:
+		types = append(types, WebhookEventHeld)
+	case containsStatus(webhookFailedStatuses, event.ToStatus):
+		types = append(types, WebhookEventFailed)
+	}
+	return types
+}
+
+func containsStatus(statuses []int, status int) bool {
+	for _, v := range statuses {
+		if v == status {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookPayload the webhook request body
+type WebhookPayload struct {
+	ID        string             `json:"id"`
+	Type      string             `json:"type"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      WebhookDepositData `json:"data"`
+}
+
+// WebhookDepositData the deposit and its status transition of the webhook event
+type WebhookDepositData struct {
+	DepositID  int64  `json:"deposit_id"`
+	BtcTxHash  string `json:"btc_tx_hash"`
+	BtcFrom    string `json:"btc_from"`
+	BtcTo      string `json:"btc_to"`
+	BtcValue   string `json:"btc_value"`
+	StatusType string `json:"status_type"`
+	FromState  string `json:"from_state"`
+	ToState    string `json:"to_state"`
+	TxHash     string `json:"tx_hash"`
+	Error      string `json:"error"`
+}
+
+// NewWebhookPayload returns the webhook payload of the deposit event
+func NewWebhookPayload(eventType string, event model.DepositEvent, deposit model.Deposit) WebhookPayload {
+	return WebhookPayload{
+		ID:        WebhookEventID(event.ID, eventType),
+		Type:      eventType,
+		CreatedAt: event.CreatedAt,
+		Data: WebhookDepositData{
+			DepositID:  deposit.ID,
+			BtcTxHash:  deposit.BtcTxHash,
+			BtcFrom:    deposit.BtcFrom,
+			BtcTo:      deposit.BtcTo,
+			BtcValue:   deposit.BtcValue.String(),
+			StatusType: event.StatusType,
+			FromState:  event.FromState,
+			ToState:    event.ToState,
+			TxHash:     event.TxHash,
+			Error:      event.Error,
+		},
+	}
+}
+
+// WebhookEventID the unique id of the webhook event, the same for every attempt and endpoint
+func WebhookEventID(depositEventID int64, eventType string) string {
+	return fmt.Sprintf("%d-%s", depositEventID, eventType)
+}
+
+// SignWebhook returns the hex HMAC-SHA256 signature of the timestamp and body
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook verifies the signature, and the timestamp is within the tolerance to reject replays
+func VerifyWebhook(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration, now time.Time) bool {
+	if tolerance > 0 {
+		diff := now.Sub(time.Unix(timestamp, 0))
+		if diff > tolerance || diff < -tolerance {
+			return false
+		}
+	}
+	expected := SignWebhook(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// SendWebhook posts the payload signed at the time to the endpoint, returns the response status code and body.
+// Non 2xx responses are failed.
+func SendWebhook(endpoint WebhookEndpoint, eventType string, eventID string, payload string, now time.Time) (int, string, error) {
+	timestamp := now.Unix()
+	resp, err := resty.New().SetTimeout(WebhookTimeout).R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader(WebhookHeaderEvent, eventType).
+		SetHeader(WebhookHeaderDelivery, eventID).
+		SetHeader(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10)).
+		SetHeader(WebhookHeaderSignature, SignWebhook(endpoint.Secret, timestamp, []byte(payload))).
+		SetBody(payload).
+		Post(endpoint.URL)
+	if err != nil {
+		return 0, "", err
+	}
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return resp.StatusCode(), resp.String(), errors.New(resp.Status())
+	}
+	return resp.StatusCode(), resp.String(), nil
+}
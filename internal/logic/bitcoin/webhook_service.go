@@ -0,0 +1,371 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/health"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/cometbft/cometbft/libs/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	WebhookServiceName = "BitcoinWebhookService"
+	// the heartbeat names of the enqueue and deliver loops
+	WebhookEnqueueHeartbeat = WebhookServiceName + "/enqueue"
+	WebhookDeliverHeartbeat = WebhookServiceName + "/deliver"
+
+	WebhookCursorName = "webhook"
+	// re-scan the deposit events committed late by concurrent transactions
+	WebhookCursorLookback = time.Minute
+	WebhookBatchLimit     = 100
+	// claim a few deliveries at a time, the lease outlasts their posts timing out
+	WebhookClaimLimit   = 10
+	WebhookLeaseTimeout = 2 * WebhookClaimLimit * WebhookTimeout
+	// WebhookResponseMaxLen the max stored length of the webhook response body
+	WebhookResponseMaxLen = 4096
+)
+
+// WebhookService enqueues the deposit lifecycle events to the webhook deliveries of the subscribed endpoints,
+// and delivers them with retries
+type WebhookService struct {
+	service.BaseService
+
+	endpoints   []WebhookEndpoint
+	maxAttempts int64
+	workerID    string
+	listener    *DepositListener
+	db          *gorm.DB
+	log         log.Logger
+}
+
+// NewWebhookService new webhook service
+func NewWebhookService(
+	cfg config.WebhookConfig,
+	listener *DepositListener,
+	db *gorm.DB,
+	logger log.Logger,
+) (*WebhookService, error) {
+	endpoints, err := ParseWebhookEndpoints(cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	w := &WebhookService{
+		endpoints:   endpoints,
+		maxAttempts: cfg.MaxAttempts,
+		workerID:    NewWorkerID(),
+		listener:    listener,
+		db:          db,
+		log:         logger,
+	}
+	w.BaseService = *service.NewBaseService(nil, WebhookServiceName, w)
+	return w, nil
+}
+
+func (w *WebhookService) OnStart() error {
+	// auto migrate also updates the existing table columns
+	err := w.db.AutoMigrate(&model.WebhookDelivery{})
+	if err != nil {
+		w.log.Errorw("webhook migrate table", "error", err.Error())
+		return err
+	}
+	if !w.db.Migrator().HasTable(&model.SyncCursor{}) {
+		err := w.db.AutoMigrate(&model.SyncCursor{})
+		if err != nil {
+			w.log.Errorw("webhook create table", "error", err.Error())
+			return err
+		}
+	}
+	// the enqueue scans the deposit events in cursor order
+	if !w.db.Migrator().HasIndex(&model.DepositEvent{}, model.DepositEventCursorIndex) {
+		err := w.db.Migrator().CreateIndex(&model.DepositEvent{}, model.DepositEventCursorIndex)
+		if err != nil {
+			w.log.Errorw("webhook create index", "error", err.Error())
+			return err
+		}
+	}
+	// the first heartbeats signal the loops started
+	health.Beat(WebhookEnqueueHeartbeat)
+	health.Beat(WebhookDeliverHeartbeat)
+	go func() {
+		wakeup := w.listener.Subscribe()
+		for w.wait(wakeup) {
+			health.Beat(WebhookEnqueueHeartbeat)
+			if err := w.Enqueue(); err != nil {
+				w.log.Errorw("webhook enqueue err", "error", err)
+			}
+		}
+	}()
+
+	wakeup := w.listener.Subscribe()
+	for w.wait(wakeup) {
+		health.Beat(WebhookDeliverHeartbeat)
+		if err := w.deliverDue(); err != nil {
+			w.log.Errorw("webhook deliver err", "error", err)
+		}
+	}
+	return nil
+}
+
+// Enqueue adds the deliveries of the deposit events after the cursor to the subscribed endpoints.
+// The re-scanned events are skipped by the unique endpoint, event and type.
+func (w *WebhookService) Enqueue() error {
+	cursor, err := LoadSyncCursor(w.db, WebhookCursorName)
+	if err != nil {
+		return err
+	}
+	// a new cursor starts from the latest deposit event, the history is not notified
+	if cursor.CursorID == 0 {
+		var latest []model.DepositEvent
+		if err := w.db.Order("id DESC").Limit(1).Find(&latest).Error; err != nil {
+			return err
+		}
+		if len(latest) > 0 {
+			cursor.CursorTime = latest[0].CreatedAt
+			cursor.CursorID = latest[0].ID
+			if err := SaveSyncCursor(w.db, &cursor); err != nil {
+				return err
+			}
+		}
+	}
+	// the pages after the first start strictly after the last event, the re-scanned events never page again
+	page, lookback := cursor, WebhookCursorLookback
+	for {
+		var events []model.DepositEvent
+		err := w.db.Model(&model.DepositEvent{}).
+			Scopes(DepositEventsAfterCursor(page, lookback, WebhookBatchLimit)).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		depositIDs := make([]int64, 0, len(events))
+		for _, event := range events {
+			depositIDs = append(depositIDs, event.DepositID)
+		}
+		var deposits []model.Deposit
+		err = w.db.Where("id IN ?", depositIDs).Find(&deposits).Error
+		if err != nil {
+			return err
+		}
+		depositMap := make(map[int64]model.Deposit, len(deposits))
+		for _, deposit := range deposits {
+			depositMap[deposit.ID] = deposit
+		}
+
+		var deliveries []model.WebhookDelivery
+		for _, event := range events {
+			for _, eventType := range WebhookEventTypes(event) {
+				payload, err := json.Marshal(NewWebhookPayload(eventType, event, depositMap[event.DepositID]))
+				if err != nil {
+					return err
+				}
+				for _, endpoint := range w.endpoints {
+					if !endpoint.Accepts(eventType) {
+						continue
+					}
+					deliveries = append(deliveries, model.WebhookDelivery{
+						Endpoint:       endpoint.URL,
+						DepositEventID: event.ID,
+						EventType:      eventType,
+						DepositID:      event.DepositID,
+						Payload:        string(payload),
+						Status:         model.WebhookDeliveryStatusPending,
+					})
+				}
+			}
+		}
+		err = w.db.Transaction(func(tx *gorm.DB) error {
+			if len(deliveries) > 0 {
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+					return err
+				}
+			}
+			AdvanceSyncCursor(&cursor, events[len(events)-1].CreatedAt, events[len(events)-1].ID)
+			return SaveSyncCursor(tx, &cursor)
+		})
+		if err != nil {
+			return err
+		}
+		page.CursorTime, page.CursorID = events[len(events)-1].CreatedAt, events[len(events)-1].ID
+		lookback = 0
+		if len(events) < WebhookBatchLimit {
+			return nil
+		}
+	}
+}
+
+// deliverDue delivers the pending webhooks due for the next attempt, claimed so that replicas never post the same one
+func (w *WebhookService) deliverDue() error {
+	defer func() {
+		if err := ReleaseWebhookLeases(w.db, w.workerID); err != nil {
+			w.log.Errorw("release webhook leases err", "error", err)
+		}
+	}()
+	for {
+		deliveries, err := ClaimWebhookDeliveries(w.db, w.workerID, WebhookLeaseTimeout, WebhookClaimLimit)
+		if err != nil {
+			return err
+		}
+		for _, v := range deliveries {
+			err := w.deliver(v)
+			if errors.Is(err, ErrWebhookLeaseLost) {
+				// the lease expired while posting, the attempt is recorded by the worker claimed it
+				w.log.Warnw("webhook delivery lease lost", "error", err, "id", v.ID)
+				continue
+			}
+			if err != nil {
+				w.log.Errorw("webhook save delivery err", "error", err, "id", v.ID)
+				return err
+			}
+		}
+		if len(deliveries) < WebhookClaimLimit {
+			return nil
+		}
+	}
+}
+
+// ClaimWebhookDeliveries claims the pending webhooks due for the next attempt which are not leased by other workers.
+// Rows locked by other workers are skipped, so that replicas never claim the same delivery.
+func ClaimWebhookDeliveries(db *gorm.DB, owner string, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(fmt.Sprintf("%s = ?", model.WebhookDelivery{}.Column().Status), model.WebhookDeliveryStatusPending).
+			Where(fmt.Sprintf("%s IS NULL OR %s <= ?", model.WebhookDelivery{}.Column().NextAttemptAt, model.WebhookDelivery{}.Column().NextAttemptAt), now).
+			Where(fmt.Sprintf("%s IS NULL OR %s < ?", model.WebhookDelivery{}.Column().LeaseExpiresAt, model.WebhookDelivery{}.Column().LeaseExpiresAt), now).
+			Order("id").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(deliveries))
+		for i := range deliveries {
+			ids = append(ids, deliveries[i].ID)
+			deliveries[i].LeaseOwner = owner
+			deliveries[i].LeaseExpiresAt = now.Add(lease)
+		}
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			UpdateColumns(map[string]interface{}{
+				model.WebhookDelivery{}.Column().LeaseOwner:     owner,
+				model.WebhookDelivery{}.Column().LeaseExpiresAt: now.Add(lease),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ReleaseWebhookLeases releases the webhook leases held by the worker
+func ReleaseWebhookLeases(db *gorm.DB, owner string) error {
+	return db.Model(&model.WebhookDelivery{}).
+		Where(fmt.Sprintf("%s = ?", model.WebhookDelivery{}.Column().LeaseOwner), owner).
+		UpdateColumns(map[string]interface{}{
+			model.WebhookDelivery{}.Column().LeaseOwner:     "",
+			model.WebhookDelivery{}.Column().LeaseExpiresAt: nil,
+		}).Error
+}
+
+// deliver posts the webhook and records the attempt, failed deliveries back off until dead letter
+func (w *WebhookService) deliver(v model.WebhookDelivery) error {
+	var (
+		statusCode int
+		body       string
+		err        = fmt.Errorf("webhook endpoint %s not configured", v.Endpoint)
+	)
+	for _, endpoint := range w.endpoints {
+		if endpoint.URL == v.Endpoint {
+			statusCode, body, err = SendWebhook(endpoint, v.EventType, WebhookEventID(v.DepositEventID, v.EventType), v.Payload, time.Now())
+			break
+		}
+	}
+	if len(body) > WebhookResponseMaxLen {
+		body = body[:WebhookResponseMaxLen]
+	}
+	if err != nil {
+		w.log.Errorw("webhook post err", "error", err, "id", v.ID, "endpoint", v.Endpoint, "attempt", v.Attempts+1)
+	} else {
+		w.log.Infow("webhook post success", "id", v.ID, "endpoint", v.Endpoint, "event", v.EventType)
+	}
+	return SaveWebhookDelivery(w.db, &v, statusCode, body, err, w.maxAttempts)
+}
+
+// wait waits a minute, or until the deposits change. Returns false when the service stops
+func (w *WebhookService) wait(wakeup <-chan struct{}) bool {
+	select {
+	case <-time.After(time.Minute):
+	case <-wakeup:
+	case <-w.Quit():
+		return false
+	}
+	return true
+}
+
+// SaveWebhookDelivery records the delivery attempt result and releases the lease,
+// the attempt is not recorded if the lease was taken over by another worker
+func SaveWebhookDelivery(db *gorm.DB, delivery *model.WebhookDelivery, statusCode int, body string, deliverErr error, maxAttempts int64) error {
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	fields := map[string]interface{}{
+		model.WebhookDelivery{}.Column().Attempts:       attempts,
+		model.WebhookDelivery{}.Column().LastStatusCode: statusCode,
+		model.WebhookDelivery{}.Column().LastResponse:   body,
+		model.WebhookDelivery{}.Column().LastError:      "",
+		model.WebhookDelivery{}.Column().LeaseOwner:     "",
+		model.WebhookDelivery{}.Column().LeaseExpiresAt: nil,
+	}
+	if deliverErr == nil {
+		fields[model.WebhookDelivery{}.Column().Status] = model.WebhookDeliveryStatusSuccess
+		fields[model.WebhookDelivery{}.Column().DeliveredAt] = now
+	} else {
+		fields[model.WebhookDelivery{}.Column().LastError] = deliverErr.Error()
+		if delay, ok := WebhookRetryBackoff.Next(attempts, maxAttempts); ok {
+			fields[model.WebhookDelivery{}.Column().NextAttemptAt] = now.Add(delay)
+		} else {
+			fields[model.WebhookDelivery{}.Column().Status] = model.WebhookDeliveryStatusDeadLetter
+		}
+	}
+	result := db.Model(&model.WebhookDelivery{}).
+		Where(fmt.Sprintf("id = ? AND %s = ?", model.WebhookDelivery{}.Column().LeaseOwner), delivery.ID, delivery.LeaseOwner).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: webhook delivery %d", ErrWebhookLeaseLost, delivery.ID)
+	}
+	return nil
+}
+
+// RedeliverWebhook operator redeliver the dead letter webhook, the attempts restart
+func RedeliverWebhook(db *gorm.DB, id int64) error {
+	result := db.Model(&model.WebhookDelivery{}).
+		Where(fmt.Sprintf("id = ? AND %s = ?", model.WebhookDelivery{}.Column().Status),
+			id, model.WebhookDeliveryStatusDeadLetter).
+		Updates(map[string]interface{}{
+			model.WebhookDelivery{}.Column().Status:        model.WebhookDeliveryStatusPending,
+			model.WebhookDelivery{}.Column().Attempts:      0,
+			model.WebhookDelivery{}.Column().NextAttemptAt: time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotRedeliverable
+	}
+	return nil
+}
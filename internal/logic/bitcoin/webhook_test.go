@@ -0,0 +1,244 @@
+package bitcoin_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWebhookEndpoints(t *testing.T) {
+	endpoints, err := bitcoin.ParseWebhookEndpoints([]string{
+		"http://127.0.0.1:9095/hook|secret|deposit.minted;deposit.failed",
+		"https://partner.example.com/b2|partner-secret|",
+	})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	require.Equal(t, "http://127.0.0.1:9095/hook", endpoints[0].URL)
+	require.Equal(t, "secret", endpoints[0].Secret)
+	require.True(t, endpoints[0].Accepts(bitcoin.WebhookEventMinted))
+	require.False(t, endpoints[0].Accepts(bitcoin.WebhookEventDetected))
+	require.True(t, endpoints[1].Accepts(bitcoin.WebhookEventHeld))
+
+	for _, item := range []string{
+		"http://127.0.0.1/hook|secret",
+		"127.0.0.1/hook|secret|",
+		"http://127.0.0.1/hook||",
+		"http://127.0.0.1/hook|secret|deposit.unknown",
+	} {
+		_, err := bitcoin.ParseWebhookEndpoints([]string{item})
+		require.ErrorIs(t, err, bitcoin.ErrWebhookEndpointInvalid, item)
+	}
+}
+
+func TestWebhookEventTypes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    model.DepositEvent
+		expected []string
+	}{
+		{
+			name:     "detected",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositStatusNone, ToStatus: model.DepositB2TxStatusPending},
+			expected: []string{bitcoin.WebhookEventDetected},
+		},
+		{
+			name:     "detected and rejected",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositStatusNone, ToStatus: model.DepositB2TxStatusRejected},
+			expected: []string{bitcoin.WebhookEventDetected, bitcoin.WebhookEventFailed},
+		},
+		{
+			name:     "confirmed",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusPending, ToStatus: model.DepositB2TxStatusInFlight},
+			expected: []string{bitcoin.WebhookEventConfirmed},
+		},
+		{
+			name:     "minted",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusInFlight, ToStatus: model.DepositB2TxStatusSuccess},
+			expected: []string{bitcoin.WebhookEventMinted},
+		},
+		{
+			name:     "held",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusPending, ToStatus: model.DepositB2TxStatusComplianceHold},
+			expected: []string{bitcoin.WebhookEventHeld},
+		},
+		{
+			name:     "retried error",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2Tx, FromStatus: model.DepositB2TxStatusFailed, ToStatus: model.DepositB2TxStatusFailed},
+			expected: nil,
+		},
+		{
+			name:     "eps delivered",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusEps, FromStatus: model.EspStatus, ToStatus: model.EspStatusSuccess},
+			expected: nil,
+		},
+		{
+			name:     "eoa minted",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2EoaTx, FromStatus: model.DepositB2EoaTxStatusPending, ToStatus: model.DepositB2EoaTxStatusSuccess},
+			expected: []string{bitcoin.WebhookEventMinted},
+		},
+		{
+			name:     "eoa created",
+			event:    model.DepositEvent{StatusType: model.DepositEventStatusB2EoaTx, FromStatus: model.DepositStatusNone, ToStatus: model.DepositB2EoaTxStatusPending},
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, bitcoin.WebhookEventTypes(tc.event))
+		})
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":"1-deposit.minted"}`)
+	signature := bitcoin.SignWebhook("secret", now.Unix(), body)
+	require.True(t, bitcoin.VerifyWebhook("secret", now.Unix(), body, signature, 5*time.Minute, now))
+	require.False(t, bitcoin.VerifyWebhook("other", now.Unix(), body, signature, 5*time.Minute, now))
+	require.False(t, bitcoin.VerifyWebhook("secret", now.Unix(), []byte(`{}`), signature, 5*time.Minute, now))
+	// replayed
+	require.False(t, bitcoin.VerifyWebhook("secret", now.Unix(), body, signature, 5*time.Minute, now.Add(10*time.Minute)))
+}
+
+func TestSendWebhook(t *testing.T) {
+	payload := `{"id":"1-deposit.minted","type":"deposit.minted"}`
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, string(body))
+		require.Equal(t, bitcoin.WebhookEventMinted, r.Header.Get(bitcoin.WebhookHeaderEvent))
+		require.Equal(t, "1-deposit.minted", r.Header.Get(bitcoin.WebhookHeaderDelivery))
+		timestamp, err := strconv.ParseInt(r.Header.Get(bitcoin.WebhookHeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.True(t, bitcoin.VerifyWebhook("secret", timestamp, body, r.Header.Get(bitcoin.WebhookHeaderSignature), time.Minute, time.Now()))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	endpoint := bitcoin.WebhookEndpoint{URL: server.URL, Secret: "secret"}
+	code, body, err := bitcoin.SendWebhook(endpoint, bitcoin.WebhookEventMinted, "1-deposit.minted", payload, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", body)
+
+	status = http.StatusInternalServerError
+	code, _, err = bitcoin.SendWebhook(endpoint, bitcoin.WebhookEventMinted, "1-deposit.minted", payload, time.Now())
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestLocalWebhookEnqueue(t *testing.T) {
+	db := localDB(t)
+	service, err := bitcoin.NewWebhookService(config.WebhookConfig{
+		Endpoints: []string{"http://127.0.0.1:9095/hook|secret|"},
+	}, nil, db, log.NewNopLogger())
+	require.NoError(t, err)
+	deposit := createDeposit(t, db, model.DepositB2TxStatusPending)
+	newEvents := func(n int, createdAt time.Time) []model.DepositEvent {
+		events := make([]model.DepositEvent, n)
+		for i := range events {
+			events[i] = model.DepositEvent{
+				CreatedAt:  createdAt,
+				DepositID:  deposit.ID,
+				BtcTxHash:  deposit.BtcTxHash,
+				StatusType: model.DepositEventStatusB2Tx,
+				FromStatus: model.DepositStatusNone,
+				ToStatus:   model.DepositB2TxStatusPending,
+			}
+		}
+		require.NoError(t, db.Create(&events).Error)
+		return events
+	}
+	enqueue := func() {
+		done := make(chan error, 1)
+		go func() { done <- service.Enqueue() }()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("enqueue not finished")
+		}
+	}
+	countDeliveries := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&model.WebhookDelivery{}).Count(&count).Error)
+		return count
+	}
+
+	// a new cursor starts from the latest event
+	newEvents(1, time.Now())
+	enqueue()
+	require.Zero(t, countDeliveries())
+
+	// more events than a batch inside the lookback window
+	synced := newEvents(bitcoin.WebhookBatchLimit+50, time.Now())
+	enqueue()
+	require.Equal(t, int64(len(synced)), countDeliveries())
+
+	// the late committed event inside the window and the new event
+	late := newEvents(1, time.Now().Add(-bitcoin.WebhookCursorLookback/2))
+	latest := newEvents(1, time.Now())
+	enqueue()
+	require.Equal(t, int64(len(synced)+2), countDeliveries())
+	var delivery model.WebhookDelivery
+	require.NoError(t, db.Where("deposit_event_id = ?", late[0].ID).First(&delivery).Error)
+	cursor, err := bitcoin.LoadSyncCursor(db, bitcoin.WebhookCursorName)
+	require.NoError(t, err)
+	require.Equal(t, latest[0].ID, cursor.CursorID)
+
+	// nothing new
+	enqueue()
+	require.Equal(t, int64(len(synced)+2), countDeliveries())
+}
+
+func TestLocalWebhookClaim(t *testing.T) {
+	db := localDB(t)
+	deliveries := []model.WebhookDelivery{
+		{Endpoint: "http://127.0.0.1:9095/hook", DepositEventID: 1, EventType: "deposit.created", Status: model.WebhookDeliveryStatusPending},
+		{Endpoint: "http://127.0.0.1:9095/hook", DepositEventID: 2, EventType: "deposit.created", Status: model.WebhookDeliveryStatusPending},
+		{Endpoint: "http://127.0.0.1:9095/hook", DepositEventID: 3, EventType: "deposit.created", Status: model.WebhookDeliveryStatusSuccess},
+	}
+	require.NoError(t, db.Create(&deliveries).Error)
+
+	claimed, err := bitcoin.ClaimWebhookDeliveries(db, "worker-a", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, "worker-a", claimed[0].LeaseOwner)
+
+	// the replica claims nothing leased
+	other, err := bitcoin.ClaimWebhookDeliveries(db, "worker-b", time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, other)
+
+	// the attempt of the lost lease is not recorded
+	lost := claimed[1]
+	lost.LeaseOwner = "worker-b"
+	require.ErrorIs(t, bitcoin.SaveWebhookDelivery(db, &lost, 0, "", errors.New("timeout"), 3), bitcoin.ErrWebhookLeaseLost)
+
+	require.NoError(t, bitcoin.SaveWebhookDelivery(db, &claimed[0], http.StatusOK, "ok", nil, 3))
+	var delivery model.WebhookDelivery
+	require.NoError(t, db.First(&delivery, claimed[0].ID).Error)
+	require.Equal(t, model.WebhookDeliveryStatusSuccess, delivery.Status)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Empty(t, delivery.LeaseOwner)
+
+	// the released delivery is claimed by the replica
+	require.NoError(t, bitcoin.ReleaseWebhookLeases(db, "worker-a"))
+	other, err = bitcoin.ClaimWebhookDeliveries(db, "worker-b", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, other, 1)
+	require.Equal(t, claimed[1].ID, other[0].ID)
+	require.Zero(t, other[0].Attempts)
+}
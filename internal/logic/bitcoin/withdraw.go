@@ -0,0 +1,93 @@
+package bitcoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const WithdrawEventName = "WithdrawEvent"
+
+var (
+	ErrWithdrawEventNotFound = errors.New("withdraw event log not found")
+	ErrWithdrawEventInvalid  = errors.New("withdraw event log invalid")
+)
+
+// WithdrawReader reads the withdraws from the WithdrawEvent logs of the bridge contract in the b2 tx receipts
+type WithdrawReader struct {
+	ethRPCURL       string
+	contractAddress common.Address
+	contractAbi     abi.ABI
+}
+
+// NewWithdrawReader new withdraw reader of the bridge contract
+func NewWithdrawReader(bridgeCfg config.BridgeConfig, abiFileDir string) (*WithdrawReader, error) {
+	rpcURL, err := url.ParseRequestURI(bridgeCfg.EthRPCURL)
+	if err != nil {
+		return nil, err
+	}
+	contractAbi, err := abi.JSON(strings.NewReader(LoadBridgeABI(bridgeCfg, abiFileDir)))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := contractAbi.Events[WithdrawEventName]; !ok {
+		return nil, fmt.Errorf("bridge abi without %s", WithdrawEventName)
+	}
+	return &WithdrawReader{
+		ethRPCURL:       rpcURL.String(),
+		contractAddress: common.HexToAddress(bridgeCfg.ContractAddress),
+		contractAbi:     contractAbi,
+	}, nil
+}
+
+// GetWithdraw returns the withdraw of the b2 tx, ethereum.NotFound if the tx is not mined
+func (r *WithdrawReader) GetWithdraw(ctx context.Context, txHash string) (*WithdrawEvent, error) {
+	client, err := ethclient.Dial(r.ethRPCURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithdrawEvent(r.contractAbi, r.contractAddress, receipt.Logs)
+}
+
+// ParseWithdrawEvent returns the first WithdrawEvent(from_address, btc_address, amount) log of the bridge contract
+func ParseWithdrawEvent(contractAbi abi.ABI, contractAddress common.Address, logs []*types.Log) (*WithdrawEvent, error) {
+	event := contractAbi.Events[WithdrawEventName]
+	for _, l := range logs {
+		if l.Address != contractAddress || len(l.Topics) != 2 || l.Topics[0] != event.ID {
+			continue
+		}
+		values, err := event.Inputs.Unpack(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrWithdrawEventInvalid, err.Error())
+		}
+		btcAddress, ok := values[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: btc_address %v", ErrWithdrawEventInvalid, values[0])
+		}
+		amount, ok := values[1].(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("%w: amount %v", ErrWithdrawEventInvalid, values[1])
+		}
+		return &WithdrawEvent{
+			FromAddress: TopicToAddress(*l, 1),
+			ToAddress:   btcAddress,
+			Amount:      amount,
+		}, nil
+	}
+	return nil, ErrWithdrawEventNotFound
+}
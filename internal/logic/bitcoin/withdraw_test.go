@@ -0,0 +1,43 @@
+package bitcoin_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithdrawEvent(t *testing.T) {
+	contractAbi, err := abi.JSON(strings.NewReader(config.DefaultDepositAbi))
+	require.NoError(t, err)
+	event := contractAbi.Events[bitcoin.WithdrawEventName]
+	contract := common.HexToAddress("0xB457BF68D71a17Fa5030269Fb895e29e6cD2DFF2")
+	from := common.HexToAddress("0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	data, err := event.Inputs.NonIndexed().Pack("tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv", big.NewInt(100000))
+	require.NoError(t, err)
+	topics := []common.Hash{event.ID, common.BytesToHash(from.Bytes())}
+
+	logs := []*types.Log{
+		// other contract
+		{Address: common.HexToAddress("0x01"), Topics: topics, Data: data},
+		// other event
+		{Address: contract, Topics: []common.Hash{contractAbi.Events["DepositEvent"].ID, topics[1]}, Data: data},
+		{Address: contract, Topics: topics, Data: data},
+	}
+	withdraw, err := bitcoin.ParseWithdrawEvent(contractAbi, contract, logs)
+	require.NoError(t, err)
+	require.Equal(t, from, withdraw.FromAddress)
+	require.Equal(t, "tb1qfhhxljfajcppfhwa09uxwty5dz4xwfptnqmvtv", withdraw.ToAddress)
+	require.Equal(t, big.NewInt(100000), withdraw.Amount)
+
+	_, err = bitcoin.ParseWithdrawEvent(contractAbi, contract, logs[:2])
+	require.ErrorIs(t, err, bitcoin.ErrWithdrawEventNotFound)
+	_, err = bitcoin.ParseWithdrawEvent(contractAbi, contract, []*types.Log{{Address: contract, Topics: topics, Data: data[:32]}})
+	require.ErrorIs(t, err, bitcoin.ErrWithdrawEventInvalid)
+}
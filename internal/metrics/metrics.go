@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const Namespace = "b2_indexer"
+
+// rpc client label
+const (
+	RPCBitcoin = "bitcoin"
+	RPCB2      = "b2"
+	RPCEps     = "eps"
+)
+
+var (
+	// BtcIndexedHeight the indexed bitcoin block height
+	BtcIndexedHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "btc_indexed_height",
+		Help:      "Indexed bitcoin block height.",
+	})
+	// BtcChainTip the bitcoin chain tip height
+	BtcChainTip = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "btc_chain_tip",
+		Help:      "Bitcoin chain tip height.",
+	})
+	// BtcIndexLag the blocks behind the chain tip
+	BtcIndexLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "btc_index_lag_blocks",
+		Help:      "Bitcoin blocks the indexer is behind the chain tip.",
+	})
+	// BtcBlocksParsed the parsed bitcoin blocks, rate() for per second
+	BtcBlocksParsed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "btc_blocks_parsed_total",
+		Help:      "Parsed bitcoin blocks.",
+	})
+	// BtcDepositsParsed the parsed bitcoin deposits, rate() for per second
+	BtcDepositsParsed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "btc_deposits_parsed_total",
+		Help:      "Parsed bitcoin deposit transactions.",
+	})
+	// RPCDuration the rpc call latency by client, method and result
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "RPC call latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"client", "method", "result"})
+	// B2GasPrice the gas price chosen for the last deposit tx
+	B2GasPrice = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "b2_gas_price_wei",
+		Help:      "Gas price of the last signed b2 deposit tx in wei.",
+	})
+	// B2GasUsed the gas used by the mined deposit and eoa transfer txs
+	B2GasUsed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "b2_gas_used_total",
+		Help:      "Gas used by the mined b2 deposit and eoa transfer txs.",
+	})
+	// B2GasSpent the fee paid by the mined deposit and eoa transfer txs
+	B2GasSpent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "b2_gas_spent_wei_total",
+		Help:      "Fee paid by the mined b2 deposit and eoa transfer txs in wei.",
+	})
+	// B2Balance the bridge contract and the signer balance
+	B2Balance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "b2_balance_wei",
+		Help:      "Native balance of the bridge contract and the signer in wei.",
+	}, []string{"account"})
+	// EpsDeliveries the eps delivery attempts by result
+	EpsDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "eps_deliveries_total",
+		Help:      "EPS delivery attempts by result.",
+	}, []string{"result"})
+)
+
+// serviceCollectors the metrics updated by the services
+var serviceCollectors = []prometheus.Collector{
+	BtcIndexedHeight,
+	BtcChainTip,
+	BtcIndexLag,
+	BtcBlocksParsed,
+	BtcDepositsParsed,
+	RPCDuration,
+	B2GasPrice,
+	B2GasUsed,
+	B2GasSpent,
+	B2Balance,
+	EpsDeliveries,
+}
+
+// ObserveRPC records the rpc call latency since start
+func ObserveRPC(client string, method string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	RPCDuration.WithLabelValues(client, method, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveEpsDelivery counts the eps delivery attempt
+func ObserveEpsDelivery(err error) {
+	if err != nil {
+		EpsDeliveries.WithLabelValues("failure").Inc()
+		return
+	}
+	EpsDeliveries.WithLabelValues("success").Inc()
+}
+
+// SetBtcIndex sets the indexed height and the chain tip
+func SetBtcIndex(indexed int64, tip int64) {
+	BtcIndexedHeight.Set(float64(indexed))
+	BtcChainTip.Set(float64(tip))
+	BtcIndexLag.Set(float64(tip - indexed))
+}
+
+// Float returns the float value of the wei amount, nil is 0
+func Float(x *big.Int) float64 {
+	if x == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
+
+// Handler returns the prometheus metrics handler of the service metrics, go runtime, process and the extra collectors
+func Handler(extra ...prometheus.Collector) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	registry.MustRegister(serviceCollectors...)
+	registry.MustRegister(extra...)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
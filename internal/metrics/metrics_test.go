@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetBtcIndex(t *testing.T) {
+	metrics.SetBtcIndex(100, 103)
+	require.Equal(t, float64(100), testutil.ToFloat64(metrics.BtcIndexedHeight))
+	require.Equal(t, float64(103), testutil.ToFloat64(metrics.BtcChainTip))
+	require.Equal(t, float64(3), testutil.ToFloat64(metrics.BtcIndexLag))
+}
+
+func TestObserveEpsDelivery(t *testing.T) {
+	success := testutil.ToFloat64(metrics.EpsDeliveries.WithLabelValues("success"))
+	failure := testutil.ToFloat64(metrics.EpsDeliveries.WithLabelValues("failure"))
+	metrics.ObserveEpsDelivery(nil)
+	metrics.ObserveEpsDelivery(errors.New("502 Bad Gateway"))
+	metrics.ObserveEpsDelivery(errors.New("502 Bad Gateway"))
+	require.Equal(t, success+1, testutil.ToFloat64(metrics.EpsDeliveries.WithLabelValues("success")))
+	require.Equal(t, failure+2, testutil.ToFloat64(metrics.EpsDeliveries.WithLabelValues("failure")))
+}
+
+func TestFloat(t *testing.T) {
+	require.Equal(t, float64(0), metrics.Float(nil))
+	wei, _ := new(big.Int).SetString("1500000000000000000", 10)
+	require.Equal(t, 1.5e18, metrics.Float(wei))
+}
+
+func TestHandler(t *testing.T) {
+	metrics.ObserveRPC(metrics.RPCBitcoin, "latest_block", time.Now(), nil)
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, 200, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	for _, name := range []string{
+		"b2_indexer_btc_indexed_height",
+		"b2_indexer_btc_blocks_parsed_total",
+		`b2_indexer_rpc_duration_seconds_count{client="bitcoin",method="latest_block",result="success"}`,
+		"b2_indexer_b2_gas_spent_wei_total",
+		"go_goroutines",
+	} {
+		require.Contains(t, string(body), name)
+	}
+}
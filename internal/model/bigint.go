@@ -0,0 +1,103 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// BigInt arbitrary-precision integer, stored as postgres numeric.
+// The value is treated as immutable, use new(big.Int) for arithmetic.
+type BigInt struct {
+	*big.Int
+}
+
+// NewBigInt new BigInt from big.Int
+func NewBigInt(x *big.Int) BigInt {
+	if x == nil {
+		return BigInt{new(big.Int)}
+	}
+	return BigInt{new(big.Int).Set(x)}
+}
+
+// NewBigIntFromInt64 new BigInt from int64
+func NewBigIntFromInt64(x int64) BigInt {
+	return BigInt{big.NewInt(x)}
+}
+
+// BigInt returns the big.Int value, zero if not set
+func (b BigInt) BigInt() *big.Int {
+	if b.Int == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(b.Int)
+}
+
+func (b BigInt) String() string {
+	if b.Int == nil {
+		return "0"
+	}
+	return b.Int.String()
+}
+
+// GormDataType gorm common data type
+func (BigInt) GormDataType() string {
+	return "numeric"
+}
+
+// Value implements driver.Valuer
+func (b BigInt) Value() (driver.Value, error) {
+	return b.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (b *BigInt) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		b.Int = new(big.Int)
+		return nil
+	case int64:
+		b.Int = big.NewInt(v)
+		return nil
+	case []byte:
+		return b.setString(string(v))
+	case string:
+		return b.setString(v)
+	default:
+		return fmt.Errorf("unsupported bigint scan type %T", value)
+	}
+}
+
+func (b *BigInt) setString(s string) error {
+	// numeric may be returned with a zero fraction, e.g. 100.0
+	if strings.Contains(s, ".") {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok || !r.IsInt() {
+			return fmt.Errorf("invalid bigint value %s", s)
+		}
+		b.Int = new(big.Int).Set(r.Num())
+		return nil
+	}
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid bigint value %s", s)
+	}
+	b.Int = i
+	return nil
+}
+
+// MarshalJSON encodes as json number
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalJSON decodes json number or string
+func (b *BigInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		b.Int = new(big.Int)
+		return nil
+	}
+	return b.setString(s)
+}
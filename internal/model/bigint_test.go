@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigIntScan(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected string
+		errMsg   string
+	}{
+		{"nil", nil, "0", ""},
+		{"int64", int64(123), "123", ""},
+		{"string", "123456789012345678901234567890", "123456789012345678901234567890", ""},
+		{"bytes", []byte("42"), "42", ""},
+		{"zero fraction", "100.0", "100", ""},
+		{"fraction", "100.5", "", "invalid bigint value 100.5"},
+		{"invalid", "abc", "", "invalid bigint value abc"},
+		{"unsupported", 1.5, "", "unsupported bigint scan type float64"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b model.BigInt
+			err := b.Scan(tc.value)
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, b.String())
+		})
+	}
+}
+
+func TestBigIntValue(t *testing.T) {
+	v, err := model.BigInt{}.Value()
+	require.NoError(t, err)
+	require.Equal(t, "0", v)
+
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	v, err = model.NewBigInt(x).Value()
+	require.NoError(t, err)
+	require.Equal(t, "123456789012345678901234567890", v)
+}
+
+func TestBigIntJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Value model.BigInt `json:"value"`
+	}{model.NewBigIntFromInt64(1000)})
+	require.NoError(t, err)
+	require.Equal(t, `{"value":1000}`, string(data))
+
+	var v struct {
+		Value model.BigInt `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"value":"123456789012345678901234567890"}`), &v))
+	require.Equal(t, "123456789012345678901234567890", v.Value.String())
+	require.NoError(t, json.Unmarshal([]byte(`{"value":42}`), &v))
+	require.Equal(t, "42", v.Value.String())
+}
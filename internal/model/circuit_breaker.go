@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// CircuitBreaker pauses all minting while open, reset by operator
+type CircuitBreaker struct {
+	Base
+	Name     string    `json:"name" gorm:"type:varchar(32);not null;default:'';uniqueIndex;comment:circuit breaker name"`
+	Open     bool      `json:"open" gorm:"default:false;comment:minting paused while open"`
+	Reason   string    `json:"reason" gorm:"type:text;comment:open reason"`
+	OpenedAt time.Time `json:"opened_at" gorm:"comment:last open time"`
+	Operator string    `json:"operator" gorm:"type:varchar(64);not null;default:'';comment:last reset operator"`
+	ResetAt  time.Time `json:"reset_at" gorm:"comment:last reset time"`
+}
+
+type CircuitBreakerColumns struct {
+	Name     string
+	Open     string
+	Reason   string
+	OpenedAt string
+	Operator string
+	ResetAt  string
+}
+
+func (CircuitBreaker) TableName() string {
+	return "circuit_breaker"
+}
+
+func (CircuitBreaker) Column() CircuitBreakerColumns {
+	return CircuitBreakerColumns{
+		Name:     "name",
+		Open:     "open",
+		Reason:   "reason",
+		OpenedAt: "opened_at",
+		Operator: "operator",
+		ResetAt:  "reset_at",
+	}
+}
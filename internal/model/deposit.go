@@ -2,63 +2,136 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 const (
 	BtcTxTypeTransfer = 0 // transfer
 
-	DepositB2TxStatusSuccess                    = 0 // success
-	DepositB2TxStatusPending                    = 1 // pending
-	DepositB2TxStatusFailed                     = 2 // deposit invoke failed
-	DepositB2TxStatusWaitMinedFailed            = 3 // deposit wait mined failed
-	DepositB2TxStatusTxHashExist                = 4 // tx hash exist, deposit have been called
-	DepositB2TxStatusWaitMinedStatusFailed      = 5 // deposit wait mined status failed, status != 1
-	DepositB2TxStatusInsufficientBalance        = 6 // deposit insufficient balance
-	DepositB2TxStatusContextDeadlineExceeded    = 7 // deposit client context deadline exceeded, Chain transaction is stuck
-	DepositB2TxStatusFromAccountGasInsufficient = 8 // deposit evm from account gas insufficient
+	DepositB2TxStatusSuccess                    = 0  // success
+	DepositB2TxStatusPending                    = 1  // pending
+	DepositB2TxStatusFailed                     = 2  // deposit invoke failed
+	DepositB2TxStatusWaitMinedFailed            = 3  // deposit wait mined failed
+	DepositB2TxStatusTxHashExist                = 4  // tx hash exist, deposit have been called
+	DepositB2TxStatusWaitMinedStatusFailed      = 5  // deposit wait mined status failed, status != 1
+	DepositB2TxStatusInsufficientBalance        = 6  // deposit insufficient balance
+	DepositB2TxStatusContextDeadlineExceeded    = 7  // deposit client context deadline exceeded, Chain transaction is stuck
+	DepositB2TxStatusFromAccountGasInsufficient = 8  // deposit evm from account gas insufficient
+	DepositB2TxStatusFeeExceedsAmount           = 9  // deposit amount not enough to pay the bridge fee
+	DepositB2TxStatusRejected                   = 10 // deposit rejected by deposit policy, wait refund
+	DepositB2TxStatusComplianceHold             = 11 // deposit held by compliance screening, wait operator release
+	DepositB2TxStatusPendingApproval            = 12 // large deposit wait operator approval
+	DepositB2TxStatusApprovalRejected           = 13 // large deposit rejected by operator, wait refund
+	DepositB2TxStatusThrottled                  = 14 // deposit exceeds the rate limit, retry when the window allows
+	DepositB2TxStatusInFlight                   = 15 // deposit tx signed and persisted, wait broadcast and mined
+	DepositB2TxStatusAmountInexact              = 16 // deposit amount not exactly convertible to l2 units, need handle manually
+	DepositB2TxStatusDropped                    = 17 // in-flight tx dropped, its nonce used by another tx, handle again
 
-	DepositB2EoaTxStatusSuccess                 = 0 // eoa transfer success
-	DepositB2EoaTxStatusPending                 = 1 // eoa transfer pending
-	DepositB2EoaTxStatusFailed                  = 2 // eoa transfer failed
-	DepositB2EoaTxStatusWaitMinedFailed         = 3 // eoa transfer wait mined failed
-	DepositB2EoaTxStatusContextDeadlineExceeded = 7 // eoa transfer client context deadline exceeded
+	DepositB2EoaTxStatusSuccess                 = 0  // eoa transfer success
+	DepositB2EoaTxStatusPending                 = 1  // eoa transfer pending
+	DepositB2EoaTxStatusFailed                  = 2  // eoa transfer failed
+	DepositB2EoaTxStatusWaitMinedFailed         = 3  // eoa transfer wait mined failed
+	DepositB2EoaTxStatusPendingApproval         = 4  // eoa transfer wait manual approval
+	DepositB2EoaTxStatusApproved                = 5  // eoa transfer approved, wait transfer
+	DepositB2EoaTxStatusRejected                = 6  // eoa transfer rejected by operator
+	DepositB2EoaTxStatusContextDeadlineExceeded = 7  // eoa transfer client context deadline exceeded
+	DepositB2EoaTxStatusPolicySkipped           = 8  // eoa transfer not allowed by fallback policy
+	DepositB2EoaTxStatusQueued                  = 9  // eoa transfer allowed by fallback policy, wait transfer
+	DepositB2EoaTxStatusInFlight                = 10 // eoa transfer tx signed and persisted, wait broadcast and mined
+	DepositB2EoaTxStatusDropped                 = 11 // in-flight eoa transfer tx dropped, its nonce used by another tx, transfer again
 )
 
+// DepositCursorIndex the (updated_at, id) index of the deposit cursor scans, eg. eps ingestion
+const DepositCursorIndex = "idx_deposit_history_updated_at_id"
+
+// Deposit bitcoin deposit and its bridge status, it declares the base fields for the cursor index
 type Deposit struct {
-	Base
-	BtcBlockNumber   int64     `json:"btc_block_number" gorm:"index;comment:bitcoin block number"`
-	BtcTxIndex       int64     `json:"btc_tx_index" gorm:"comment:bitcoin tx index"`
-	BtcTxHash        string    `json:"btc_tx_hash" gorm:"type:varchar(64);not null;default:'';uniqueIndex;comment:bitcoin tx hash"`
-	BtcTxType        int       `json:"btc_tx_type" gorm:"type:SMALLINT;default:0;comment:btc tx type"`
-	BtcFroms         string    `json:"btc_froms" gorm:"type:jsonb;comment:bitcoin transfer, from may be multiple"`
-	BtcFrom          string    `json:"btc_from" gorm:"type:varchar(64);not null;default:'';index"`
-	BtcTo            string    `json:"btc_to" gorm:"type:varchar(64);not null;default:'';index"`
-	BtcFromAAAddress string    `json:"btc_from_aa_address" gorm:"type:varchar(42);default:'';comment:from aa address"`
-	BtcValue         int64     `json:"btc_value" gorm:"default:0;comment:bitcoin transfer value"`
-	B2TxHash         string    `json:"b2_tx_hash" gorm:"type:varchar(66);not null;default:'';index;comment:b2 network tx hash"`
-	B2TxStatus       int       `json:"b2_tx_status" gorm:"type:SMALLINT;default:1"`
-	B2TxRetry        int       `json:"b2_tx_retry" gorm:"type:SMALLINT;default:0"`
-	B2EoaTxHash      string    `json:"b2_eoa_tx_hash" gorm:"type:varchar(66);not null;default:'';comment:b2 network eoa tx hash"`
-	B2EoaTxStatus    int       `json:"b2_eoa_tx_status" gorm:"type:SMALLINT;default:1"`
-	BtcBlockTime     time.Time `json:"btc_block_time"`
+	ID                 int64          `json:"id" gorm:"index:idx_deposit_history_updated_at_id,priority:2"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"index:idx_deposit_history_updated_at_id,priority:1"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at"`
+	BtcBlockNumber     int64          `json:"btc_block_number" gorm:"index;comment:bitcoin block number"`
+	BtcTxIndex         int64          `json:"btc_tx_index" gorm:"comment:bitcoin tx index"`
+	BtcTxHash          string         `json:"btc_tx_hash" gorm:"type:varchar(64);not null;default:'';uniqueIndex;comment:bitcoin tx hash"`
+	BtcTxType          int            `json:"btc_tx_type" gorm:"type:SMALLINT;default:0;comment:btc tx type"`
+	BtcFroms           string         `json:"btc_froms" gorm:"type:jsonb;comment:bitcoin transfer, from may be multiple"`
+	BtcFrom            string         `json:"btc_from" gorm:"type:varchar(64);not null;default:'';index"`
+	BtcTo              string         `json:"btc_to" gorm:"type:varchar(64);not null;default:'';index"`
+	BtcFromAAAddress   string         `json:"btc_from_aa_address" gorm:"type:varchar(42);default:'';comment:from aa address"`
+	BtcValue           BigInt         `json:"btc_value" gorm:"type:numeric(78,0);default:0;comment:bitcoin transfer value"`
+	B2TxHash           string         `json:"b2_tx_hash" gorm:"type:varchar(66);not null;default:'';index;comment:b2 network tx hash"`
+	B2TxStatus         int            `json:"b2_tx_status" gorm:"type:SMALLINT;default:1"`
+	B2TxRetry          int            `json:"b2_tx_retry" gorm:"type:SMALLINT;default:0"`
+	B2RawTx            string         `json:"b2_raw_tx" gorm:"type:text;comment:b2 network signed raw tx, hex encoded"`
+	B2TxSubmittedAt    time.Time      `json:"b2_tx_submitted_at" gorm:"index;comment:b2 network tx submitted time, the rate limit window"`
+	B2EoaTxHash        string         `json:"b2_eoa_tx_hash" gorm:"type:varchar(66);not null;default:'';comment:b2 network eoa tx hash"`
+	B2EoaTxStatus      int            `json:"b2_eoa_tx_status" gorm:"type:SMALLINT;default:1"`
+	B2EoaRawTx         string         `json:"b2_eoa_raw_tx" gorm:"type:text;comment:b2 network signed eoa transfer raw tx, hex encoded"`
+	B2EoaTxSubmittedAt time.Time      `json:"b2_eoa_tx_submitted_at" gorm:"index;comment:b2 network eoa tx submitted time, the daily limit window"`
+	BtcBlockTime       time.Time      `json:"btc_block_time"`
+	BtcMemo            string         `json:"btc_memo" gorm:"type:varchar(160);not null;default:'';comment:bitcoin op_return memo, hex encoded"`
+	RecipientStrategy  string         `json:"recipient_strategy" gorm:"type:varchar(32);not null;default:'';comment:l2 recipient resolution strategy"`
+	L2Value            BigInt         `json:"l2_value" gorm:"type:numeric(78,0);default:0;comment:l2 minted value, converted by l2 decimals"`
+	BridgeFee          BigInt         `json:"bridge_fee" gorm:"type:numeric(78,0);default:0;comment:bridge fee deducted from bitcoin transfer value"`
+	NetValue           BigInt         `json:"net_value" gorm:"type:numeric(78,0);default:0;comment:bitcoin transfer value after bridge fee"`
+	RejectReason       string         `json:"reject_reason" gorm:"type:text;comment:deposit policy reject reason"`
+	ComplianceReason   string         `json:"compliance_reason" gorm:"type:text;comment:compliance screening hit reason"`
+	ComplianceReleased bool           `json:"compliance_released" gorm:"default:false;comment:compliance hold released by operator"`
+	ComplianceOperator string         `json:"compliance_operator" gorm:"type:varchar(64);not null;default:'';comment:compliance hold release operator"`
+	ComplianceRemark   string         `json:"compliance_remark" gorm:"type:text;comment:compliance hold release remark"`
+	ApprovalOperator   string         `json:"approval_operator" gorm:"type:varchar(64);not null;default:'';comment:large deposit review operator"`
+	ApprovalRemark     string         `json:"approval_remark" gorm:"type:text;comment:large deposit review remark"`
+	ApprovalAt         time.Time      `json:"approval_at" gorm:"comment:large deposit review time"`
+	ThrottleReason     string         `json:"throttle_reason" gorm:"type:text;comment:exceeded rate limit"`
+	RetryAttempt       int            `json:"retry_attempt" gorm:"type:SMALLINT;default:0;comment:consecutive failed handle attempts"`
+	NextRetryAt        time.Time      `json:"next_retry_at" gorm:"index;comment:next handle time, backoff by error class"`
+	LastError          string         `json:"last_error" gorm:"type:text;comment:last handle error"`
+	LeaseOwner         string         `json:"lease_owner" gorm:"type:varchar(128);not null;default:'';comment:bridge worker handling the deposit"`
+	LeaseExpiresAt     time.Time      `json:"lease_expires_at" gorm:"index;comment:worker lease expiry, expired deposits can be claimed by other workers"`
+	TraceParent        string         `json:"trace_parent" gorm:"type:varchar(55);not null;default:'';comment:w3c traceparent of the deposit trace, continued by all services"`
 }
 
 type DepositColumns struct {
-	BtcBlockNumber   string
-	BtcTxIndex       string
-	BtcTxHash        string
-	BtcTxType        string
-	BtcFroms         string
-	BtcFrom          string
-	BtcTo            string
-	BtcFromAAAddress string
-	BtcValue         string
-	B2TxHash         string
-	B2TxStatus       string
-	B2TxRetry        string
-	B2EoaTxHash      string
-	B2EoaTxStatus    string
-	BtcBlockTime     string
+	BtcBlockNumber     string
+	BtcTxIndex         string
+	BtcTxHash          string
+	BtcTxType          string
+	BtcFroms           string
+	BtcFrom            string
+	BtcTo              string
+	BtcFromAAAddress   string
+	BtcValue           string
+	B2TxHash           string
+	B2TxStatus         string
+	B2TxRetry          string
+	B2RawTx            string
+	B2TxSubmittedAt    string
+	B2EoaTxHash        string
+	B2EoaTxStatus      string
+	B2EoaRawTx         string
+	B2EoaTxSubmittedAt string
+	BtcBlockTime       string
+	BtcMemo            string
+	RecipientStrategy  string
+	L2Value            string
+	BridgeFee          string
+	NetValue           string
+	RejectReason       string
+	ComplianceReason   string
+	ComplianceReleased string
+	ComplianceOperator string
+	ComplianceRemark   string
+	ApprovalOperator   string
+	ApprovalRemark     string
+	ApprovalAt         string
+	ThrottleReason     string
+	RetryAttempt       string
+	NextRetryAt        string
+	LastError          string
+	LeaseOwner         string
+	LeaseExpiresAt     string
+	TraceParent        string
 }
 
 func (Deposit) TableName() string {
@@ -67,20 +140,44 @@ func (Deposit) TableName() string {
 
 func (Deposit) Column() DepositColumns {
 	return DepositColumns{
-		BtcBlockNumber:   "btc_block_number",
-		BtcTxIndex:       "btc_tx_index",
-		BtcTxHash:        "btc_tx_hash",
-		BtcTxType:        "btc_tx_type",
-		BtcFroms:         "btc_froms",
-		BtcFrom:          "btc_from",
-		BtcTo:            "btc_to",
-		BtcFromAAAddress: "btc_from_aa_address",
-		BtcValue:         "btc_value",
-		B2TxHash:         "b2_tx_hash",
-		B2TxStatus:       "b2_tx_status",
-		B2EoaTxHash:      "b2_eoa_tx_hash",
-		B2EoaTxStatus:    "b2_eoa_tx_status",
-		BtcBlockTime:     "btc_block_time",
-		B2TxRetry:        "b2_tx_retry",
+		BtcBlockNumber:     "btc_block_number",
+		BtcTxIndex:         "btc_tx_index",
+		BtcTxHash:          "btc_tx_hash",
+		BtcTxType:          "btc_tx_type",
+		BtcFroms:           "btc_froms",
+		BtcFrom:            "btc_from",
+		BtcTo:              "btc_to",
+		BtcFromAAAddress:   "btc_from_aa_address",
+		BtcValue:           "btc_value",
+		B2TxHash:           "b2_tx_hash",
+		B2TxStatus:         "b2_tx_status",
+		B2EoaTxHash:        "b2_eoa_tx_hash",
+		B2EoaTxStatus:      "b2_eoa_tx_status",
+		B2EoaRawTx:         "b2_eoa_raw_tx",
+		B2EoaTxSubmittedAt: "b2_eoa_tx_submitted_at",
+		BtcBlockTime:       "btc_block_time",
+		B2TxRetry:          "b2_tx_retry",
+		B2RawTx:            "b2_raw_tx",
+		B2TxSubmittedAt:    "b2_tx_submitted_at",
+		BtcMemo:            "btc_memo",
+		RecipientStrategy:  "recipient_strategy",
+		L2Value:            "l2_value",
+		BridgeFee:          "bridge_fee",
+		NetValue:           "net_value",
+		RejectReason:       "reject_reason",
+		ComplianceReason:   "compliance_reason",
+		ComplianceReleased: "compliance_released",
+		ComplianceOperator: "compliance_operator",
+		ComplianceRemark:   "compliance_remark",
+		ApprovalOperator:   "approval_operator",
+		ApprovalRemark:     "approval_remark",
+		ApprovalAt:         "approval_at",
+		ThrottleReason:     "throttle_reason",
+		RetryAttempt:       "retry_attempt",
+		NextRetryAt:        "next_retry_at",
+		LastError:          "last_error",
+		LeaseOwner:         "lease_owner",
+		LeaseExpiresAt:     "lease_expires_at",
+		TraceParent:        "trace_parent",
 	}
 }
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DepositEventStatusB2Tx    = "b2_tx"     // B2TxStatus transition
+	DepositEventStatusB2EoaTx = "b2_eoa_tx" // B2EoaTxStatus transition
+	DepositEventStatusEps     = "eps"       // eps delivery status transition
+
+	DepositStatusNone = -1 // from status of the deposit created event
+)
+
+// DepositEventCursorIndex the (created_at, id) index of the deposit event cursor scans, eg. webhook enqueue
+const DepositEventCursorIndex = "idx_deposit_events_created_at_id"
+
+// DepositEvent deposit status transition history, every transition is a new row.
+// It declares the base fields for the cursor index.
+type DepositEvent struct {
+	ID         int64          `json:"id" gorm:"index:idx_deposit_events_created_at_id,priority:2"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"index:idx_deposit_events_created_at_id,priority:1"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at"`
+	DepositID  int64          `json:"deposit_id" gorm:"index;comment:deposit_history id"`
+	BtcTxHash  string         `json:"btc_tx_hash" gorm:"type:varchar(64);not null;default:'';index;comment:bitcoin tx hash"`
+	StatusType string         `json:"status_type" gorm:"type:varchar(16);not null;default:'';comment:b2_tx, b2_eoa_tx or eps status"`
+	FromStatus int            `json:"from_status" gorm:"type:SMALLINT;default:-1"`
+	ToStatus   int            `json:"to_status" gorm:"type:SMALLINT;default:-1"`
+	FromState  string         `json:"from_state" gorm:"type:varchar(64);not null;default:'';comment:from status name"`
+	ToState    string         `json:"to_state" gorm:"type:varchar(64);not null;default:'';comment:to status name"`
+	Error      string         `json:"error" gorm:"type:text;comment:transition error or reason"`
+	TxHash     string         `json:"tx_hash" gorm:"type:varchar(66);not null;default:'';comment:b2 network tx hash"`
+	Actor      string         `json:"actor" gorm:"type:varchar(64);not null;default:'';comment:service or operator"`
+}
+
+type DepositEventColumns struct {
+	DepositID  string
+	BtcTxHash  string
+	StatusType string
+	FromStatus string
+	ToStatus   string
+	FromState  string
+	ToState    string
+	Error      string
+	TxHash     string
+	Actor      string
+}
+
+func (DepositEvent) TableName() string {
+	return "deposit_events"
+}
+
+func (DepositEvent) Column() DepositEventColumns {
+	return DepositEventColumns{
+		DepositID:  "deposit_id",
+		BtcTxHash:  "btc_tx_hash",
+		StatusType: "status_type",
+		FromStatus: "from_status",
+		ToStatus:   "to_status",
+		FromState:  "from_state",
+		ToState:    "to_state",
+		Error:      "error",
+		TxHash:     "tx_hash",
+		Actor:      "actor",
+	}
+}
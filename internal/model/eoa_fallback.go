@@ -0,0 +1,56 @@
+package model
+
+const (
+	EoaFallbackActionSkipped         = "skipped"          // not allowed by policy
+	EoaFallbackActionQueued          = "queued"           // allowed by policy, wait eoa worker transfer within daily limit
+	EoaFallbackActionPendingApproval = "pending_approval" // wait manual approval
+	EoaFallbackActionApproved        = "approved"         // approved by operator
+	EoaFallbackActionRejected        = "rejected"         // rejected by operator
+	EoaFallbackActionExecuted        = "executed"         // eoa transfer tx sent
+	EoaFallbackActionSucceeded       = "succeeded"        // eoa transfer tx mined
+	EoaFallbackActionFailed          = "failed"           // eoa transfer tx failed
+)
+
+// EoaFallback eoa transfer fallback audit trail, every decision and result is a new row
+type EoaFallback struct {
+	Base
+	DepositID   int64  `json:"deposit_id" gorm:"index;comment:deposit_history id"`
+	BtcTxHash   string `json:"btc_tx_hash" gorm:"type:varchar(64);not null;default:'';index;comment:bitcoin tx hash"`
+	Policy      string `json:"policy" gorm:"type:varchar(32);not null;default:'';comment:eoa fallback policy"`
+	Action      string `json:"action" gorm:"type:varchar(32);not null;default:'';index;comment:eoa fallback action"`
+	Amount      BigInt `json:"amount" gorm:"type:numeric(78,0);default:0;comment:bitcoin transfer value, net of the bridge fee once transferred"`
+	ToAddress   string `json:"to_address" gorm:"type:varchar(42);not null;default:'';comment:eoa transfer to address"`
+	B2EoaTxHash string `json:"b2_eoa_tx_hash" gorm:"type:varchar(66);not null;default:'';comment:b2 network eoa tx hash"`
+	Operator    string `json:"operator" gorm:"type:varchar(64);not null;default:'';comment:operator, system if empty"`
+	Reason      string `json:"reason" gorm:"type:text;comment:action reason or error"`
+}
+
+type EoaFallbackColumns struct {
+	DepositID   string
+	BtcTxHash   string
+	Policy      string
+	Action      string
+	Amount      string
+	ToAddress   string
+	B2EoaTxHash string
+	Operator    string
+	Reason      string
+}
+
+func (EoaFallback) TableName() string {
+	return "eoa_fallback_history"
+}
+
+func (EoaFallback) Column() EoaFallbackColumns {
+	return EoaFallbackColumns{
+		DepositID:   "deposit_id",
+		BtcTxHash:   "btc_tx_hash",
+		Policy:      "policy",
+		Action:      "action",
+		Amount:      "amount",
+		ToAddress:   "to_address",
+		B2EoaTxHash: "b2_eoa_tx_hash",
+		Operator:    "operator",
+		Reason:      "reason",
+	}
+}
@@ -0,0 +1,31 @@
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/b2network/b2-indexer/pkg/utils"
+)
+
+func TestValidateEoaFallbackColumn(t *testing.T) {
+	var d model.EoaFallback
+	dc := model.EoaFallback{}.Column()
+
+	dFields := reflect.TypeOf(d)
+	dcValues := reflect.ValueOf(dc)
+
+	dJSONTags := []string{}
+	for i := 0; i < dFields.NumField(); i++ {
+		dField := dFields.Field(i)
+		dJSONTag := dField.Tag.Get("json")
+		dJSONTags = append(dJSONTags, dJSONTag)
+	}
+
+	for i := 0; i < dcValues.NumField(); i++ {
+		dcValue := dcValues.Field(i).String()
+		if !utils.StrInArray(dJSONTags, dcValue) {
+			t.Fatalf("eoaFallbackColumn field %s not found in eoa fallback %s", dcValue, dJSONTags)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package model
 import "time"
 
 const (
-	EspStatus        = 1
-	EspStatusSuccess = 2 // success
+	EspStatus             = 1
+	EspStatusSuccess      = 2 // success
+	EspStatusDeadLetter   = 3 // delivery failed after max attempts, wait redeliver
+	EspStatusIngestFailed = 4 // b2 tx receipt ingestion failed, re-ingested by backoff
 )
 
 type Eps struct {
@@ -12,12 +14,24 @@ type Eps struct {
 	DepositID          int64     `json:"deposit_id" gorm:"index;comment:deposit_history id"`
 	B2From             string    `json:"b2_from" gorm:"type:varchar(64);not null;default:'';index;commit:b2 from"`
 	B2To               string    `json:"b2_to" gorm:"type:varchar(64);not null;default:'';index;commit:b2 to"`
-	BtcValue           int64     `json:"btc_value" gorm:"default:0;comment:btc transfer value"`
+	BtcValue           BigInt    `json:"btc_value" gorm:"type:numeric(78,0);default:0;comment:btc transfer value"`
+	NetValue           BigInt    `json:"net_value" gorm:"type:numeric(78,0);default:0;comment:bridged value after bridge fee, the minted amount in sats"`
 	B2TxHash           string    `json:"b2_tx_hash" gorm:"type:varchar(66);not null;default:'';index;comment:b2 network tx hash"`
 	B2TxTime           time.Time `json:"b2_tx_time" gorm:"type:timestamp;comment:btc tx time"`
 	B2BlockNumber      int64     `json:"b2_block_number" gorm:"index;comment:b2 block number"`
 	B2TransactionIndex int64     `json:"b2_transaction_index" gorm:"index;comment:b2 transaction index"`
+	B2BlockHash        string    `json:"b2_block_hash" gorm:"type:varchar(66);not null;default:'';comment:b2 block hash"`
+	LogIndex           int       `json:"log_index" gorm:"default:0;comment:DepositEvent log index, 0 for eoa transfer"`
+	B2GasUsed          int64     `json:"b2_gas_used" gorm:"default:0;comment:b2 tx gas used"`
 	Status             int       `json:"status" gorm:"type:SMALLINT;default:1"`
+	IdempotencyKey     string    `json:"idempotency_key" gorm:"type:varchar(64);not null;default:'';index;comment:delivery idempotency key header"`
+	Attempts           int       `json:"attempts" gorm:"default:0;comment:delivery attempts, ingestion attempts if ingest failed"`
+	NextAttemptAt      time.Time `json:"next_attempt_at" gorm:"index;comment:next delivery or ingestion time, backoff by attempts"`
+	LastStatusCode     int       `json:"last_status_code" gorm:"default:0;comment:last delivery http status code"`
+	LastResponse       string    `json:"last_response" gorm:"type:text;comment:last delivery response body"`
+	LastError          string    `json:"last_error" gorm:"type:text;comment:last delivery or ingestion error"`
+	DeliveredAt        time.Time `json:"delivered_at" gorm:"comment:delivery success time"`
+	TraceParent        string    `json:"trace_parent" gorm:"type:varchar(55);not null;default:'';comment:w3c traceparent of the deposit trace"`
 }
 
 type EpsColumns struct {
@@ -26,11 +40,23 @@ type EpsColumns struct {
 	B2From             string
 	B2To               string
 	BtcValue           string
+	NetValue           string
 	B2TxHash           string
 	B2TxTime           string
 	B2BlockNumber      string
 	B2TransactionIndex string
+	B2BlockHash        string
+	LogIndex           string
+	B2GasUsed          string
 	Status             string
+	IdempotencyKey     string
+	Attempts           string
+	NextAttemptAt      string
+	LastStatusCode     string
+	LastResponse       string
+	LastError          string
+	DeliveredAt        string
+	TraceParent        string
 }
 
 func (Eps) TableName() string {
@@ -44,10 +70,22 @@ func (Eps) Column() EpsColumns {
 		B2From:             "b2_from",
 		B2To:               "b2_to",
 		BtcValue:           "btc_value",
+		NetValue:           "net_value",
 		B2TxHash:           "b2_tx_hash",
 		B2TxTime:           "b2_tx_time",
 		B2BlockNumber:      "b2_block_number",
 		B2TransactionIndex: "b2_transaction_index",
+		B2BlockHash:        "b2_block_hash",
+		LogIndex:           "log_index",
+		B2GasUsed:          "b2_gas_used",
 		Status:             "status",
+		IdempotencyKey:     "idempotency_key",
+		Attempts:           "attempts",
+		NextAttemptAt:      "next_attempt_at",
+		LastStatusCode:     "last_status_code",
+		LastResponse:       "last_response",
+		LastError:          "last_error",
+		DeliveredAt:        "delivered_at",
+		TraceParent:        "trace_parent",
 	}
 }
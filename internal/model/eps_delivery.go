@@ -0,0 +1,42 @@
+package model
+
+// EpsDelivery the delivery log of every eps delivery attempt
+type EpsDelivery struct {
+	Base
+	EpsID          int64  `json:"eps_id" gorm:"index;comment:eps_history id"`
+	DepositID      int64  `json:"deposit_id" gorm:"index;comment:deposit_history id"`
+	Attempt        int    `json:"attempt" gorm:"comment:delivery attempt number"`
+	IdempotencyKey string `json:"idempotency_key" gorm:"type:varchar(64);not null;default:'';comment:delivery idempotency key header"`
+	StatusCode     int    `json:"status_code" gorm:"default:0;comment:http status code, 0 if no response"`
+	Response       string `json:"response" gorm:"type:text;comment:response body"`
+	Error          string `json:"error" gorm:"type:text;comment:delivery error"`
+	Duration       int64  `json:"duration" gorm:"comment:delivery duration in milliseconds"`
+}
+
+type EpsDeliveryColumns struct {
+	EpsID          string
+	DepositID      string
+	Attempt        string
+	IdempotencyKey string
+	StatusCode     string
+	Response       string
+	Error          string
+	Duration       string
+}
+
+func (EpsDelivery) TableName() string {
+	return "eps_delivery_log"
+}
+
+func (EpsDelivery) Column() EpsDeliveryColumns {
+	return EpsDeliveryColumns{
+		EpsID:          "eps_id",
+		DepositID:      "deposit_id",
+		Attempt:        "attempt",
+		IdempotencyKey: "idempotency_key",
+		StatusCode:     "status_code",
+		Response:       "response",
+		Error:          "error",
+		Duration:       "duration",
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+const (
+	RefundStatusSent            = 0 // refund tx sent
+	RefundStatusPendingApproval = 1 // wait manual approval
+	RefundStatusApproved        = 2 // approved by operator, wait send
+	RefundStatusRejected        = 3 // rejected by operator, no refund
+	RefundStatusSending         = 4 // refund tx sending, need check manually if it stays
+	RefundStatusFailed          = 5 // refund tx send failed
+)
+
+// Refund refund queue of rejected deposits, refund to the deposit bitcoin from address
+type Refund struct {
+	Base
+	DepositID    int64  `json:"deposit_id" gorm:"uniqueIndex;comment:deposit_history id"`
+	BtcTxHash    string `json:"btc_tx_hash" gorm:"type:varchar(64);not null;default:'';index;comment:bitcoin tx hash"`
+	ToAddress    string `json:"to_address" gorm:"type:varchar(64);not null;default:'';comment:refund bitcoin address"`
+	Amount       BigInt `json:"amount" gorm:"type:numeric(78,0);default:0;comment:bitcoin transfer value"`
+	RefundValue  BigInt `json:"refund_value" gorm:"type:numeric(78,0);default:0;comment:refund value after bitcoin tx fee"`
+	RejectReason string `json:"reject_reason" gorm:"type:text;comment:deposit reject reason"`
+	Status       int    `json:"status" gorm:"type:SMALLINT;default:1;index"`
+	Operator     string `json:"operator" gorm:"type:varchar(64);not null;default:'';comment:review operator"`
+	Remark       string `json:"remark" gorm:"type:text;comment:review remark or send error"`
+	RefundTxHash string `json:"refund_tx_hash" gorm:"type:varchar(64);not null;default:'';comment:bitcoin refund tx hash"`
+}
+
+type RefundColumns struct {
+	DepositID    string
+	BtcTxHash    string
+	ToAddress    string
+	Amount       string
+	RefundValue  string
+	RejectReason string
+	Status       string
+	Operator     string
+	Remark       string
+	RefundTxHash string
+}
+
+func (Refund) TableName() string {
+	return "refund_history"
+}
+
+func (Refund) Column() RefundColumns {
+	return RefundColumns{
+		DepositID:    "deposit_id",
+		BtcTxHash:    "btc_tx_hash",
+		ToAddress:    "to_address",
+		Amount:       "amount",
+		RefundValue:  "refund_value",
+		RejectReason: "reject_reason",
+		Status:       "status",
+		Operator:     "operator",
+		Remark:       "remark",
+		RefundTxHash: "refund_tx_hash",
+	}
+}
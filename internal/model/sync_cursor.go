@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// SyncCursor the incremental sync progress on the deposit (updated_at, id) or the deposit event (created_at, id) order
+type SyncCursor struct {
+	Base
+	Name       string    `json:"name" gorm:"type:varchar(32);not null;default:'';uniqueIndex;comment:cursor name"`
+	CursorTime time.Time `json:"cursor_time" gorm:"comment:time of the last synced row"`
+	CursorID   int64     `json:"cursor_id" gorm:"comment:id of the last synced row"`
+}
+
+type SyncCursorColumns struct {
+	Name       string
+	CursorTime string
+	CursorID   string
+}
+
+func (SyncCursor) TableName() string {
+	return "sync_cursor"
+}
+
+func (SyncCursor) Column() SyncCursorColumns {
+	return SyncCursorColumns{
+		Name:       "name",
+		CursorTime: "cursor_time",
+		CursorID:   "cursor_id",
+	}
+}
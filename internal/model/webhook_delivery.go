@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+const (
+	WebhookDeliveryStatusPending    = 1 // wait delivery
+	WebhookDeliveryStatusSuccess    = 2 // delivered
+	WebhookDeliveryStatusDeadLetter = 3 // delivery failed after max attempts, wait redeliver
+)
+
+// WebhookDelivery the webhook delivery of the deposit event to the endpoint, one row per endpoint and event type
+type WebhookDelivery struct {
+	Base
+	Endpoint       string    `json:"endpoint" gorm:"type:varchar(512);not null;default:'';uniqueIndex:idx_webhook_delivery_event;comment:endpoint url"`
+	DepositEventID int64     `json:"deposit_event_id" gorm:"uniqueIndex:idx_webhook_delivery_event;comment:deposit_events id"`
+	EventType      string    `json:"event_type" gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_webhook_delivery_event;comment:webhook event type"`
+	DepositID      int64     `json:"deposit_id" gorm:"index;comment:deposit_history id"`
+	Payload        string    `json:"payload" gorm:"type:text;comment:webhook json payload"`
+	Status         int       `json:"status" gorm:"type:SMALLINT;default:1;index"`
+	Attempts       int       `json:"attempts" gorm:"default:0;comment:delivery attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at" gorm:"index;comment:next delivery time, backoff by attempts"`
+	LastStatusCode int       `json:"last_status_code" gorm:"default:0;comment:last delivery http status code"`
+	LastResponse   string    `json:"last_response" gorm:"type:text;comment:last delivery response body"`
+	LastError      string    `json:"last_error" gorm:"type:text;comment:last delivery error"`
+	DeliveredAt    time.Time `json:"delivered_at" gorm:"comment:delivery success time"`
+	LeaseOwner     string    `json:"lease_owner" gorm:"type:varchar(128);not null;default:'';comment:webhook worker delivering the webhook"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at" gorm:"index;comment:worker lease expiry, expired deliveries can be claimed by other workers"`
+}
+
+type WebhookDeliveryColumns struct {
+	Endpoint       string
+	DepositEventID string
+	EventType      string
+	DepositID      string
+	Payload        string
+	Status         string
+	Attempts       string
+	NextAttemptAt  string
+	LastStatusCode string
+	LastResponse   string
+	LastError      string
+	DeliveredAt    string
+	LeaseOwner     string
+	LeaseExpiresAt string
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+func (WebhookDelivery) Column() WebhookDeliveryColumns {
+	return WebhookDeliveryColumns{
+		Endpoint:       "endpoint",
+		DepositEventID: "deposit_event_id",
+		EventType:      "event_type",
+		DepositID:      "deposit_id",
+		Payload:        "payload",
+		Status:         "status",
+		Attempts:       "attempts",
+		NextAttemptAt:  "next_attempt_at",
+		LastStatusCode: "last_status_code",
+		LastResponse:   "last_response",
+		LastError:      "last_error",
+		DeliveredAt:    "delivered_at",
+		LeaseOwner:     "lease_owner",
+		LeaseExpiresAt: "lease_expires_at",
+	}
+}
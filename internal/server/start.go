@@ -1,24 +1,72 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path"
 	"syscall"
 	"time"
 
+	"github.com/b2network/b2-indexer/internal/api"
 	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/health"
 	"github.com/b2network/b2-indexer/internal/logic/bitcoin"
+	"github.com/b2network/b2-indexer/internal/tracing"
+	"github.com/b2network/b2-indexer/internal/types"
 	logger "github.com/b2network/b2-indexer/pkg/log"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/spf13/cobra"
 	"gorm.io/gorm"
 )
 
+// ServiceStartTimeout the max time a service takes to beat its first heartbeat, including the table migrations
+const ServiceStartTimeout = 5 * time.Minute
+
 func Start(ctx *Context, cmd *cobra.Command) (err error) {
 	home := ctx.Config.RootDir
 	bitcoinCfg := ctx.BitcoinConfig
+
+	// the deposit traces are exported by OTLP if enabled
+	shutdownTracing, err := tracing.Setup(context.Background(), ctx.Config)
+	if err != nil {
+		logger.Errorw("failed to setup tracing", "error", err.Error())
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Errorw("failed to shutdown tracing", "error", err.Error())
+		}
+	}()
+
+	// deposit changes are notified by postgres LISTEN/NOTIFY, shared by the bridge, eps, webhook and api watches
+	var listener *bitcoin.DepositListener
+	if bitcoinCfg.EnableIndexer || bitcoinCfg.Eps.EnableEps || bitcoinCfg.Webhook.EnableWebhook ||
+		ctx.Config.HTTPListenAddress != "" || ctx.Config.GRPCListenAddress != "" {
+		db, err := GetDBContextFromCmd(cmd)
+		if err != nil {
+			logger.Errorw("failed to get db context", "error", err.Error())
+			return err
+		}
+		listenerLoggerOpt := logger.NewOptions()
+		listenerLoggerOpt.Format = ctx.Config.LogFormat
+		listenerLoggerOpt.Level = ctx.Config.LogLevel
+		listenerLoggerOpt.EnableColor = true
+		listenerLoggerOpt.Name = "[deposit-listener]"
+		listener = bitcoin.NewDepositListener(db, logger.New(listenerLoggerOpt))
+		go listener.Listen(context.Background())
+	}
+
+	// the chain tip of the http api, set if the indexer enabled
+	var chainTip api.ChainTip
+	// the bitcoind and the service loops checked by the health probes
+	var (
+		btcInfo    health.BitcoinInfo
+		heartbeats []string
+	)
 	if bitcoinCfg.EnableIndexer {
 		logger.Infow("bitcoin index service starting!!!")
 		bclient, err := rpcclient.New(&rpcclient.ConnConfig{
@@ -54,6 +102,8 @@ func Start(ctx *Context, cmd *cobra.Command) (err error) {
 			logger.Errorw("failed to get bitcoin core status", "error", err.Error())
 			return err
 		}
+		chainTip = bidxer
+		btcInfo = bidxer
 
 		db, err := GetDBContextFromCmd(cmd)
 		if err != nil {
@@ -61,19 +111,51 @@ func Start(ctx *Context, cmd *cobra.Command) (err error) {
 			return err
 		}
 
-		bindexerService := bitcoin.NewIndexerService(bidxer, db, bidxLogger)
+		depositPolicy := bitcoin.NewDepositPolicyChainWithConfig(bitcoinCfg)
+		// replicas run as standby, only the leader indexes
+		elector := bitcoin.NewLeaderElector(db, bitcoin.ServiceName, bidxLogger)
+		bindexerService := bitcoin.NewIndexerService(bidxer, depositPolicy, elector, db, bidxLogger)
+
+		if err := startService(bindexerService.Start, bitcoin.ServiceName); err != nil {
+			logger.Errorw("failed to start bitcoin indexer service", "error", err.Error())
+			return err
+		}
+		heartbeats = append(heartbeats, bitcoin.ServiceName)
 
-		errCh := make(chan error)
-		go func() {
-			if err := bindexerService.Start(); err != nil {
-				errCh <- err
+		if bitcoinCfg.EnableRefund {
+			// refund by the bitcoin core wallet
+			walletHost := bitcoinCfg.RPCHost + ":" + bitcoinCfg.RPCPort
+			if bitcoinCfg.WalletName != "" {
+				walletHost += "/wallet/" + bitcoinCfg.WalletName
 			}
-		}()
+			wclient, err := rpcclient.New(&rpcclient.ConnConfig{
+				Host:         walletHost,
+				User:         bitcoinCfg.RPCUser,
+				Pass:         bitcoinCfg.RPCPass,
+				HTTPPostMode: true,
+				DisableTLS:   bitcoinCfg.DisableTLS,
+			}, nil)
+			if err != nil {
+				logger.Errorw("failed to create bitcoin wallet client", "error", err.Error())
+				return err
+			}
+			defer func() {
+				wclient.Shutdown()
+			}()
 
-		select {
-		case err := <-errCh:
-			return err
-		case <-time.After(5 * time.Second): // assume server started successfully
+			refundLoggerOpt := logger.NewOptions()
+			refundLoggerOpt.Format = ctx.Config.LogFormat
+			refundLoggerOpt.Level = ctx.Config.LogLevel
+			refundLoggerOpt.EnableColor = true
+			refundLoggerOpt.Name = "[refund]"
+			refundLogger := logger.New(refundLoggerOpt)
+
+			refundService := bitcoin.NewRefundService(bitcoin.NewRefunder(wclient, bitcoinParam), bitcoinCfg.Fee, db, refundLogger)
+			if err := startService(refundService.Start, bitcoin.RefundServiceName); err != nil {
+				logger.Errorw("failed to start refund service", "error", err.Error())
+				return err
+			}
+			heartbeats = append(heartbeats, bitcoin.RefundServiceName)
 		}
 
 		// start l1->l2 bridge service
@@ -90,18 +172,57 @@ func Start(ctx *Context, cmd *cobra.Command) (err error) {
 			return err
 		}
 
-		bridgeService := bitcoin.NewBridgeDepositService(bridge, db, bridgeLogger)
-		bridgeErrCh := make(chan error)
-		go func() {
-			if err := bridgeService.Start(); err != nil {
-				bridgeErrCh <- err
+		var screener types.AddressScreener
+		if len(bitcoinCfg.Bridge.ScreeningBlocklists) > 0 {
+			files := make([]string, 0, len(bitcoinCfg.Bridge.ScreeningBlocklists))
+			for _, file := range bitcoinCfg.Bridge.ScreeningBlocklists {
+				if !path.IsAbs(file) {
+					file = path.Join(home, "config", file)
+				}
+				files = append(files, file)
+			}
+			screener, err = bitcoin.NewBlocklistScreener(files,
+				time.Duration(bitcoinCfg.Bridge.ScreeningReloadInterval)*time.Second)
+			if err != nil {
+				logger.Errorw("failed to load screening block lists", "error", err.Error())
+				return err
+			}
+		}
+
+		bridgeService, err := bitcoin.NewBridgeDepositService(bridge, bitcoinCfg.Bridge, screener, listener, db, bridgeLogger)
+		if err != nil {
+			logger.Errorw("failed to create bridge deposit service", "error", err.Error())
+			return err
+		}
+		if err := startService(bridgeService.Start, bitcoin.BridgeDepositServiceName, bitcoin.BridgeDepositReceiptHeartbeat); err != nil {
+			logger.Errorw("failed to start bridge deposit service", "error", err.Error())
+			return err
+		}
+		// the background loops of the bridge deposit service stop on quit
+		defer func() {
+			if err := bridgeService.Stop(); err != nil {
+				logger.Errorw("failed to stop bridge deposit service", "error", err.Error())
 			}
 		}()
+		heartbeats = append(heartbeats, bitcoin.BridgeDepositServiceName, bitcoin.BridgeDepositReceiptHeartbeat)
 
-		select {
-		case err := <-bridgeErrCh:
+		// start contract and signer balance monitor
+		monitorService, err := bitcoin.NewBalanceMonitorService(bridge, bitcoinCfg.Bridge, db, bridgeLogger)
+		if err != nil {
+			logger.Errorw("failed to create balance monitor service", "error", err.Error())
 			return err
-		case <-time.After(5 * time.Second): // assume server started successfully
+		}
+		if monitorService != nil {
+			if err := startService(monitorService.Start, bitcoin.BalanceMonitorServiceName); err != nil {
+				logger.Errorw("failed to start balance monitor service", "error", err.Error())
+				return err
+			}
+			defer func() {
+				if err := monitorService.Stop(); err != nil {
+					logger.Errorw("failed to stop balance monitor service", "error", err.Error())
+				}
+			}()
+			heartbeats = append(heartbeats, bitcoin.BalanceMonitorServiceName)
 		}
 	}
 
@@ -119,22 +240,145 @@ func Start(ctx *Context, cmd *cobra.Command) (err error) {
 			return err
 		}
 
-		epsService, err := bitcoin.NewEpsService(bitcoinCfg.Bridge, bitcoinCfg.Eps, epsLogger, db)
+		epsService, err := bitcoin.NewEpsService(bitcoinCfg.Bridge, bitcoinCfg.Eps, epsLogger, listener, db)
 		if err != nil {
 			logger.Errorw("failed to new eps server", "error", err.Error())
 			return err
 		}
-		epsErrCh := make(chan error)
-		go func() {
-			if err := epsService.OnStart(); err != nil {
-				epsErrCh <- err
+		if err := startService(epsService.OnStart, bitcoin.EpsIngestHeartbeat, bitcoin.EpsDeliverHeartbeat); err != nil {
+			logger.Errorw("failed to start eps service", "error", err.Error())
+			return err
+		}
+		heartbeats = append(heartbeats, bitcoin.EpsIngestHeartbeat, bitcoin.EpsDeliverHeartbeat)
+	}
+
+	if bitcoinCfg.Webhook.EnableWebhook {
+		webhookLoggerOpt := logger.NewOptions()
+		webhookLoggerOpt.Format = ctx.Config.LogFormat
+		webhookLoggerOpt.Level = ctx.Config.LogLevel
+		webhookLoggerOpt.EnableColor = true
+		webhookLoggerOpt.Name = "[webhook]"
+		webhookLogger := logger.New(webhookLoggerOpt)
+
+		db, err := GetDBContextFromCmd(cmd)
+		if err != nil {
+			logger.Errorw("failed to get db context", "error", err.Error())
+			return err
+		}
+
+		webhookService, err := bitcoin.NewWebhookService(bitcoinCfg.Webhook, listener, db, webhookLogger)
+		if err != nil {
+			logger.Errorw("failed to new webhook server", "error", err.Error())
+			return err
+		}
+		if err := startService(webhookService.Start, bitcoin.WebhookEnqueueHeartbeat, bitcoin.WebhookDeliverHeartbeat); err != nil {
+			logger.Errorw("failed to start webhook service", "error", err.Error())
+			return err
+		}
+		// the enqueue and deliver loops of the webhook service stop on quit
+		defer func() {
+			if err := webhookService.Stop(); err != nil {
+				logger.Errorw("failed to stop webhook service", "error", err.Error())
 			}
 		}()
+		heartbeats = append(heartbeats, bitcoin.WebhookEnqueueHeartbeat, bitcoin.WebhookDeliverHeartbeat)
+	}
+	// the probes are served on their own address, so that they never depend on the http api enabled
+	var checker *health.Checker
+	if ctx.Config.HealthListenAddress != "" || ctx.Config.HTTPListenAddress != "" {
+		db, err := GetDBContextFromCmd(cmd)
+		if err != nil {
+			logger.Errorw("failed to get db context", "error", err.Error())
+			return err
+		}
+		// the evm rpc is checked if the bridge or eps uses it
+		var ethRPCURL string
+		if bitcoinCfg.EnableIndexer || bitcoinCfg.Eps.EnableEps {
+			ethRPCURL = bitcoinCfg.Bridge.EthRPCURL
+		}
+		checker = health.NewChecker(db, btcInfo, ethRPCURL, ctx.Config.HealthMaxLag,
+			time.Duration(ctx.Config.HealthHeartbeatTimeout)*time.Second, heartbeats, health.DefaultHeartbeats)
+	}
+	if ctx.Config.HealthListenAddress != "" {
+		healthServer := &http.Server{
+			Handler:           checker.Handler(),
+			ReadHeaderTimeout: api.ReadHeaderTimeout,
+		}
+		logger.Infow("health probes listening", "address", ctx.Config.HealthListenAddress)
+		if err := listenAndServe("health probes", ctx.Config.HealthListenAddress, healthServer.Serve); err != nil {
+			logger.Errorw("failed to listen health probes", "error", err.Error())
+			return err
+		}
+	}
+	if ctx.Config.HTTPListenAddress != "" {
+		apiLoggerOpt := logger.NewOptions()
+		apiLoggerOpt.Format = ctx.Config.LogFormat
+		apiLoggerOpt.Level = ctx.Config.LogLevel
+		apiLoggerOpt.EnableColor = true
+		apiLoggerOpt.Name = "[http-api]"
+		apiLogger := logger.New(apiLoggerOpt)
+
+		db, err := GetDBContextFromCmd(cmd)
+		if err != nil {
+			logger.Errorw("failed to get db context", "error", err.Error())
+			return err
+		}
+
+		apiServer := api.NewServer(db, chainTip, listener, apiLogger)
+		apiServer.Handle("/healthz", checker.LivenessHandler())
+		apiServer.Handle("/readyz", checker.ReadinessHandler())
+		if err := listenAndServe("http api", ctx.Config.HTTPListenAddress, apiServer.Serve); err != nil {
+			logger.Errorw("failed to listen http api", "error", err.Error())
+			return err
+		}
+	}
+	if ctx.Config.GRPCListenAddress != "" {
+		grpcLoggerOpt := logger.NewOptions()
+		grpcLoggerOpt.Format = ctx.Config.LogFormat
+		grpcLoggerOpt.Level = ctx.Config.LogLevel
+		grpcLoggerOpt.EnableColor = true
+		grpcLoggerOpt.Name = "[grpc-api]"
+		grpcLogger := logger.New(grpcLoggerOpt)
+
+		db, err := GetDBContextFromCmd(cmd)
+		if err != nil {
+			logger.Errorw("failed to get db context", "error", err.Error())
+			return err
+		}
 
-		select {
-		case err := <-epsErrCh:
+		withdraws, err := bitcoin.NewWithdrawReader(bitcoinCfg.Bridge, path.Join(home, "config"))
+		if err != nil {
+			logger.Errorw("failed to new withdraw reader", "error", err.Error())
+			return err
+		}
+		grpcServer := api.NewGRPCServer(db, listener, withdraws, grpcLogger)
+		if err := listenAndServe("grpc api", ctx.Config.GRPCListenAddress, grpcServer.Serve); err != nil {
+			logger.Errorw("failed to listen grpc api", "error", err.Error())
+			return err
+		}
+	}
+	if ctx.Config.AdminListenAddress != "" {
+		adminLoggerOpt := logger.NewOptions()
+		adminLoggerOpt.Format = ctx.Config.LogFormat
+		adminLoggerOpt.Level = ctx.Config.LogLevel
+		adminLoggerOpt.EnableColor = true
+		adminLoggerOpt.Name = "[admin-api]"
+		adminLogger := logger.New(adminLoggerOpt)
+
+		db, err := GetDBContextFromCmd(cmd)
+		if err != nil {
+			logger.Errorw("failed to get db context", "error", err.Error())
+			return err
+		}
+
+		adminServer, err := api.NewAdminServer(db, ctx.Config.AdminToken, adminLogger)
+		if err != nil {
+			logger.Errorw("failed to new admin api server", "error", err.Error())
+			return err
+		}
+		if err := listenAndServe("admin api", ctx.Config.AdminListenAddress, adminServer.Serve); err != nil {
+			logger.Errorw("failed to listen admin api", "error", err.Error())
 			return err
-		case <-time.After(5 * time.Second): // assume server started successfully
 		}
 	}
 	// wait quit
@@ -143,6 +387,45 @@ func Start(ctx *Context, cmd *cobra.Command) (err error) {
 	return nil
 }
 
+// startService starts the service in background and waits until its loops beat the first heartbeat,
+// so that a service failed or stuck on start fails the server start instead of being assumed started
+func startService(start func() error, heartbeats ...string) error {
+	since := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		if err := start(); err != nil {
+			errCh <- err
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ServiceStartTimeout)
+	defer cancel()
+	beatCh := make(chan error, 1)
+	go func() {
+		beatCh <- health.DefaultHeartbeats.WaitBeat(ctx, since, heartbeats...)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case err := <-beatCh:
+		return err
+	}
+}
+
+// listenAndServe binds the address then serves in background, the server is started once the address is bound
+func listenAndServe(name string, addr string, serve func(net.Listener) error) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := serve(lis); err != nil {
+			logger.Errorw("server stopped", "server", name, "error", err.Error())
+		}
+	}()
+	return nil
+}
+
 func GetDBContextFromCmd(cmd *cobra.Command) (*gorm.DB, error) {
 	if v := cmd.Context().Value(DBContextKey); v != nil {
 		db := v.(*gorm.DB)
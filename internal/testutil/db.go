@@ -0,0 +1,60 @@
+// Package testutil the helpers of the tests on the local postgres
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/model"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// LocalDB opens the local postgres in a new schema with the tables migrated, the schema is dropped after the test
+func LocalDB(t testing.TB) *gorm.DB {
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	gormCfg := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	admin, err := gorm.Open(postgres.Open(cfg.DatabaseSource), gormCfg)
+	require.NoError(t, err)
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	require.NoError(t, admin.Exec("CREATE SCHEMA "+schema).Error)
+
+	dsn := cfg.DatabaseSource
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+	db, err := gorm.Open(postgres.Open(dsn), gormCfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	require.NoError(t, db.AutoMigrate(
+		&model.Deposit{},
+		&model.DepositEvent{},
+		&model.Refund{},
+		&model.EoaFallback{},
+		&model.CircuitBreaker{},
+		&model.Eps{},
+		&model.EpsDelivery{},
+		&model.WebhookDelivery{},
+		&model.SyncCursor{},
+	))
+	return db
+}
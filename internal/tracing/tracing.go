@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ServiceName = "b2-indexer"
+	TracerName  = "github.com/b2network/b2-indexer"
+)
+
+// span attribute keys of the deposit
+const (
+	AttrDepositID      = "deposit.id"
+	AttrBtcTxHash      = "btc.tx_hash"
+	AttrBtcBlockNumber = "btc.block_number"
+	AttrB2TxHash       = "b2.tx_hash"
+	AttrB2TxStatus     = "b2.tx_status"
+	AttrEpsID          = "eps.id"
+	AttrAttempt        = "attempt"
+)
+
+// propagator the w3c trace context persisted on the deposit row
+var propagator = propagation.TraceContext{}
+
+// Setup exports the traces to the OTLP gRPC collector if enabled, the tracer is noop otherwise.
+// The returned shutdown flushes the pending spans.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	if !cfg.TraceEnable {
+		return func(context.Context) error { return nil }, nil
+	}
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.TraceEndpoint)}
+	if cfg.TraceInsecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	provider := NewTracerProvider(sdktrace.NewBatchSpanProcessor(exporter), cfg.TraceSampleRatio)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider returns the tracer provider of the span processor,
+// ratio is the ratio of the traces sampled, none sampled if <= 0, all sampled if >= 1
+func NewTracerProvider(processor sdktrace.SpanProcessor, ratio float64) *sdktrace.TracerProvider {
+	var sampler sdktrace.Sampler
+	switch {
+	case ratio <= 0:
+		sampler = sdktrace.NeverSample()
+	case ratio >= 1:
+		sampler = sdktrace.AlwaysSample()
+	default:
+		sampler = sdktrace.TraceIDRatioBased(ratio)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		// the services continue the deposit trace by its persisted context, follow its sampling decision
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName))),
+	)
+}
+
+// Start starts the span of the global tracer provider
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// End records the error of the span and ends it
+func End(span trace.Span, err error, opts ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(opts...)
+}
+
+// Inject returns the w3c traceparent of the span in the context, empty if no valid span
+func Inject(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// Extract returns the context continues the persisted traceparent, the background if empty or invalid
+func Extract(traceParent string) context.Context {
+	if traceParent == "" {
+		return context.Background()
+	}
+	return propagator.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceParent})
+}
+
+// TraceID returns the trace id of the span in the context for log correlation, empty if no valid span
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
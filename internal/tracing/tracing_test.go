@@ -0,0 +1,113 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/b2network/b2-indexer/internal/config"
+	"github.com/b2network/b2-indexer/internal/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func setupExporter(t *testing.T, ratio float64) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(sdktrace.NewSimpleSpanProcessor(exporter), ratio)
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	return exporter
+}
+
+func TestInjectExtract(t *testing.T) {
+	exporter := setupExporter(t, 1)
+
+	ctx, root := tracing.Start(context.Background(), "deposit")
+	traceParent := tracing.Inject(ctx)
+	root.End()
+	require.Len(t, traceParent, 55)
+	require.Equal(t, root.SpanContext().TraceID().String(), tracing.TraceID(ctx))
+
+	// another service continues the persisted trace
+	_, child := tracing.Start(tracing.Extract(traceParent), "eps.deliver")
+	child.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "eps.deliver", spans[1].Name)
+	require.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	require.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+	require.True(t, spans[1].Parent.IsRemote())
+}
+
+func TestExtractEmpty(t *testing.T) {
+	require.Equal(t, "", tracing.Inject(context.Background()))
+	require.Equal(t, "", tracing.TraceID(context.Background()))
+	for _, traceParent := range []string{"", "invalid"} {
+		ctx := tracing.Extract(traceParent)
+		require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	}
+}
+
+func TestEnd(t *testing.T) {
+	exporter := setupExporter(t, 1)
+
+	_, span := tracing.Start(context.Background(), "b2.broadcast")
+	tracing.End(span, errors.New("nonce too low"))
+	_, span = tracing.Start(context.Background(), "b2.transaction_receipt")
+	tracing.End(span, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Equal(t, "nonce too low", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	require.Equal(t, codes.Unset, spans[1].Status.Code)
+	require.Empty(t, spans[1].Events)
+}
+
+func TestSampleRatio(t *testing.T) {
+	exporter := setupExporter(t, 0.000001)
+
+	// the continued trace follows the sampling decision of the persisted context
+	sampled := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	_, span := tracing.Start(tracing.Extract(sampled), "bridge.handle_deposit")
+	span.End()
+	notSampled := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+	_, span = tracing.Start(tracing.Extract(notSampled), "bridge.handle_deposit")
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+
+	// none sampled if the ratio <= 0, all sampled if >= 1
+	for _, tc := range []struct {
+		ratio float64
+		spans int
+	}{
+		{ratio: -1, spans: 0},
+		{ratio: 0, spans: 0},
+		{ratio: 1, spans: 1},
+		{ratio: 2, spans: 1},
+	} {
+		exporter := setupExporter(t, tc.ratio)
+		_, span := tracing.Start(context.Background(), "deposit")
+		span.End()
+		require.Len(t, exporter.GetSpans(), tc.spans, "ratio %v", tc.ratio)
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), &config.Config{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
@@ -2,16 +2,69 @@ package types
 
 import (
 	"context"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // BITCOINBridge defines the interface of custom bitcoin bridge.
 type BITCOINBridge interface {
+	// ResolveRecipient resolves the l2 recipient address, returns address and strategy name
+	ResolveRecipient(context.Context, *RecipientRequest) (string, string, error)
 	// Deposit transfers amout to address
-	Deposit(string, string, int64) (*types.Transaction, []byte, string, error)
+	Deposit(string, string, *big.Int) (*types.Transaction, []byte, error)
+	// PrepareDeposit builds the deposit tx with the gas estimated, without nonce
+	PrepareDeposit(context.Context, string, string, *big.Int) (*UnsignedTx, error)
+	// PrepareTransfer builds the native transfer tx with the gas estimated, without nonce
+	PrepareTransfer(context.Context, string, *big.Int) (*UnsignedTx, error)
+	// SignTx signs the prepared tx with the pending nonce of the signer, without broadcast
+	SignTx(context.Context, *UnsignedTx) (*types.Transaction, error)
+	// Broadcast sends the signed tx
+	Broadcast(context.Context, *types.Transaction) error
+	// TransactionReceipt returns the receipt of the tx hash
+	TransactionReceipt(context.Context, string) (*types.Receipt, error)
 	// Transfer amount to address
-	Transfer(string, int64) (*types.Transaction, error)
+	Transfer(context.Context, string, *big.Int) (*types.Transaction, error)
 	// WaitMined wait mined
 	WaitMined(context.Context, *types.Transaction, []byte) (*types.Receipt, error)
+	// Balances returns the bridge contract balance and the signer native balance
+	Balances(context.Context) (*big.Int, *big.Int, error)
+}
+
+// UnsignedTx defines the tx prepared by the bridge, the nonce is allocated when signed,
+// so that only the nonce allocation and broadcast are serialized
+type UnsignedTx struct {
+	To       common.Address
+	Value    *big.Int
+	Data     []byte
+	Gas      uint64
+	GasPrice *big.Int
+	ChainID  *big.Int
+}
+
+// RecipientResolver defines the interface of l2 recipient resolution strategy.
+type RecipientResolver interface {
+	// Name returns the strategy name, it is recorded on the deposit
+	Name() string
+	// Resolve returns the l2 recipient address of the deposit
+	Resolve(context.Context, *RecipientRequest) (string, error)
+}
+
+// RecipientRequest defines the deposit info used to resolve the l2 recipient
+type RecipientRequest struct {
+	// BtcTxHash is the bitcoin tx hash
+	BtcTxHash string
+	// BtcFrom is the bitcoin from address
+	BtcFrom string
+	// BtcFroms is all bitcoin from addresses
+	BtcFroms []string
+	// Memo is the hex encoded OP_RETURN data
+	Memo string
+}
+
+// AddressScreener defines the interface of sanctions and blocklist screening.
+type AddressScreener interface {
+	// Screen returns the hit reason of the addresses, empty if no address hit
+	Screen([]string) (string, error)
 }
@@ -23,4 +23,20 @@ type BitcoinTxParseResult struct {
 	TxType string
 	// index is the index of the transaction in the block
 	Index int64
+	// memo is the hex encoded OP_RETURN data of the transaction
+	Memo string
+	// from_script_types is the script class of each from address, eg. "witness_v0_keyhash"
+	FromScriptTypes []string
+}
+
+// DepositPolicy defines the interface of deposit admission policy.
+type DepositPolicy interface {
+	// Check returns the reject reason of the deposit, empty if accepted
+	Check(*BitcoinTxParseResult) (string, error)
+}
+
+// BITCOINRefunder defines the interface of rejected deposit refund.
+type BITCOINRefunder interface {
+	// Refund sends amount(sats) to the bitcoin address, returns the refund tx hash
+	Refund(string, int64) (string, error)
 }